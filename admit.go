@@ -0,0 +1,78 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "math"
+
+// WithMinCount returns an Option that only interns a value once it has
+// been requested at least n times within a window of recent misses.
+// Until then, Get returns a fresh Value that is not in the table and
+// is not shared with any other caller, so the long tail of values seen
+// only once or twice never grows the table.
+//
+// Request counts are kept approximately, in a fixed-size count-min
+// sketch, so the Option's memory use doesn't depend on the number of
+// distinct values. Counts only ever overestimate. Every window misses,
+// all counts are halved, so values must recur to stay eligible. If
+// window is zero or negative, a window of 64 times the sketch width
+// (about 64K misses) is used.
+//
+// With this Option, the pointer identity guarantee of Get only holds
+// for values that have been admitted: two Gets of a value that hasn't
+// yet been seen n times return different pointers.
+func WithMinCount(n, window int) Option {
+	return func(in *Interner) {
+		if n <= 1 {
+			in.admit = nil
+			return
+		}
+		if window <= 0 {
+			window = 64 * sketchWidth
+		}
+		in.admit = &sketch{min: uint32(n), window: window}
+	}
+}
+
+const (
+	sketchDepth = 4
+	sketchWidth = 1024 // must be a power of two
+)
+
+// sketch is a count-min sketch of the keys missed by an Interner.
+// It is guarded by the Interner's mu.
+type sketch struct {
+	min    uint32 // count at which keys are admitted
+	window int    // observations between halvings
+	seen   int    // observations since last halving
+	counts [sketchDepth][sketchWidth]uint32
+}
+
+// observe records a request for k and reports whether k has now been
+// requested enough times to be admitted to the table.
+func (s *sketch) observe(k key) bool {
+	s.seen++
+	if s.seen >= s.window {
+		s.seen = 0
+		for i := range s.counts {
+			for j := range s.counts[i] {
+				s.counts[i][j] /= 2
+			}
+		}
+	}
+
+	h := hashKey(k)
+	h1, h2 := uint32(h), uint32(h>>32)|1
+	est := uint32(math.MaxUint32)
+	for i := range s.counts {
+		c := &s.counts[i][(h1+uint32(i)*h2)&(sketchWidth-1)]
+		if *c < math.MaxUint32 {
+			*c++
+		}
+		if *c < est {
+			est = *c
+		}
+	}
+	return est >= s.min
+}
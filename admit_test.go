@@ -0,0 +1,47 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWithMinCount(t *testing.T) {
+	in := New(WithMinCount(3, 0))
+	a, b := in.GetByString("rare"), in.GetByString("rare")
+	if a == b {
+		t.Fatal("value interned before reaching min count")
+	}
+	if a.Get() != "rare" || b.Get() != "rare" {
+		t.Fatalf("unadmitted values = %v, %v; want rare", a.Get(), b.Get())
+	}
+	c, d := in.GetByString("rare"), in.Get("rare")
+	if c != d {
+		t.Error("value not interned after reaching min count")
+	}
+	if c == a || c == b {
+		t.Error("admitted value reused an unadmitted pointer")
+	}
+
+	type pair struct{ a, b int }
+	for i := 0; i < 2; i++ {
+		in.Get(pair{1, 2})
+	}
+	if in.Get(pair{1, 2}) != in.Get(pair{1, 2}) {
+		t.Error("struct value not interned after reaching min count")
+	}
+}
+
+func TestWithMinCountWindow(t *testing.T) {
+	in := New(WithMinCount(2, 10))
+	in.GetByString("decays")
+	for i := 0; i < 20; i++ {
+		in.GetByString(fmt.Sprint("filler", i))
+	}
+	if in.GetByString("decays") == in.GetByString("decays") {
+		t.Error("count survived two windows")
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"unsafe"
+)
+
+// arenaMagic begins every arena file. The final byte is the format version.
+const arenaMagic = "go4iarn\x01"
+
+// An Arena is a file-backed, append-only log of the symbol table.
+//
+// Opening an Arena memory-maps its file and interns every string in
+// it, assigning each the Symbol it had when it was added. The strings
+// loaded at open time are not copied onto the Go heap; they point
+// directly into the mapping, which is shared with the page cache.
+// For that reason the mapping is never unmapped, even by Close.
+//
+// While an Arena is open, it is the record of every Symbol: Add and
+// Sync append any Symbols assigned since the last append, however
+// they were assigned. Only string values can be persisted.
+//
+// An Arena opened with OpenSharedArena can additionally be used by
+// several processes at once; see OpenSharedArena.
+type Arena struct {
+	shared bool
+
+	mu  sync.Mutex
+	f   *os.File
+	n   Symbol // number of Symbols persisted in f
+	off int64  // size of f, as of our last read or write
+}
+
+// OpenArena opens the arena file at path, creating it if needed,
+// and loads its contents into the symbol table.
+//
+// OpenArena should be called at startup, before Symbols are assigned
+// by other means. As with ReadTable, it fails if the file disagrees
+// with Symbols already assigned.
+//
+// A partially written record at the end of the file, as left by a
+// crash during Add, is discarded.
+func OpenArena(path string) (*Arena, error) {
+	return openArena(path, false)
+}
+
+// OpenSharedArena is like OpenArena, but the arena file may be opened
+// by multiple processes on the same host at once, all of which agree
+// on the Symbol for every value in it.
+//
+// Appends are serialized between processes with an advisory file lock.
+// Add picks up Symbols added by other processes before assigning a new
+// one, and ValueOf picks them up when asked for a Symbol it doesn't
+// know. Lookups of already-loaded Symbols never touch the file.
+//
+// In a process using a shared arena, Symbols must only be assigned
+// with Add: a Symbol assigned by SymbolOf may collide with one that
+// another process has added, in which case Sync returns an error.
+//
+// OpenSharedArena is only supported on systems with flock(2).
+func OpenSharedArena(path string) (*Arena, error) {
+	return openArena(path, true)
+}
+
+func openArena(path string, shared bool) (*Arena, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	a := &Arena{f: f, shared: shared}
+	if err := a.load(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("intern: opening arena %s: %w", path, err)
+	}
+	return a, nil
+}
+
+// load memory-maps the arena file and loads all of it.
+func (a *Arena) load() error {
+	if err := a.lock(); err != nil {
+		return err
+	}
+	defer a.unlock()
+
+	fi, err := a.f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() == 0 {
+		if _, err := a.f.Write([]byte(arenaMagic)); err != nil {
+			return err
+		}
+		a.off = int64(len(arenaMagic))
+		return nil
+	}
+	if fi.Size() < int64(len(arenaMagic)) || int64(int(fi.Size())) != fi.Size() {
+		return errors.New("not an arena file")
+	}
+	data, err := mmapFile(a.f, int(fi.Size()))
+	if err != nil {
+		return err
+	}
+	if string(data[:len(arenaMagic)]) != arenaMagic {
+		return errors.New("not an arena file, or unsupported version")
+	}
+	a.off = int64(len(arenaMagic))
+	return a.loadRecords(data[len(arenaMagic):])
+}
+
+// catchUp loads any records appended to the file by other processes.
+// The file lock must be held.
+func (a *Arena) catchUp() error {
+	if !a.shared {
+		return nil
+	}
+	fi, err := a.f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() <= a.off {
+		return nil
+	}
+	// New records are copied rather than mapped, as mappings
+	// must start on a page boundary and are never unmapped.
+	data := make([]byte, fi.Size()-a.off)
+	if _, err := a.f.ReadAt(data, a.off); err != nil {
+		return err
+	}
+	return a.loadRecords(data)
+}
+
+// loadRecords assigns Symbols for the records in data, which starts at
+// offset a.off in the file, and must not be modified afterwards.
+// A partial record at the end of data is truncated from the file.
+// The file lock must be held.
+func (a *Arena) loadRecords(data []byte) error {
+	off := 0
+	for off < len(data) {
+		size, n := binary.Uvarint(data[off:])
+		if n <= 0 || size > uint64(len(data)-off-n) {
+			break // torn write; truncated below
+		}
+		b := data[off+n : off+n+int(size)]
+		if err := assignSymbol(a.n+1, std.getString(bytesToString(b), getCtx{shared: true})); err != nil {
+			return err
+		}
+		a.n++
+		off += n + int(size)
+	}
+	a.off += int64(off)
+	if off < len(data) {
+		if err := a.f.Truncate(a.off); err != nil {
+			return err
+		}
+	}
+	_, err := a.f.Seek(a.off, 0)
+	return err
+}
+
+// bytesToString returns a string sharing memory with b,
+// which must never be modified.
+func bytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// Add returns the Symbol for s, assigning one if needed,
+// and ensures it has been appended to the arena file.
+func (a *Arena) Add(s string) (Symbol, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.lock(); err != nil {
+		return 0, err
+	}
+	defer a.unlock()
+	if err := a.catchUp(); err != nil {
+		return 0, err
+	}
+	sym := SymbolOfString(s)
+	return sym, a.appendPending()
+}
+
+// Sync appends every Symbol not yet in the arena file to it,
+// and commits the file to stable storage.
+//
+// It returns an error if an unpersisted Symbol has a non-string value.
+func (a *Arena) Sync() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.lock(); err != nil {
+		return err
+	}
+	defer a.unlock()
+	if err := a.catchUp(); err != nil {
+		return err
+	}
+	if err := a.appendPending(); err != nil {
+		return err
+	}
+	return a.f.Sync()
+}
+
+// ValueOf is like the package-level ValueOf, except that for a shared
+// arena, it first loads Symbols added by other processes if sym isn't
+// yet known.
+func (a *Arena) ValueOf(sym Symbol) *Value {
+	if v := ValueOf(sym); v != nil || !a.shared {
+		return v
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.f == nil || a.lock() != nil {
+		return nil
+	}
+	defer a.unlock()
+	if a.catchUp() != nil {
+		return nil
+	}
+	return ValueOf(sym)
+}
+
+// appendPending appends every Symbol not yet in the arena file.
+// a.mu and the file lock must be held.
+func (a *Arena) appendPending() error {
+	symMu.RLock()
+	pending := symVals[a.n+1 : len(symVals) : len(symVals)]
+	symMu.RUnlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var rec []byte
+	var buf [binary.MaxVarintLen64]byte
+	for i, v := range pending {
+		s, ok := v.cmpVal.(string)
+		if !ok {
+			return fmt.Errorf("intern: can't add Symbol %d with non-string value of type %T to arena", int(a.n)+1+i, v.cmpVal)
+		}
+		rec = append(rec, buf[:binary.PutUvarint(buf[:], uint64(len(s)))]...)
+		rec = append(rec, s...)
+	}
+	if _, err := a.f.Write(rec); err != nil {
+		return err
+	}
+	a.n += Symbol(len(pending))
+	a.off += int64(len(rec))
+	return nil
+}
+
+// lock takes the file lock, if the arena is shared.
+// It returns an error if the arena is closed. a.mu must be held.
+func (a *Arena) lock() error {
+	if a.f == nil {
+		return errors.New("intern: Arena is closed")
+	}
+	if !a.shared {
+		return nil
+	}
+	return lockFile(a.f)
+}
+
+// unlock releases the file lock taken by lock.
+func (a *Arena) unlock() {
+	if a.shared {
+		unlockFile(a.f)
+	}
+}
+
+// Close syncs and closes the arena file. Strings loaded by OpenArena
+// remain valid, as the mapping is kept for the life of the process.
+func (a *Arena) Close() error {
+	err := a.Sync()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.f == nil {
+		return err
+	}
+	if cerr := a.f.Close(); err == nil {
+		err = cerr
+	}
+	a.f = nil
+	return err
+}
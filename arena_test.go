@@ -0,0 +1,145 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	defer swapSymbols()()
+
+	dir, err := ioutil.TempDir("", "intern-arena")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "arena")
+
+	a, err := OpenArena(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := []string{"red", "green", "", "blue"}
+	for i, w := range words {
+		sym, err := a.Add(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sym != Symbol(i+1) {
+			t.Errorf("Add(%q) = %d; want %d", w, sym, i+1)
+		}
+	}
+	SymbolOfString("added-elsewhere")
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	words = append(words, "added-elsewhere")
+
+	// Simulate a crash in the middle of appending a record.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{10, 'x'})
+	f.Close()
+
+	swapSymbols()
+	a, err = OpenArena(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if got := NumSymbols(); got != len(words) {
+		t.Errorf("NumSymbols after reopen = %d; want %d", got, len(words))
+	}
+	for i, w := range words {
+		if got := ValueOf(Symbol(i + 1)).Get(); got != w {
+			t.Errorf("ValueOf(%d) = %q; want %q", i+1, got, w)
+		}
+	}
+	if sym, err := a.Add("purple"); err != nil || sym != Symbol(len(words)+1) {
+		t.Errorf("Add after reopen = %d, %v; want %d, nil", sym, err, len(words)+1)
+	}
+}
+
+func TestArenaNonString(t *testing.T) {
+	defer swapSymbols()()
+
+	dir, err := ioutil.TempDir("", "intern-arena")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	a, err := OpenArena(filepath.Join(dir, "arena"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	SymbolOf(42)
+	if err := a.Sync(); err == nil {
+		t.Error("Sync succeeded with non-string Symbol")
+	}
+}
+
+func TestOpenArenaNotArena(t *testing.T) {
+	f, err := ioutil.TempFile("", "intern-arena")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("this is not an arena file")
+	f.Close()
+
+	if a, err := OpenArena(f.Name()); err == nil {
+		a.Close()
+		t.Error("OpenArena succeeded on a non-arena file")
+	}
+}
+
+func TestSharedArena(t *testing.T) {
+	defer swapSymbols()()
+
+	dir, err := ioutil.TempDir("", "intern-arena")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "arena")
+
+	a, err := OpenSharedArena(path)
+	if err != nil {
+		t.Skipf("shared arenas unsupported: %v", err)
+	}
+	defer a.Close()
+	if sym, err := a.Add("mine"); err != nil || sym != 1 {
+		t.Fatalf("Add(mine) = %d, %v; want 1, nil", sym, err)
+	}
+
+	// Append a record as another process would.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{6, 't', 'h', 'e', 'i', 'r', 's'})
+	f.Close()
+
+	if v := ValueOf(2); v != nil {
+		t.Fatalf("ValueOf(2) = %v before catching up; want nil", v)
+	}
+	if v := a.ValueOf(2); v == nil || v.Get() != "theirs" {
+		t.Fatalf("Arena.ValueOf(2) = %v; want theirs", v)
+	}
+	if sym, err := a.Add("theirs"); err != nil || sym != 2 {
+		t.Errorf("Add(theirs) = %d, %v; want 2, nil", sym, err)
+	}
+	if sym, err := a.Add("next"); err != nil || sym != 3 {
+		t.Errorf("Add(next) = %d, %v; want 3, nil", sym, err)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// auxBox holds a Value's auxiliary value, so that nil can be stored.
+type auxBox struct {
+	x interface{}
+}
+
+// Aux returns the auxiliary value attached to v by SetAux or AuxOnce,
+// or nil if there is none, as for a nil v.
+//
+// An auxiliary value is a place to cache something derived from v's
+// underlying value, such as a parsed or lowercased form, so that it
+// needn't be recomputed at every use. It lives as long as v does; if v
+// is collected, a later Get returns a new Value without it.
+//
+// An auxiliary value must not refer to v, directly or indirectly, as
+// a Value in such a cycle is never collected.
+func (v *Value) Aux() interface{} {
+	if v == nil {
+		return nil
+	}
+	if e := v.ext(); e != nil {
+		if b := (*auxBox)(atomic.LoadPointer(&e.aux)); b != nil {
+			return b.x
+		}
+	}
+	return nil
+}
+
+// SetAux attaches the auxiliary value x to v, replacing any existing
+// one. See Aux.
+func (v *Value) SetAux(x interface{}) {
+	atomic.StorePointer(&v.addExt().aux, unsafe.Pointer(&auxBox{x}))
+}
+
+// AuxOnce returns v's auxiliary value, first attaching the result of
+// f if there is none. See Aux.
+//
+// If multiple goroutines call AuxOnce on v at once, f may be called
+// by more than one of them, but all of them return the same result.
+func (v *Value) AuxOnce(f func() interface{}) interface{} {
+	e := v.addExt()
+	if b := (*auxBox)(atomic.LoadPointer(&e.aux)); b != nil {
+		return b.x
+	}
+	nb := &auxBox{f()}
+	if atomic.CompareAndSwapPointer(&e.aux, nil, unsafe.Pointer(nb)) {
+		return nb.x
+	}
+	return (*auxBox)(atomic.LoadPointer(&e.aux)).x
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAux(t *testing.T) {
+	in := New()
+	v := in.GetByString("Aux-Value")
+	if x := v.Aux(); x != nil {
+		t.Fatalf("Aux of new Value = %v; want nil", x)
+	}
+	v.SetAux(nil)
+	if x := v.AuxOnce(func() interface{} { return "unused" }); x != nil {
+		t.Errorf("AuxOnce after SetAux(nil) = %v; want nil", x)
+	}
+	v.SetAux(42)
+	if x := in.GetByString("Aux-Value").Aux(); x != 42 {
+		t.Errorf("Aux = %v; want 42", x)
+	}
+}
+
+func TestAuxOnce(t *testing.T) {
+	v := New().GetByString("Aux-Once")
+	lower := func() interface{} { return strings.ToLower(v.Get().(string)) }
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = v.AuxOnce(lower)
+		}(i)
+	}
+	wg.Wait()
+	for _, r := range results {
+		if r != "aux-once" {
+			t.Fatalf("AuxOnce = %v; want aux-once", r)
+		}
+	}
+	calls := 0
+	v.AuxOnce(func() interface{} { calls++; return nil })
+	if calls != 0 {
+		t.Error("AuxOnce called f with an aux value present")
+	}
+}
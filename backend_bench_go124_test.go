@@ -0,0 +1,54 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.24
+// +build go1.24
+
+package intern
+
+import (
+	"runtime"
+	"sync"
+	"unique"
+	"weak"
+)
+
+func init() {
+	benchBackends = append(benchBackends,
+		benchBackend{"weak", func() func(string) interface{} {
+			wi := &weakInterner{m: map[string]weak.Pointer[string]{}}
+			return func(s string) interface{} { return wi.get(s) }
+		}},
+		benchBackend{"unique", func() func(string) interface{} {
+			return func(s string) interface{} { return unique.Make(s) }
+		}},
+	)
+}
+
+// weakInterner is a minimal string interner built on weak.Pointer,
+// for comparison with this package's finalizer-based scheme.
+type weakInterner struct {
+	mu sync.Mutex
+	m  map[string]weak.Pointer[string]
+}
+
+func (wi *weakInterner) get(s string) *string {
+	wi.mu.Lock()
+	defer wi.mu.Unlock()
+	if p := wi.m[s].Value(); p != nil {
+		return p
+	}
+	p := new(string)
+	*p = s
+	wp := weak.Make(p)
+	wi.m[s] = wp
+	runtime.AddCleanup(p, func(s string) {
+		wi.mu.Lock()
+		defer wi.mu.Unlock()
+		if wi.m[s] == wp {
+			delete(wi.m, s)
+		}
+	}, s)
+	return p
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// A benchBackend is a way of interning strings, for comparison by
+// BenchmarkBackends.
+type benchBackend struct {
+	name string
+	// new returns a function interning a string and returning
+	// its handle, which keeps it interned while reachable.
+	new func() func(string) interface{}
+}
+
+// benchBackends are the backends compared. Files built with newer Go
+// versions add more.
+var benchBackends = []benchBackend{
+	{"finalizer", func() func(string) interface{} {
+		in := New()
+		return func(s string) interface{} { return in.GetByString(s) }
+	}},
+	{"finalizer-hashkeys", func() func(string) interface{} {
+		in := New(HashKeys())
+		return func(s string) interface{} { return in.GetByString(s) }
+	}},
+	{"finalizer-seqlock", func() func(string) interface{} {
+		in := New(Seqlock())
+		return func(s string) interface{} { return in.GetByString(s) }
+	}},
+	{"finalizer-rcu", func() func(string) interface{} {
+		in := New(RCU())
+		return func(s string) interface{} { return in.GetByString(s) }
+	}},
+	{"safe-leaky", func() func(string) interface{} {
+		in := New(safeBackend)
+		return func(s string) interface{} { return in.GetByString(s) }
+	}},
+}
+
+// BenchmarkBackends compares the backends across ratios of hits, Gets
+// of values already interned, to misses. Run it with -cpu to compare
+// across numbers of cores. Names have the form
+// Backends/backend=NAME/hit=PERCENT-CPUS, for benchstat's -col and
+// -row flags.
+func BenchmarkBackends(b *testing.B) {
+	const numHot = 1024
+	hot := make([]string, numHot)
+	for i := range hot {
+		hot[i] = "hot-" + strconv.Itoa(i)
+	}
+	for _, be := range benchBackends {
+		for _, hitPct := range []int{100, 90, 50, 0} {
+			b.Run("backend="+be.name+"/hit="+strconv.Itoa(hitPct), func(b *testing.B) {
+				get := be.new()
+				// Hold the hot values' handles so they stay interned.
+				keep := make([]interface{}, numHot)
+				for i, s := range hot {
+					keep[i] = get(s)
+				}
+				var miss uint64
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						var s string
+						if i%100 < hitPct {
+							s = hot[i%numHot]
+						} else {
+							s = "miss-" + strconv.FormatUint(atomic.AddUint64(&miss, 1), 10)
+						}
+						if get(s) == nil {
+							// Not Fatal, which mustn't be called
+							// from RunParallel's goroutines.
+							b.Error("nil handle")
+							return
+						}
+						i++
+					}
+				})
+				b.StopTimer()
+				runtime.KeepAlive(keep)
+			})
+		}
+	}
+}
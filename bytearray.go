@@ -0,0 +1,134 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "runtime"
+
+// A Bytes16Interner interns [16]byte values, such as UUIDs, IPv6
+// addresses and MD5 hashes, more cheaply than an Interner: it hashes
+// their bytes directly, and Get doesn't box its argument in an
+// interface unless it creates a new Value. The Get method of the
+// Values it returns returns a [16]byte.
+type Bytes16Interner struct {
+	in *Interner
+}
+
+// A Bytes32Interner is like a Bytes16Interner for [32]byte values,
+// such as SHA-256 hashes.
+type Bytes32Interner struct {
+	in *Interner
+}
+
+// NewBytes16Interner returns a new, empty Bytes16Interner.
+func NewBytes16Interner() *Bytes16Interner {
+	return &Bytes16Interner{in: newByteArrayInterner()}
+}
+
+// NewBytes32Interner returns a new, empty Bytes32Interner.
+func NewBytes32Interner() *Bytes32Interner {
+	return &Bytes32Interner{in: newByteArrayInterner()}
+}
+
+func newByteArrayInterner() *Interner {
+	in := New()
+	in.byteArrays = true
+	return in
+}
+
+// Get returns the Value for b. Get(b) == Get(c) if and only if b == c.
+//
+// Values from a Bytes16Interner are distinct from those of any
+// Interner.
+func (bi *Bytes16Interner) Get(b [16]byte) *Value {
+	in := bi.in
+	if in.valSafe != nil {
+		return in.Get(b)
+	}
+	h := tableHashString(bytesToString(b[:]))
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	addr := in.tab.findFunc(h, func(v *Value) bool {
+		c, ok := v.cmpVal.([16]byte)
+		return ok && c == b
+	})
+	if addr == 0 && in.flooded != nil {
+		addr = in.flooded[key{cmpVal: b}]
+	}
+	if v := in.resurrectLocked(addr); v != nil {
+		return v
+	}
+	return in.insertArrayLocked(h, &Value{cmpVal: b, in: in})
+}
+
+// Get returns the Value for b. Get(b) == Get(c) if and only if b == c.
+//
+// Values from a Bytes32Interner are distinct from those of any
+// Interner.
+func (bi *Bytes32Interner) Get(b [32]byte) *Value {
+	in := bi.in
+	if in.valSafe != nil {
+		return in.Get(b)
+	}
+	h := tableHashString(bytesToString(b[:]))
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	addr := in.tab.findFunc(h, func(v *Value) bool {
+		c, ok := v.cmpVal.([32]byte)
+		return ok && c == b
+	})
+	if addr == 0 && in.flooded != nil {
+		addr = in.flooded[key{cmpVal: b}]
+	}
+	if v := in.resurrectLocked(addr); v != nil {
+		return v
+	}
+	return in.insertArrayLocked(h, &Value{cmpVal: b, in: in})
+}
+
+// Len returns the number of Values in bi.
+func (bi *Bytes16Interner) Len() int { return bi.in.Stats().Entries }
+
+// Len returns the number of Values in bi.
+func (bi *Bytes32Interner) Len() int { return bi.in.Stats().Entries }
+
+// resurrectLocked returns the Value at addr, found in in's table, or
+// nil if addr is zero or its finalizer has claimed it, in which case
+// it's removed, for the caller to replace.
+//
+//go:nocheckptr
+func (in *Interner) resurrectLocked(addr uintptr) *Value {
+	if addr == 0 {
+		return nil
+	}
+	v := valueAt(addr)
+	if !v.resurrect() {
+		in.removeLocked(v)
+		return nil
+	}
+	in.hits++
+	return v
+}
+
+// insertArrayLocked adds v, whose byte array has hash h, to in's table.
+func (in *Interner) insertArrayLocked(h uint64, v *Value) *Value {
+	in.misses++
+	// SetFinalizer before uintptr conversion, as in insertLocked.
+	runtime.SetFinalizer(v, finalize)
+	in.tableInsertLocked(h, v)
+	return v
+}
+
+// hashByteArray returns the table hash of cmpVal's bytes if it's a
+// byte array interned by a Bytes16Interner or Bytes32Interner, so that
+// the hash matches that computed by their Get methods.
+func hashByteArray(cmpVal interface{}) uint64 {
+	switch b := cmpVal.(type) {
+	case [16]byte:
+		return tableHashString(bytesToString(b[:]))
+	case [32]byte:
+		return tableHashString(bytesToString(b[:]))
+	}
+	return tableHashValue(cmpVal)
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBytes16Interner(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	bi := NewBytes16Interner()
+	a := bi.Get([16]byte{1, 2, 3})
+	b := bi.Get([16]byte{1, 2, 4})
+	if a == b {
+		t.Fatal("distinct arrays share a Value")
+	}
+	if bi.Get([16]byte{1, 2, 3}) != a {
+		t.Error("Get not canonical")
+	}
+	if got := a.Get(); got != [16]byte{1, 2, 3} {
+		t.Errorf("Get() = %v", got)
+	}
+	if n := bi.Len(); n != 2 {
+		t.Errorf("Len = %d; want 2", n)
+	}
+	if n := testing.AllocsPerRun(100, func() { bi.Get([16]byte{1, 2, 3}) }); n != 0 {
+		t.Errorf("Get allocated %v objects on a hit; want 0", n)
+	}
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+}
+
+func TestBytes32Interner(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	bi := NewBytes32Interner()
+	var k [32]byte
+	k[31] = 9
+	a := bi.Get(k)
+	if bi.Get(k) != a || a.Get() != k {
+		t.Error("Get not canonical")
+	}
+	if n := testing.AllocsPerRun(100, func() { bi.Get(k) }); n != 0 {
+		t.Errorf("Get allocated %v objects on a hit; want 0", n)
+	}
+	runtime.KeepAlive(a)
+}
+
+func TestBytesInternerCollect(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("Values aren't collected in safe-but-leaky mode")
+	}
+	bi := NewBytes16Interner()
+	keep := bi.Get([16]byte{0xff})
+	func() {
+		for i := 0; i < 1000; i++ {
+			bi.Get([16]byte{byte(i), byte(i >> 8)})
+		}
+	}()
+	for i := 0; i < 100 && bi.Len() > 1; i++ {
+		runtime.GC()
+	}
+	if n := bi.Len(); n != 1 {
+		t.Fatalf("Len after GC = %d; want 1", n)
+	}
+	if bi.Get([16]byte{0xff}) != keep {
+		t.Error("kept Value was collected")
+	}
+	runtime.KeepAlive(keep)
+}
+
+func TestBytesInternerClaimed(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no finalizers in safe-but-leaky mode")
+	}
+	bi := NewBytes16Interner()
+	b := [16]byte{1}
+	v := bi.Get(b)
+	// As if v's finalizer had decided to remove it.
+	atomic.StoreUint32(&v.state, stateDead)
+	w := bi.Get(b)
+	if w == v {
+		t.Fatal("Get returned a Value its finalizer had claimed")
+	}
+	if bi.Get(b) != w || bi.Len() != 1 {
+		t.Errorf("replacement isn't canonical; Len = %d", bi.Len())
+	}
+	runtime.KeepAlive(v)
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// A Cache is a small cache of recently returned Values, held by one
+// goroutine in front of an Interner, such as by each worker of a
+// parser. Gets that hit in the Cache don't take the Interner's lock,
+// so workloads with temporal locality contend less.
+//
+// A Cache is direct-mapped: each value can only be held in one entry,
+// chosen by its hash, and replaces whatever the entry held. Held
+// Values are kept reachable, and so stay interned, until replaced.
+//
+// The Cache only compares values with those of the Values it holds,
+// so with options that change values, such as CaseInsensitive, values
+// that aren't already canonical always miss and go to the Interner.
+// A Value that has been Forgotten may still be returned from a Cache.
+//
+// A Cache is not safe for concurrent use.
+type Cache struct {
+	in      *Interner
+	entries []*Value // len is a power of two
+}
+
+// NewCache returns a new Cache in front of in holding up to about
+// size Values.
+func (in *Interner) NewCache(size int) *Cache {
+	n := 1
+	for n < size {
+		n *= 2
+	}
+	return &Cache{in: in, entries: make([]*Value, n)}
+}
+
+// GetByString is like in.GetByString, for c's Interner in.
+func (c *Cache) GetByString(s string) *Value {
+	if c.bypass() {
+		return c.in.GetByString(s)
+	}
+	e := &c.entries[tableHashString(s)&uint64(len(c.entries)-1)]
+	if v := *e; v != nil {
+		if vs, ok := v.cmpVal.(string); ok && vs == s {
+			return v
+		}
+	}
+	v := c.in.GetByString(s)
+	*e = v
+	return v
+}
+
+// Get is like in.Get, for c's Interner in.
+func (c *Cache) Get(cmpVal interface{}) *Value {
+	if s, ok := cmpVal.(string); ok {
+		return c.GetByString(s)
+	}
+	if c.bypass() {
+		return c.in.Get(cmpVal)
+	}
+	e := &c.entries[tableHashValue(cmpVal)&uint64(len(c.entries)-1)]
+	if v := *e; v != nil && v.cmpVal == cmpVal {
+		return v
+	}
+	v := c.in.Get(cmpVal)
+	*e = v
+	return v
+}
+
+// bypass reports whether c's Interner is returning Values that
+// mustn't be cached, because they're not canonical.
+func (c *Cache) bypass() bool {
+	return c.in.isDisabled() || c.in.shadow != nil || c.in.admit != nil
+}
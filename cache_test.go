@@ -0,0 +1,61 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	in := New()
+	c := in.NewCache(4)
+	if len(c.entries) != 4 {
+		t.Fatalf("entries = %d", len(c.entries))
+	}
+	for i := 0; i < 100; i++ {
+		s := strconv.Itoa(i % 10)
+		if got, want := c.GetByString(s), in.GetByString(s); got != want {
+			t.Fatalf("GetByString(%q) = %p; Interner has %p", s, got, want)
+		}
+		if got, want := c.Get(i%7), in.Get(i%7); got != want {
+			t.Fatalf("Get(%d) = %p; Interner has %p", i%7, got, want)
+		}
+	}
+	before := in.Stats()
+	c.GetByString("warm")
+	c.GetByString("warm")
+	after := in.Stats()
+	if got := after.Hits + after.Misses - before.Hits - before.Misses; got != 1 {
+		t.Errorf("Interner saw %d Gets; want 1", got)
+	}
+}
+
+func TestCacheAllocs(t *testing.T) {
+	c := New().NewCache(16)
+	c.GetByString(globalString)
+	if n := testing.AllocsPerRun(100, func() { c.GetByString(globalString) }); n != 0 {
+		t.Errorf("GetByString allocated %v objects on a hit; want 0", n)
+	}
+}
+
+func TestCacheBypass(t *testing.T) {
+	in := New(Disabled())
+	c := in.NewCache(4)
+	if c.GetByString("x") == c.GetByString("x") {
+		t.Error("Cache returned shared Values from a disabled Interner")
+	}
+}
+
+func BenchmarkCacheGetByString(b *testing.B) {
+	in := New()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		c := in.NewCache(64)
+		for pb.Next() {
+			c.GetByString(globalString)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// maxSiteDepth is the most frames of a call site's stack recorded.
+const maxSiteDepth = 32
+
+// SampleCallSites returns an Option that records the call stack of
+// one in every rate insertions of a new Value, so that the code paths
+// responsible for growth of the table, such as one interning values of
+// unexpectedly high cardinality, can be found with CallSites.
+//
+// Unlike Profile, which records every insertion, SampleCallSites is
+// cheap enough to leave enabled in production at a modest rate, such
+// as 100. A rate of 1 records every insertion; SampleCallSites does
+// nothing if rate is less than 1.
+func SampleCallSites(rate int) Option {
+	return func(in *Interner) {
+		if rate < 1 {
+			return
+		}
+		in.siteRate = uint64(rate)
+		in.sites = make(map[[maxSiteDepth]uintptr]*CallSite)
+		in.siteOf = make(map[uintptr]*CallSite)
+	}
+}
+
+// A CallSite is a call stack that inserted Values into an Interner,
+// as sampled by SampleCallSites.
+type CallSite struct {
+	// Stack holds the program counters of the stack, innermost
+	// first, as returned by runtime.Callers. It begins within
+	// package intern.
+	Stack []uintptr
+
+	// Inserts is the number of sampled insertions made by the stack.
+	// Multiplied by the sampling rate, it estimates the number of
+	// insertions.
+	Inserts uint64
+
+	// Live is the number of the sampled Values that are still
+	// interned. It isn't maintained for Interners using
+	// WeakPointers, for which it is always zero.
+	Live int
+}
+
+// Frames returns the frames of s's stack.
+func (s CallSite) Frames() *runtime.Frames {
+	return runtime.CallersFrames(s.Stack)
+}
+
+// String returns s's counts and the functions, files, and lines of
+// its stack, one frame per line, omitting the frames within package
+// intern.
+func (s CallSite) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d inserts, %d live", s.Inserts, s.Live)
+	frames := s.Frames()
+	for {
+		f, more := frames.Next()
+		if !isInternFunc(f.Function) {
+			fmt.Fprintf(&b, "\n\t%s\n\t\t%s:%d", f.Function, f.File, f.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// isInternFunc reports whether fn, a function's full name, belongs to
+// package intern itself, other than its tests.
+func isInternFunc(fn string) bool {
+	const prefix = "go4.org/intern."
+	return strings.HasPrefix(fn, prefix) && !strings.HasPrefix(fn[len(prefix):], "Test")
+}
+
+// CallSites returns the call sites sampled by SampleCallSites, with
+// those with the most live Values, and then the most insertions,
+// first. It returns nil if in wasn't created with SampleCallSites.
+func (in *Interner) CallSites() []CallSite {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.sites == nil {
+		return nil
+	}
+	sites := make([]CallSite, 0, len(in.sites))
+	for _, s := range in.sites {
+		c := *s
+		c.Stack = append([]uintptr(nil), s.Stack...)
+		sites = append(sites, c)
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		a, b := sites[i], sites[j]
+		if a.Live != b.Live {
+			return a.Live > b.Live
+		}
+		return a.Inserts > b.Inserts
+	})
+	return sites
+}
+
+// sampleSiteLocked records the call stack inserting v, if it's
+// sampled. in.mu must be held.
+func (in *Interner) sampleSiteLocked(v *Value) {
+	in.siteInserts++
+	if in.siteInserts%in.siteRate != 0 {
+		return
+	}
+	var pcs [maxSiteDepth]uintptr
+	// Skip runtime.Callers and ourselves.
+	n := runtime.Callers(2, pcs[:])
+	s := in.sites[pcs]
+	if s == nil {
+		s = &CallSite{Stack: append([]uintptr(nil), pcs[:n]...)}
+		in.sites[pcs] = s
+	}
+	s.Inserts++
+	if in.wk == nil {
+		s.Live++
+		in.siteOf[uintptr(unsafe.Pointer(v))] = s
+	}
+}
+
+// unsampleSiteLocked records v's removal from in.
+// in.mu must be held.
+func (in *Interner) unsampleSiteLocked(v *Value) {
+	addr := uintptr(unsafe.Pointer(v))
+	if s := in.siteOf[addr]; s != nil {
+		s.Live--
+		delete(in.siteOf, addr)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func insertMany(in *Interner, prefix string, n int) []*Value {
+	var vs []*Value
+	for i := 0; i < n; i++ {
+		vs = append(vs, in.GetByString(prefix+strconv.Itoa(i)))
+	}
+	return vs
+}
+
+func insertFew(in *Interner, n int) []*Value {
+	var vs []*Value
+	for i := 0; i < n; i++ {
+		vs = append(vs, in.Get(i))
+	}
+	return vs
+}
+
+// hasFunc reports whether s's stack includes the function named fn.
+func hasFunc(s CallSite, fn string) bool {
+	frames := s.Frames()
+	for {
+		f, more := frames.Next()
+		if strings.HasSuffix(f.Function, fn) {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+func TestCallSites(t *testing.T) {
+	in := New(ManualCollect(), SampleCallSites(2))
+	many := insertMany(in, "many", 100)
+	few := insertFew(in, 10)
+	insertMany(in, "many", 100) // hits aren't insertions
+
+	sites := in.CallSites()
+	if len(sites) != 2 {
+		t.Fatalf("got %d call sites; want 2", len(sites))
+	}
+	if s := sites[0]; s.Inserts != 50 || s.Live != 50 || !hasFunc(s, ".insertMany") {
+		t.Errorf("first site = %v; want 50 inserts by insertMany", s)
+	}
+	if s := sites[1]; s.Inserts != 5 || !hasFunc(s, ".insertFew") {
+		t.Errorf("second site = %v; want 5 inserts by insertFew", s)
+	}
+	if s := sites[0].String(); strings.Contains(s, "(*Interner)") || !strings.Contains(s, "TestCallSites") {
+		t.Errorf("String includes frames within package intern, or omits its tests:\n%s", s)
+	}
+
+	for _, v := range many {
+		v.Release()
+		v.Release()
+	}
+	in.Collect()
+	sites = in.CallSites()
+	if s := sites[0]; s.Live != 5 || !hasFunc(s, ".insertFew") {
+		t.Errorf("after Collect, first site = %v; want 5 live from insertFew", s)
+	}
+	if s := sites[1]; s.Live != 0 || s.Inserts != 50 {
+		t.Errorf("after Collect, second site = %v; want 50 inserts, none live", s)
+	}
+	runtime.KeepAlive(few)
+
+	if New().CallSites() != nil {
+		t.Error("CallSites without SampleCallSites isn't nil")
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// WithCapacity returns an Option that sizes the Interner's index for n
+// values when it's created, for Interners whose eventual size is known,
+// so that the index isn't rehashed repeatedly as it grows to that size.
+//
+// The index never shrinks below room for n values, even when the
+// values are collected or removed, as by Compact. It still grows if
+// more than n values are interned.
+func WithCapacity(n int) Option {
+	return func(in *Interner) { in.capacity = n }
+}
+
+// presize sizes in's index for in.capacity values, when in is created.
+func (in *Interner) presize() {
+	n := in.capacity
+	switch {
+	case in.valSafe != nil:
+		in.valSafe = make(map[key]*Value, n)
+	case in.hashMap != nil:
+		in.hashMap = make(map[[16]byte]uintptr, n)
+	case in.wk != nil:
+		// Sized as needed.
+	default:
+		in.tab.floor = n
+		in.tab.resize(n)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestWithCapacity(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	const n = 1000
+	in := New(WithCapacity(n))
+	size := len(in.tab.slots)
+	if size != tableSize(n) {
+		t.Fatalf("initial slots = %d; want %d", size, tableSize(n))
+	}
+	vs := make([]*Value, n)
+	for i := range vs {
+		vs[i] = in.Get(i)
+	}
+	if got := len(in.tab.slots); got != size {
+		t.Errorf("slots = %d after %d Gets; want %d, unchanged", got, n, size)
+	}
+	for i := 1; i < n; i++ {
+		in.Forget(i)
+	}
+	in.Compact()
+	if got := len(in.tab.slots); got != size {
+		t.Errorf("slots = %d after removals; want %d, unshrunk", got, size)
+	}
+	runtime.KeepAlive(vs)
+
+	// Without a capacity, the same removals shrink the table.
+	in = New()
+	for i := range vs {
+		vs[i] = in.Get(i)
+	}
+	for i := 1; i < n; i++ {
+		in.Forget(i)
+	}
+	if got := len(in.tab.slots); got >= size {
+		t.Errorf("slots = %d without WithCapacity; want fewer than %d", got, size)
+	}
+	runtime.KeepAlive(vs)
+}
+
+func TestWithCapacityMaps(t *testing.T) {
+	for _, opt := range []Option{HashKeys(), ManualCollect()} {
+		in := New(WithCapacity(100), opt)
+		v := in.GetByString("x")
+		if in.GetByString("x") != v {
+			t.Error("Values differ")
+		}
+		runtime.KeepAlive(v)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// defaultCloneThreshold is the default for WithCloneThreshold.
+const defaultCloneThreshold = 1 << 10
+
+// WithCloneThreshold returns an Option that sets the length, in bytes,
+// up to which strings are copied when they're first interned. The
+// default is 1KiB; zero or less disables copying.
+//
+// A string passed to Get or GetByString is often a substring of a much
+// larger buffer, such as a line of a file or the body of a request.
+// Interning it as is would keep the whole buffer reachable for as long
+// as the Value is. Copying the string costs an allocation on each miss,
+// but frees the buffer once the caller is done with it. Copying long
+// strings is less useful, as they're less likely to be small parts of
+// large buffers, and more costly.
+//
+// Strings in interfaces passed to Get, other than plain strings,
+// aren't copied, nor are strings loaded from an Arena, which are
+// already parts of its file's mapping.
+func WithCloneThreshold(n int) Option {
+	return func(in *Interner) { in.cloneMax = n }
+}
+
+// cloneString returns a copy of s in a new allocation.
+func cloneString(s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	b := make([]byte, len(s))
+	copy(b, s)
+	return bytesToString(b)
+}
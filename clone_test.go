@@ -0,0 +1,55 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCloneThreshold(t *testing.T) {
+	big := strings.Repeat("x", 4096) + "needle" + strings.Repeat("y", 4096)
+	sub := big[4096 : 4096+len("needle")]
+	long := big[:2048]
+
+	in := New()
+	v := in.GetByString(sub)
+	if got := v.Get().(string); stringData(got) == stringData(sub) {
+		t.Error("short substring was interned without copying")
+	}
+	lv := in.GetByString(long)
+	if got := lv.Get().(string); stringData(got) != stringData(long) {
+		t.Error("string above the threshold was copied")
+	}
+
+	if got := in.getString(big[:3], getCtx{shared: true}).Get().(string); stringData(got) != stringData(big) {
+		t.Error("string from an Arena was copied")
+	}
+
+	in = New(WithCloneThreshold(0))
+	v2 := in.GetByString(sub)
+	if got := v2.Get().(string); stringData(got) != stringData(sub) {
+		t.Error("string was copied with copying disabled")
+	}
+	if got := in.GetByString(""); got.Get() != "" {
+		t.Errorf("empty string = %q", got.Get())
+	}
+	runtime.KeepAlive(v)
+	runtime.KeepAlive(lv)
+	runtime.KeepAlive(v2)
+}
+
+func TestCloneString(t *testing.T) {
+	for _, s := range []string{"", "a", "hello, world"} {
+		c := cloneString(s)
+		if c != s {
+			t.Errorf("cloneString(%q) = %q", s, c)
+		}
+		if len(s) > 0 && stringData(c) == stringData(s) {
+			t.Errorf("cloneString(%q) shares memory", s)
+		}
+	}
+}
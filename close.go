@@ -0,0 +1,88 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"errors"
+	"sync/atomic"
+	"unsafe"
+)
+
+// ErrClosed is returned by TryGet, and is the value Get panics with,
+// once the Interner has been closed.
+var ErrClosed = errors.New("intern: Interner closed")
+
+// Close releases in's resources, so that an Interner made in a plugin
+// or test leaves nothing behind. It's for Interners made with New; the
+// default Interner mustn't be closed.
+//
+// Close stops in's background goroutine, if it has one, and waits for
+// it to exit. It then drops in's references to its values and to any
+// state its options keep: the index, constants, snapshots, sampled
+// call sites, and pending events. Values already returned remain
+// valid, but in no longer holds them, and values found through a
+// parent (see WithParent) are left in the parent. Files opened by an
+// Arena are separate and aren't closed; see Arena.Close.
+//
+// After Close, in fails fast: Get and the like panic with ErrClosed,
+// TryGet returns ErrClosed, and Lookup finds nothing, except that
+// Get(nil) and Lookup(nil) still return the sentinel Value.
+//
+// Close is safe to call more than once, and always returns nil.
+func (in *Interner) Close() error {
+	in.closeOnce.Do(func() {
+		atomic.StoreUint32(&in.closed, closedByClose)
+		if j := in.janitor; j != nil {
+			close(j.stop)
+			<-j.done
+		}
+		in.drop()
+		in.reset()
+	})
+	return nil
+}
+
+// Values of an Interner's closed field, other than zero.
+const (
+	closedByClose  = 1 + iota
+	closedUntested // awaiting a RuntimePolicy; see PanicOnUntestedRuntime
+)
+
+// isClosed reports whether in's Gets fail fast, because it has been
+// closed, or under PanicOnUntestedRuntime.
+func (in *Interner) isClosed() bool {
+	return atomic.LoadUint32(&in.closed) != 0
+}
+
+// closedErr returns the error Gets on in fail with, or nil.
+func (in *Interner) closedErr() error {
+	switch atomic.LoadUint32(&in.closed) {
+	case closedByClose:
+		return ErrClosed
+	case closedUntested:
+		return ErrUntestedRuntime
+	}
+	return nil
+}
+
+// drop releases the state of in's options, other than its index,
+// which reset empties, when in is closed.
+func (in *Interner) drop() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.drainFinalizedLocked()
+	if in.profile {
+		in.forEachLocked(func(v *Value) {
+			valuesProfile().Remove(uintptr(unsafe.Pointer(v)))
+		})
+	}
+	atomic.StorePointer(&in.consts, nil)
+	in.dropSnapshot()
+	in.sites = nil
+	in.siteOf = nil
+	in.events = nil
+	in.evicted = [numEvicted]interface{}{}
+	in.admit = nil
+}
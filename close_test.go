@@ -0,0 +1,55 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	in := New(WithJanitor(time.Hour), Snapshots(), SampleCallSites(1))
+	in.RegisterConstants("GET")
+	v := in.GetByString("x")
+	in.PublishSnapshot()
+	in.Close()
+
+	if got := in.Stats().Entries; got != 0 {
+		t.Errorf("Entries = %d after Close; want 0", got)
+	}
+	if in.constants() != nil || len(in.CallSites()) != 0 {
+		t.Error("Close didn't drop constants or call sites")
+	}
+	if v.Get() != "x" {
+		t.Error("Value invalid after Close")
+	}
+	if _, ok := in.Lookup("x"); ok {
+		t.Error("Lookup found value after Close")
+	}
+	if got, ok := in.Lookup(nil); got != nilValue || !ok {
+		t.Error("Lookup(nil) failed after Close")
+	}
+	if in.Get(nil) != nilValue {
+		t.Error("Get(nil) failed after Close")
+	}
+	if _, err := in.TryGet("x"); !errors.Is(err, ErrClosed) {
+		t.Errorf("TryGet error = %v; want ErrClosed", err)
+	}
+	for name, f := range map[string]func(){
+		"Get":         func() { in.Get("x") },
+		"GetByString": func() { in.GetByString("GET") },
+		"String":      func() { in.String("x") },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrClosed {
+					t.Errorf("%s after Close panicked with %v; want ErrClosed", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The interngen command generates code for interning the fields of
+// struct types with go4.org/intern, without reflection at run time.
+//
+// For each named struct type, it generates an InternFields method
+// that replaces the type's string and []string fields tagged with
+// `intern:""` with their canonical copies, as by intern.String. For
+// types whose fields are all comparable, it also generates a typed
+// interner: a TypeInterner type whose Get method interns a value of
+// the type, after interning its fields.
+//
+// Usage:
+//
+//	interngen -type=T[,U...] [-output=file] [dir]
+//
+// It's meant to be run by go generate, as by a directive such as
+//
+//	//go:generate interngen -type=Record
+//
+// in which case dir is the package being generated, and the output
+// defaults to <type>_intern.go, in lower case, for the first type.
+package main // import "go4.org/intern/cmd/interngen"
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("interngen: ")
+	types := flag.String("type", "", "comma-separated list of struct type names; required")
+	output := flag.String("output", "", "output file name; default <type>_intern.go")
+	flag.Parse()
+	if *types == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	names := strings.Split(*types, ",")
+	src, err := generate(dir, names, strings.Join(os.Args[1:], " "))
+	if err != nil {
+		log.Fatal(err)
+	}
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, strings.ToLower(names[0])+"_intern.go")
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// A structType is a struct type to generate code for.
+type structType struct {
+	name       string
+	fields     []field // tagged fields
+	comparable bool
+}
+
+// A field is a field tagged for interning.
+type field struct {
+	name  string
+	slice bool // []string rather than string
+}
+
+// generate returns the generated source for the named types of the
+// package in dir. args are the command's arguments, for the header.
+func generate(dir string, names []string, args string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("found %d packages in %s; want 1", len(pkgs), dir)
+	}
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+
+	specs := map[string]*ast.TypeSpec{}
+	for _, f := range pkg.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if ts, ok := n.(*ast.TypeSpec); ok {
+				specs[ts.Name.Name] = ts
+			}
+			return true
+		})
+	}
+	var sts []structType
+	for _, name := range names {
+		ts, ok := specs[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in %s", name, dir)
+		}
+		st, err := parseStruct(fset, ts)
+		if err != nil {
+			return nil, err
+		}
+		sts = append(sts, st)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by \"interngen %s\"; DO NOT EDIT.\n\n", args)
+	fmt.Fprintf(&buf, "package %s\n\nimport \"go4.org/intern\"\n", pkg.Name)
+	for _, st := range sts {
+		writeStruct(&buf, st)
+	}
+	return format.Source(buf.Bytes())
+}
+
+func parseStruct(fset *token.FileSet, ts *ast.TypeSpec) (structType, error) {
+	st := structType{name: ts.Name.Name, comparable: true}
+	s, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return st, fmt.Errorf("%s: %s is not a struct type", fset.Position(ts.Pos()), st.name)
+	}
+	for _, f := range s.Fields.List {
+		if !comparableExpr(f.Type) {
+			st.comparable = false
+		}
+		if f.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return st, err
+		}
+		if _, ok := reflect.StructTag(tag).Lookup("intern"); !ok {
+			continue
+		}
+		var slice bool
+		switch typ := exprString(f.Type); typ {
+		case "string":
+		case "[]string":
+			slice = true
+		default:
+			return st, fmt.Errorf("%s: intern tag on field of type %s; want string or []string", fset.Position(f.Pos()), typ)
+		}
+		for _, n := range f.Names {
+			st.fields = append(st.fields, field{n.Name, slice})
+		}
+	}
+	return st, nil
+}
+
+// exprString returns the source form of the type expression e.
+func exprString(e ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, token.NewFileSet(), e)
+	return buf.String()
+}
+
+// comparableExpr reports whether the type e can be compared with ==,
+// as far as can be told without type checking: types other than
+// slices, maps, and funcs, and arrays and inline structs of them, are
+// assumed comparable.
+func comparableExpr(e ast.Expr) bool {
+	switch t := e.(type) {
+	case *ast.ArrayType:
+		return t.Len != nil && comparableExpr(t.Elt)
+	case *ast.MapType, *ast.FuncType:
+		return false
+	case *ast.StructType:
+		for _, f := range t.Fields.List {
+			if !comparableExpr(f.Type) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func writeStruct(buf *bytes.Buffer, st structType) {
+	recv := strings.ToLower(st.name[:1])
+	fmt.Fprintf(buf, "\n// InternFields replaces %s's fields tagged `intern` with their\n", recv)
+	fmt.Fprintf(buf, "// canonical copies, as by intern.String.\n")
+	fmt.Fprintf(buf, "func (%s *%s) InternFields() {\n", recv, st.name)
+	for _, f := range st.fields {
+		if f.slice {
+			fmt.Fprintf(buf, "for i, s := range %s.%s {\n%s.%s[i] = intern.String(s)\n}\n", recv, f.name, recv, f.name)
+		} else {
+			fmt.Fprintf(buf, "%s.%s = intern.String(%s.%s)\n", recv, f.name, recv, f.name)
+		}
+	}
+	fmt.Fprintf(buf, "}\n")
+	if !st.comparable {
+		return
+	}
+	fmt.Fprintf(buf, `
+// %[1]sInterner interns values of type %[1]s.
+type %[1]sInterner struct {
+	in *intern.Interner
+}
+
+// New%[1]sInterner returns a new %[1]sInterner backed by an Interner
+// configured by opts.
+func New%[1]sInterner(opts ...intern.Option) *%[1]sInterner {
+	return &%[1]sInterner{intern.New(opts...)}
+}
+
+// Get returns the Value for v, after interning its fields as by
+// InternFields.
+func (ti *%[1]sInterner) Get(v %[1]s) *intern.Value {
+	v.InternFields()
+	return ti.in.Get(v)
+}
+
+// Value returns the %[1]s held by v, a Value returned by Get.
+func (ti *%[1]sInterner) Value(v *intern.Value) %[1]s {
+	return v.Get().(%[1]s)
+}
+`, st.name)
+}
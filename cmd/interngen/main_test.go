@@ -0,0 +1,51 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate(t *testing.T) {
+	got, err := generate("testdata", []string{"Record", "Row"}, "-type=Record,Row")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "record_intern.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("generated:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, tt := range []struct {
+		types []string
+		want  string
+	}{
+		{[]string{"Missing"}, "not found"},
+		{[]string{"Bad"}, "want string or []string"},
+		{[]string{"NotStruct"}, "not a struct type"},
+	} {
+		_, err := generate(filepath.Join("testdata", "bad"), tt.types, "")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("generate(%v) = %v; want error containing %q", tt.types, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package bad
+
+type Bad struct {
+	N int `intern:""`
+}
+
+type NotStruct int
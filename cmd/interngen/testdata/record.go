@@ -0,0 +1,16 @@
+package record
+
+// Record is comparable, so gets a typed interner.
+type Record struct {
+	Country    string `intern:""`
+	Status     string `json:"status" intern:""`
+	ID         int64
+	Note, Kind string `intern:""`
+}
+
+// Row isn't comparable.
+type Row struct {
+	Tags  []string `intern:""`
+	Cells map[string]string
+	Name  string `intern:""`
+}
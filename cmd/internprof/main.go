@@ -0,0 +1,198 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The internprof command reads a pprof heap profile and reports the
+// allocation sites that create strings, estimating the memory that
+// interning their strings with go4.org/intern would save, to guide
+// where to adopt it.
+//
+// Heap profiles record where memory was allocated but not what it
+// holds, so internprof can't see how many of a site's strings are
+// duplicates, and must guess which sites allocate strings at all.
+// A site whose samples pass through a string-creating function, such
+// as strings.Builder's, is reported as being of kind "string". Recent
+// Go runtimes omit their own frames, such as that converting a []byte
+// to a string, from heap profiles, so other sites whose objects are
+// small on average, no larger than -maxsize, are reported as of kind
+// "small", as candidates to check. Each site's savings are estimated
+// as its bytes times an assumed duplicate fraction, given by -dup.
+//
+// Usage:
+//
+//	internprof [flags] heap.pprof
+//
+// The profile can be taken with pprof.WriteHeapProfile or from
+// net/http/pprof's /debug/pprof/heap endpoint.
+package main // import "go4.org/intern/cmd/internprof"
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("internprof: ")
+	var c config
+	flag.StringVar(&c.sampleIndex, "sample_index", "inuse_space", "sample value to report: inuse_space or alloc_space")
+	flag.IntVar(&c.top, "top", 20, "number of sites to report")
+	flag.Float64Var(&c.dup, "dup", 0.5, "assumed fraction of each site's string bytes that are duplicates")
+	flag.Int64Var(&c.maxSize, "maxsize", 256, "largest average object size of sites reported without evidence of allocating strings")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: internprof [flags] heap.pprof\n")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+	data, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	p, err := parseProfile(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sites, err := analyze(p, c)
+	if err != nil {
+		log.Fatal(err)
+	}
+	report(os.Stdout, sites, c)
+}
+
+type config struct {
+	sampleIndex string  // name of the bytes sample value
+	top         int     // number of sites to report
+	dup         float64 // assumed duplicate fraction
+	maxSize     int64   // largest average size of "small" sites
+}
+
+// A site is a line of code allocating memory.
+type site struct {
+	name    string // function and line
+	kind    string // "string" or "small"
+	objects int64
+	bytes   int64
+}
+
+func (s site) avgSize() int64 {
+	if s.objects == 0 {
+		return 0
+	}
+	return s.bytes / s.objects
+}
+
+// stringFuncs are functions that allocate the memory of new strings.
+// A sample whose stack includes one allocated a string.
+var stringFuncs = map[string]bool{
+	"runtime.slicebytetostring":      true,
+	"runtime.slicerunetostring":      true,
+	"runtime.concatstrings":          true,
+	"runtime.concatstring2":          true,
+	"runtime.concatstring3":          true,
+	"runtime.concatstring4":          true,
+	"runtime.concatstring5":          true,
+	"runtime.intstring":              true,
+	"runtime.rawstring":              true,
+	"runtime.rawstringtmp":           true,
+	"strings.(*Builder).grow":        true,
+	"strings.Repeat":                 true,
+	"strings.(*Builder).WriteString": true,
+}
+
+// analyze returns the sites in p that allocate strings, or may, most
+// bytes first.
+func analyze(p *profile, c config) ([]site, error) {
+	objIndex := strings.Replace(c.sampleIndex, "_space", "_objects", 1)
+	bi, oi := -1, -1
+	for i, t := range p.sampleTypes {
+		switch t {
+		case c.sampleIndex:
+			bi = i
+		case objIndex:
+			oi = i
+		}
+	}
+	if bi < 0 || oi < 0 {
+		return nil, fmt.Errorf("profile has no %s and %s values; is it a heap profile?", c.sampleIndex, objIndex)
+	}
+
+	bySite := map[string]*site{}
+	for _, s := range p.samples {
+		if bi >= len(s.values) || oi >= len(s.values) {
+			continue
+		}
+		name, kind := allocSite(p, s)
+		if name == "" {
+			continue
+		}
+		st := bySite[name]
+		if st == nil {
+			st = &site{name: name, kind: kind}
+			bySite[name] = st
+		}
+		if kind == "string" {
+			st.kind = kind
+		}
+		st.bytes += s.values[bi]
+		st.objects += s.values[oi]
+	}
+	var sites []site
+	for _, st := range bySite {
+		if st.bytes > 0 && (st.kind == "string" || st.avgSize() <= c.maxSize) {
+			sites = append(sites, *st)
+		}
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].bytes != sites[j].bytes {
+			return sites[i].bytes > sites[j].bytes
+		}
+		return sites[i].name < sites[j].name
+	})
+	return sites, nil
+}
+
+// allocSite returns the site that allocated s's memory: the innermost
+// frame of s's stack outside the runtime and package strings, as
+// function:line. Its kind is "string" if s's stack passes through a
+// string-creating function, and "small" otherwise.
+func allocSite(p *profile, s sample) (name, kind string) {
+	kind = "small"
+	for _, id := range s.locations {
+		for _, f := range p.locations[id] {
+			if stringFuncs[f.fn] {
+				kind = "string"
+			}
+			if strings.HasPrefix(f.fn, "runtime.") || strings.HasPrefix(f.fn, "strings.") {
+				continue
+			}
+			return fmt.Sprintf("%s:%d", f.fn, f.line), kind
+		}
+	}
+	return "", ""
+}
+
+func report(w io.Writer, sites []site, c config) {
+	var total int64
+	for _, s := range sites {
+		total += s.bytes
+	}
+	fmt.Fprintf(w, "%d sites allocated %d bytes of strings or small objects (%s); assuming %.0f%% duplicates, interning would save ~%d bytes\n\n",
+		len(sites), total, c.sampleIndex, c.dup*100, int64(float64(total)*c.dup))
+	if len(sites) > c.top {
+		sites = sites[:c.top]
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "bytes\tobjects\tavg size\test. saved\t  kind\t  site\n")
+	for _, s := range sites {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t  %s\t  %s\n", s.bytes, s.objects, s.avgSize(), int64(float64(s.bytes)*c.dup), s.kind, s.name)
+	}
+	tw.Flush()
+}
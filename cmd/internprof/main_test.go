@@ -0,0 +1,78 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+var retained []string
+
+//go:noinline
+func makeStrings(b []byte, n int) {
+	for i := 0; i < n; i++ {
+		retained = append(retained, string(b))
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	defer func(rate int) { runtime.MemProfileRate = rate }(runtime.MemProfileRate)
+	runtime.MemProfileRate = 1
+	makeStrings([]byte("some repeated status value"), 1000)
+	// The profile is as of the last GC cycle to complete after
+	// the allocations.
+	runtime.GC()
+	runtime.GC()
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("heap").WriteTo(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	p, err := parseProfile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := config{sampleIndex: "inuse_space", top: 5, dup: 0.5, maxSize: 256}
+	sites, err := analyze(p, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found *site
+	for i, s := range sites {
+		if strings.Contains(s.name, ".makeStrings:") && s.objects >= 1000 {
+			found = &sites[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("makeStrings not among sites %v; profile: %+v", sites, p)
+	}
+	if found.bytes < 1000*int64(len("some repeated status value")) {
+		t.Errorf("makeStrings site = %+v; want at least 1000 objects", *found)
+	}
+
+	var out bytes.Buffer
+	report(&out, sites, c)
+	if !strings.Contains(out.String(), "makeStrings") {
+		t.Errorf("report lacks makeStrings:\n%s", out.String())
+	}
+	runtime.KeepAlive(retained)
+}
+
+func TestParseProfileErrors(t *testing.T) {
+	if _, err := parseProfile([]byte{0x0a, 0x10}); err == nil {
+		t.Error("truncated profile parsed")
+	}
+	p, err := parseProfile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := analyze(p, config{sampleIndex: "inuse_space"}); err == nil {
+		t.Error("analyze of profile without heap values succeeded")
+	}
+}
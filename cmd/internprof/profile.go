@@ -0,0 +1,216 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// A profile is the part of a pprof profile (profile.proto) that
+// internprof uses, decoded without depending on the pprof module.
+type profile struct {
+	sampleTypes []string // the type of each sample value, such as "inuse_space"
+	samples     []sample
+	locations   map[uint64][]frame // location ID to frames, innermost first
+}
+
+// A frame is a function and line in a stack.
+type frame struct {
+	fn   string
+	line int64
+}
+
+// A sample is one sampled stack.
+type sample struct {
+	locations []uint64 // location IDs, leaf first
+	values    []int64
+}
+
+// parseProfile decodes a pprof profile, gzipped or not.
+func parseProfile(data []byte) (*profile, error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = ioutil.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		strs        []string
+		sampleTypes [][2]int64                 // type and unit string indexes
+		functions   = map[uint64]int64{}       // function ID to name string index
+		locLines    = map[uint64][][2]uint64{} // location ID to function IDs and lines
+		p           = &profile{locations: map[uint64][]frame{}}
+	)
+	err := fields(data, func(num int, wire int, v uint64, b []byte) error {
+		switch num {
+		case 1: // sample_type
+			var vt [2]int64
+			err := fields(b, func(num, wire int, v uint64, b []byte) error {
+				if num == 1 || num == 2 {
+					vt[num-1] = int64(v)
+				}
+				return nil
+			})
+			sampleTypes = append(sampleTypes, vt)
+			return err
+		case 2: // sample
+			var s sample
+			err := fields(b, func(num, wire int, v uint64, b []byte) error {
+				switch num {
+				case 1:
+					return packed(wire, v, b, func(x uint64) { s.locations = append(s.locations, x) })
+				case 2:
+					return packed(wire, v, b, func(x uint64) { s.values = append(s.values, int64(x)) })
+				}
+				return nil
+			})
+			p.samples = append(p.samples, s)
+			return err
+		case 4: // location
+			var id uint64
+			var lines [][2]uint64
+			err := fields(b, func(num, wire int, v uint64, b []byte) error {
+				switch num {
+				case 1:
+					id = v
+				case 4: // line
+					var l [2]uint64
+					err := fields(b, func(num, wire int, v uint64, b []byte) error {
+						if num == 1 || num == 2 {
+							l[num-1] = v
+						}
+						return nil
+					})
+					lines = append(lines, l)
+					return err
+				}
+				return nil
+			})
+			locLines[id] = lines
+			return err
+		case 5: // function
+			var id uint64
+			var name int64
+			err := fields(b, func(num, wire int, v uint64, b []byte) error {
+				switch num {
+				case 1:
+					id = v
+				case 2:
+					name = int64(v)
+				}
+				return nil
+			})
+			functions[id] = name
+			return err
+		case 6: // string_table
+			strs = append(strs, string(b))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	str := func(i int64) string {
+		if i < 0 || i >= int64(len(strs)) {
+			return ""
+		}
+		return strs[i]
+	}
+	for _, vt := range sampleTypes {
+		p.sampleTypes = append(p.sampleTypes, str(vt[0]))
+	}
+	for id, lines := range locLines {
+		// Lines are innermost first, as inlined calls are.
+		frames := make([]frame, len(lines))
+		for i, l := range lines {
+			frames[i] = frame{str(functions[l[0]]), int64(l[1])}
+		}
+		p.locations[id] = frames
+	}
+	return p, nil
+}
+
+var errTruncated = errors.New("truncated profile")
+
+// fields calls f with each field of the protobuf message b: its
+// number, wire type, and value, which is v for varints and fixed
+// values and b for length-delimited ones.
+func fields(b []byte, f func(num, wire int, v uint64, b []byte) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errTruncated
+		}
+		b = b[n:]
+		num, wire := int(key>>3), int(key&7)
+		var v uint64
+		var lb []byte
+		switch wire {
+		case 0: // varint
+			v, n = binary.Uvarint(b)
+			if n <= 0 {
+				return errTruncated
+			}
+			b = b[n:]
+		case 1: // fixed64
+			if len(b) < 8 {
+				return errTruncated
+			}
+			for i := 7; i >= 0; i-- {
+				v = v<<8 | uint64(b[i])
+			}
+			b = b[8:]
+		case 2: // length-delimited
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return errTruncated
+			}
+			lb = b[n : n+int(l)]
+			b = b[n+int(l):]
+		case 5: // fixed32
+			if len(b) < 4 {
+				return errTruncated
+			}
+			for i := 3; i >= 0; i-- {
+				v = v<<8 | uint64(b[i])
+			}
+			b = b[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wire)
+		}
+		if err := f(num, wire, v, lb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// packed calls f with each value of a repeated varint field, which may
+// be packed (wire type 2) or not.
+func packed(wire int, v uint64, b []byte, f func(uint64)) error {
+	if wire != 2 {
+		f(v)
+		return nil
+	}
+	for len(b) > 0 {
+		x, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errTruncated
+		}
+		f(x)
+		b = b[n:]
+	}
+	return nil
+}
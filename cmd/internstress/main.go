@@ -0,0 +1,240 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The internstress command stress tests go4.org/intern, for validating
+// it against new Go runtimes.
+//
+// It runs goroutines that repeatedly Get values, keeping some of the
+// returned Values and dropping others, while the garbage collector
+// runs, and checks that the Interner keeps its guarantees: that a
+// value's Value doesn't change while it's reachable, and that each
+// Value holds the value it was created from. It reports the number of
+// violations and percentiles of Get latency periodically and on exit,
+// and exits with status 1 if there were any violations.
+//
+// Usage:
+//
+//	internstress [flags]
+//
+// For example, to run 64 goroutines for six hours over a million keys
+// of 8 to 256 bytes, collecting garbage every 10ms:
+//
+//	internstress -goroutines=64 -duration=6h -keys=1000000 -size=8-256 -gc=10ms
+package main // import "go4.org/intern/cmd/internstress"
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go4.org/intern"
+)
+
+func main() {
+	var c config
+	flag.IntVar(&c.goroutines, "goroutines", runtime.GOMAXPROCS(0), "number of goroutines calling Get")
+	flag.DurationVar(&c.duration, "duration", time.Minute, "how long to run")
+	flag.IntVar(&c.keys, "keys", 10000, "number of distinct keys")
+	flag.Float64Var(&c.churn, "churn", 0.5, "probability of dropping a held Value after each Get")
+	size := flag.String("size", "8-64", "range of key sizes in bytes, as min-max")
+	flag.DurationVar(&c.gcEvery, "gc", 100*time.Millisecond, "interval between forced garbage collections, or 0 for none")
+	flag.DurationVar(&c.reportEvery, "report", time.Minute, "interval between progress reports")
+	flag.Parse()
+
+	var err error
+	c.minSize, c.maxSize, err = parseSize(*size)
+	if err != nil {
+		log.Fatalf("bad -size: %v", err)
+	}
+	if c.goroutines < 1 || c.keys < 1 || c.churn < 0 || c.churn > 1 {
+		log.Fatal("-goroutines and -keys must be positive and -churn in [0, 1]")
+	}
+	if r := run(c, os.Stdout); r.violations > 0 {
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	goroutines       int
+	duration         time.Duration
+	keys             int
+	churn            float64
+	minSize, maxSize int
+	gcEvery          time.Duration
+	reportEvery      time.Duration
+}
+
+// parseSize parses a key size range such as "8-64", or a single size.
+func parseSize(s string) (min, max int, err error) {
+	lo, hi := s, s
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		lo, hi = s[:i], s[i+1:]
+	}
+	if min, err = strconv.Atoi(lo); err != nil {
+		return 0, 0, err
+	}
+	if max, err = strconv.Atoi(hi); err != nil {
+		return 0, 0, err
+	}
+	if min < 1 || max < min {
+		return 0, 0, fmt.Errorf("invalid range %q", s)
+	}
+	return min, max, nil
+}
+
+// key returns the key numbered i, whose length is derived from i
+// to lie within c's size range.
+func (c *config) key(i int) string {
+	n := c.minSize + int(uint(i)*2654435761%uint(c.maxSize-c.minSize+1))
+	s := strconv.Itoa(i) + ":"
+	if len(s) < n {
+		s += strings.Repeat("x", n-len(s))
+	}
+	return s
+}
+
+// sampleEvery is how often Get latencies are sampled, to bound the
+// memory used recording them.
+const sampleEvery = 64
+
+type result struct {
+	gets       uint64
+	violations uint64
+	latencies  []time.Duration // sampled
+}
+
+// stats accumulates results from all workers.
+type stats struct {
+	gets, violations uint64 // accessed atomically
+
+	mu        sync.Mutex
+	latencies []time.Duration
+}
+
+func (st *stats) addLatencies(d []time.Duration) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.latencies = append(st.latencies, d...)
+}
+
+// snapshot returns the results so far.
+func (st *stats) snapshot() result {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return result{
+		gets:       atomic.LoadUint64(&st.gets),
+		violations: atomic.LoadUint64(&st.violations),
+		latencies:  append([]time.Duration(nil), st.latencies...),
+	}
+}
+
+// run runs the stress test configured by c, writing reports to w.
+func run(c config, w io.Writer) result {
+	in := intern.New()
+	st := new(stats)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < c.goroutines; g++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			worker(c, in, st, rand.New(rand.NewSource(seed)), done)
+		}(int64(g))
+	}
+	if c.gcEvery > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := time.NewTicker(c.gcEvery)
+			defer t.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-t.C:
+					runtime.GC()
+				}
+			}
+		}()
+	}
+
+	start := time.Now()
+	end := time.After(c.duration)
+	var tick <-chan time.Time
+	if c.reportEvery > 0 {
+		t := time.NewTicker(c.reportEvery)
+		defer t.Stop()
+		tick = t.C
+	}
+loop:
+	for {
+		select {
+		case <-tick:
+			report(w, time.Since(start), st.snapshot(), in)
+		case <-end:
+			break loop
+		}
+	}
+	close(done)
+	wg.Wait()
+	r := st.snapshot()
+	report(w, time.Since(start), r, in)
+	return r
+}
+
+// worker Gets random keys until done is closed, checking each result.
+func worker(c config, in *intern.Interner, st *stats, rnd *rand.Rand, done <-chan struct{}) {
+	held := map[string]*intern.Value{}
+	var lat []time.Duration
+	defer func() { st.addLatencies(lat) }()
+	for n := 0; ; n++ {
+		if n%sampleEvery == 0 {
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+		k := c.key(rnd.Intn(c.keys))
+		t0 := time.Now()
+		v := in.GetByString(k)
+		if n%sampleEvery == 0 {
+			lat = append(lat, time.Since(t0))
+		}
+		atomic.AddUint64(&st.gets, 1)
+		if v.Get() != k || (held[k] != nil && held[k] != v) {
+			atomic.AddUint64(&st.violations, 1)
+		}
+		if rnd.Float64() < c.churn {
+			delete(held, k)
+		} else {
+			held[k] = v
+		}
+	}
+}
+
+// report writes a summary of r to w.
+func report(w io.Writer, elapsed time.Duration, r result, in *intern.Interner) {
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	pct := func(p float64) time.Duration {
+		if len(r.latencies) == 0 {
+			return 0
+		}
+		return r.latencies[int(p*float64(len(r.latencies)-1))]
+	}
+	st := in.Stats()
+	fmt.Fprintf(w, "%v: %d gets, %d violations, %d entries, %d finalized; latency p50=%v p90=%v p99=%v max=%v\n",
+		elapsed.Round(time.Second), r.gets, r.violations, st.Entries, st.Finalized,
+		pct(0.50), pct(0.90), pct(0.99), pct(1))
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in       string
+		min, max int
+		ok       bool
+	}{
+		{"8-64", 8, 64, true},
+		{"16", 16, 16, true},
+		{"64-8", 0, 0, false},
+		{"0-8", 0, 0, false},
+		{"x", 0, 0, false},
+	}
+	for _, tt := range tests {
+		min, max, err := parseSize(tt.in)
+		if (err == nil) != tt.ok || min != tt.min || max != tt.max {
+			t.Errorf("parseSize(%q) = %d, %d, %v", tt.in, min, max, err)
+		}
+	}
+}
+
+func TestKeySizes(t *testing.T) {
+	c := config{minSize: 8, maxSize: 12}
+	for i := 0; i < 1000; i++ {
+		if n := len(c.key(i)); n < c.minSize || n > c.maxSize {
+			t.Fatalf("len(key(%d)) = %d; want in [%d, %d]", i, n, c.minSize, c.maxSize)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := config{
+		goroutines: 4,
+		duration:   200 * time.Millisecond,
+		keys:       100,
+		churn:      0.5,
+		minSize:    4,
+		maxSize:    32,
+		gcEvery:    10 * time.Millisecond,
+	}
+	var buf bytes.Buffer
+	r := run(c, &buf)
+	if r.violations != 0 {
+		t.Errorf("%d violations", r.violations)
+	}
+	if r.gets == 0 {
+		t.Error("no gets")
+	}
+	if !strings.Contains(buf.String(), "latency p50=") {
+		t.Errorf("report = %q", buf.String())
+	}
+}
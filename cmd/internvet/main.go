@@ -0,0 +1,151 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The internvet command checks Go packages for misuse of
+// go4.org/intern, reporting the problems described by package
+// go4.org/intern/internvet.
+//
+// Usage:
+//
+//	internvet [dir ...]
+//
+// Each directory, "." by default, holds a package, which is parsed and
+// type-checked against its imports' export data, as built by go list.
+// Test files aren't checked. internvet exits with status 1 if it reports any problems.
+package main // import "go4.org/intern/cmd/internvet"
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go4.org/intern/internvet"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("internvet: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: internvet [dir ...]\n")
+		os.Exit(2)
+	}
+	flag.Parse()
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	n, err := vet(os.Stdout, dirs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if n > 0 {
+		os.Exit(1)
+	}
+}
+
+// vet checks the packages in dirs, writing the problems found to w,
+// and returns how many there were.
+func vet(w io.Writer, dirs []string) (int, error) {
+	fset := token.NewFileSet()
+	exports, err := exportData(dirs)
+	if err != nil {
+		return 0, err
+	}
+	imp := importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		file, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %q", path)
+		}
+		return os.Open(file)
+	})
+	n := 0
+	for _, dir := range dirs {
+		diags, err := vetDir(fset, imp, dir)
+		if err != nil {
+			return n, err
+		}
+		for _, d := range diags {
+			fmt.Fprintf(w, "%s: %s\n", fset.Position(d.Pos), d.Message)
+		}
+		n += len(diags)
+	}
+	return n, nil
+}
+
+// exportData returns the export data files of the packages in dirs
+// and their dependencies, by import path, building them if need be.
+func exportData(dirs []string) (map[string]string, error) {
+	args := []string{"list", "-e", "-export", "-deps", "-json=ImportPath,Export"}
+	for _, dir := range dirs {
+		// go list takes relative directories, not import paths, only
+		// if they begin with a dot.
+		if !filepath.IsAbs(dir) && !strings.HasPrefix(dir, ".") {
+			dir = "." + string(filepath.Separator) + dir
+		}
+		args = append(args, dir)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %v: %s", err, stderr.Bytes())
+	}
+	exports := make(map[string]string)
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var p struct{ ImportPath, Export string }
+		if err := dec.Decode(&p); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if p.Export != "" {
+			exports[p.ImportPath] = p.Export
+		}
+	}
+	return exports, nil
+}
+
+func vetDir(fset *token.FileSet, imp types.Importer, dir string) ([]internvet.Diagnostic, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{Importer: imp}
+	if _, err := conf.Check(bp.ImportPath, fset, files, info); err != nil {
+		return nil, err
+	}
+	diags := internvet.Check(files, info)
+	sort.Slice(diags, func(i, j int) bool { return diags[i].Pos < diags[j].Pos })
+	return diags, nil
+}
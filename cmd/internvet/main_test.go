@@ -0,0 +1,29 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVet(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := vet(&buf, []string{filepath.Join("testdata", "bad")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if n != 3 || len(lines) != 3 {
+		t.Fatalf("got %d problems:\n%s", n, buf.String())
+	}
+	for i, want := range []string{"bad.go:15:6: ", "bad.go:16:13: ", "bad.go:17:21: "} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q; want position %q", i, lines[i], want)
+		}
+	}
+}
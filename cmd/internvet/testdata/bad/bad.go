@@ -0,0 +1,19 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bad
+
+import (
+	"unsafe"
+
+	"go4.org/intern"
+)
+
+func Misuse(b []byte) {
+	v := intern.GetByString("a")
+	_ = *v
+	intern.Get([]int{1})
+	intern.GetByString(*(*string)(unsafe.Pointer(&b)))
+	intern.Get(string(b))
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// ManualCollect returns an Option that makes the Interner's behavior
+// independent of the garbage collector, for use in tests.
+//
+// No finalizers are installed. Instead, the Interner counts references:
+// each Get of a value counts one, and each Release of its Value drops
+// one. Values stay interned, however unreachable, until Collect is
+// called, which removes those whose count has dropped to zero.
+//
+// Options that depend on finalization, such as HashKeys, have no
+// effect with ManualCollect.
+func ManualCollect() Option {
+	return func(in *Interner) {
+		in.manual = true
+		if in.valSafe == nil {
+			in.valSafe = map[key]*Value{}
+		}
+	}
+}
+
+// Release drops a reference to v taken by a Get or Acquire, in an
+// Interner created with ManualCollect or Refcounted. With Refcounted,
+// v is removed once its last reference is dropped. Release panics if
+// v has been Released more times than it was referenced. In other
+// Interners, it does nothing, as it does for nil and Get(nil).
+func (v *Value) Release() {
+	in := v.orNil().in
+	if in == nil || !in.manual {
+		return
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	e := v.ext()
+	if e == nil || e.refs <= 0 {
+		panic("intern: Release of unreferenced Value")
+	}
+	e.refs--
+	if e.refs == 0 && in.refcounted {
+		in.removeLocked(v)
+		in.dropSnapshot()
+	}
+}
+
+// Collect removes from in each Value whose references have all been
+// Released, and returns the number removed. Later Gets of their values
+// return new Values.
+//
+// Collect does nothing, and returns zero, unless in was created with
+// ManualCollect.
+func (in *Interner) Collect() int {
+	if !in.manual {
+		return 0
+	}
+	defer in.flushEvents()
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	n := 0
+	for _, v := range in.valSafe {
+		if e := v.ext(); e == nil || e.refs == 0 {
+			in.removeLocked(v)
+			n++
+		}
+	}
+	if n > 0 {
+		in.dropSnapshot()
+		in.eventLocked(Event{Kind: EventEvicted, Count: n, Reason: "collect"})
+	}
+	return n
+}
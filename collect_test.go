@@ -0,0 +1,75 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "testing"
+
+func TestManualCollect(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(ManualCollect())
+	a1 := in.GetByString("a")
+	a2 := in.GetByString("a")
+	b := in.GetByString("b")
+	if a1 != a2 {
+		t.Fatal("Values differ")
+	}
+
+	b.Release()
+	a1.Release()
+	if n := in.Collect(); n != 1 {
+		t.Errorf("Collect = %d; want 1", n)
+	}
+	if got := in.Stats().Entries; got != 1 {
+		t.Errorf("Entries = %d; want 1", got)
+	}
+	if in.GetByString("a") != a1 {
+		t.Error("referenced Value was collected")
+	}
+	if in.GetByString("b") == b {
+		t.Error("released Value wasn't collected")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("over-Release didn't panic")
+		}
+	}()
+	v := in.GetByString("c")
+	v.Release()
+	v.Release()
+}
+
+func TestCollectWithoutManual(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New()
+	v := in.GetByString("x")
+	v.Release()
+	v.Release()
+	if n := in.Collect(); n != 0 {
+		t.Errorf("Collect = %d; want 0", n)
+	}
+	if in.GetByString("x") != v {
+		t.Error("Value changed")
+	}
+}
+
+func TestCollectAfterLookup(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(ManualCollect())
+	in.GetByString("x").Release()
+	if _, ok := in.Lookup("x"); !ok {
+		t.Fatal("Lookup didn't find x")
+	}
+	if n := in.Collect(); n != 1 {
+		t.Errorf("Collect = %d after Lookup; want 1", n)
+	}
+
+	parent := New(ManualCollect())
+	parent.GetByString("y").Release()
+	New(WithParent(parent)).GetByString("y")
+	if n := parent.Collect(); n != 1 {
+		t.Errorf("Collect = %d after a child's Get; want 1", n)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// Compact shrinks in's index to fit the values it currently holds,
+// but no smaller than its capacity, if set by WithCapacity.
+//
+// Go maps never shrink, and a table that has held many values keeps
+// the memory for them after they are collected. The index is compacted
+// automatically when it becomes mostly empty, so calling Compact is
+// only necessary to reclaim memory sooner, such as after a burst of
+// values is known to have been collected.
+func (in *Interner) Compact() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.tab.compact()
+	if in.hashMap != nil && len(in.hashMap) < in.hashPeak {
+		in.compactHashMapLocked()
+	}
+}
+
+// compactHashMapLocked rebuilds in.hashMap at its current size, or
+// in's capacity if that's more.
+// in.mu must be held.
+func (in *Interner) compactHashMapLocked() {
+	n := len(in.hashMap)
+	if n < in.capacity {
+		n = in.capacity
+	}
+	m := make(map[[16]byte]uintptr, n)
+	for h, addr := range in.hashMap {
+		m[h] = addr
+	}
+	in.hashMap = m
+	in.hashPeak = len(m)
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestTableShrinks(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	for _, opts := range [][]Option{nil, {HashKeys()}} {
+		in := New(opts...)
+		const n = 10000
+		for i := 0; i < n; i++ {
+			in.Get(i)
+		}
+		in.mu.Lock()
+		grown := len(in.tab.slots)
+		in.mu.Unlock()
+
+		keep := in.Get(-1)
+		for try := 0; try < 1000 && in.Stats().Entries > 1; try++ {
+			runtime.GC()
+		}
+		in.mu.Lock()
+		slots, peak := len(in.tab.slots), in.hashPeak
+		in.mu.Unlock()
+		if in.hashMap == nil && slots >= grown/shrinkRatio {
+			t.Errorf("table has %d slots after collection; grew to %d", slots, grown)
+		}
+		if in.hashMap != nil && peak >= n/shrinkRatio {
+			t.Errorf("hash map peak = %d after collection; want < %d", peak, n/shrinkRatio)
+		}
+		if in.Get(-1) != keep {
+			t.Error("value lost while shrinking")
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	in := New()
+	vals := make([]*Value, 100)
+	for i := range vals {
+		vals[i] = in.Get(i)
+	}
+	in.mu.Lock()
+	for i := 0; i < 20; i++ {
+		in.tab.remove(tableHashValue(i), uintptr(unsafe.Pointer(vals[i])))
+	}
+	tombs := in.tab.tombs
+	in.mu.Unlock()
+	if tombs == 0 {
+		t.Fatal("no tombstones to compact")
+	}
+	in.Compact()
+	in.mu.Lock()
+	tombs = in.tab.tombs
+	in.mu.Unlock()
+	if tombs != 0 {
+		t.Errorf("%d tombstones after Compact", tombs)
+	}
+	for i := 20; i < 100; i++ {
+		if in.Get(i) != vals[i] {
+			t.Fatalf("Get(%d) changed after Compact", i)
+		}
+	}
+}
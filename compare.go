@@ -0,0 +1,97 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Compare returns -1, 0, or +1 as v orders before, the same as, or
+// after other, so that Values can be kept in sorted slices and trees.
+// Compare returns 0 only if v == other.
+//
+// Values whose underlying values have the same type of an ordered kind
+// (strings, integers, floating point numbers) or bool order by those
+// values, with false before true. Values with different underlying
+// types order by the name of the type, with nil first. Other Values of
+// the same type, such as structs, and NaNs, order by Pointer, which is
+// consistent only while both are reachable.
+//
+// A nil *Value compares equal to Get(nil).
+func (v *Value) Compare(other *Value) int {
+	v, other = v.orNil(), other.orNil()
+	if v == other {
+		return 0
+	}
+	if a, ok := v.cmpVal.(string); ok {
+		if b, ok := other.cmpVal.(string); ok {
+			if c := strings.Compare(a, b); c != 0 {
+				return c
+			}
+			// Equal strings from different Interners.
+			return comparePointers(v, other)
+		}
+	}
+	if c := compareValues(reflect.ValueOf(v.cmpVal), reflect.ValueOf(other.cmpVal)); c != 0 {
+		return c
+	}
+	return comparePointers(v, other)
+}
+
+// Less reports whether v orders before other. See Compare.
+func (v *Value) Less(other *Value) bool {
+	return v.Compare(other) < 0
+}
+
+// compareValues compares a and b as described by Compare, returning 0
+// if they're equal or unordered.
+func compareValues(a, b reflect.Value) int {
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return 0
+	case !a.IsValid():
+		return -1
+	case !b.IsValid():
+		return +1
+	}
+	if ta, tb := a.Type(), b.Type(); ta != tb {
+		if c := strings.Compare(ta.String(), tb.String()); c != 0 {
+			return c
+		}
+		return strings.Compare(ta.PkgPath(), tb.PkgPath())
+	}
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, y := a.Int(), b.Int()
+		return compareOrdered(x < y, x > y)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, y := a.Uint(), b.Uint()
+		return compareOrdered(x < y, x > y)
+	case reflect.Float32, reflect.Float64:
+		x, y := a.Float(), b.Float()
+		return compareOrdered(x < y, x > y)
+	case reflect.Bool:
+		x, y := a.Bool(), b.Bool()
+		return compareOrdered(!x && y, x && !y)
+	}
+	return 0
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return +1
+	}
+	return 0
+}
+
+func comparePointers(a, b *Value) int {
+	return compareOrdered(a.Pointer() < b.Pointer(), a.Pointer() > b.Pointer())
+}
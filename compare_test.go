@@ -0,0 +1,66 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	type myString string
+	type pair struct{ a, b int }
+	// In order: by type name, then value.
+	vals := []*Value{
+		Get(nil),
+		Get(false),
+		Get(true),
+		Get(math.Inf(-1)),
+		Get(1.5),
+		Get(-5),
+		Get(0),
+		Get(7),
+		Get(int64(-1)),
+		Get(myString("a")),
+		Get(myString("b")),
+		GetByString(""),
+		GetByString("a"),
+		GetByString("ab"),
+		GetByString("b"),
+		Get(uint8(200)),
+		Get(uint8(201)),
+	}
+	for i, a := range vals {
+		for j, b := range vals {
+			want := compareOrdered(i < j, i > j)
+			if got := a.Compare(b); got != want {
+				t.Errorf("Compare(%#v, %#v) = %d; want %d", a.Get(), b.Get(), got, want)
+			}
+			if got := a.Less(b); got != (i < j) {
+				t.Errorf("Less(%#v, %#v) = %v", a.Get(), b.Get(), got)
+			}
+		}
+	}
+
+	// Unordered values still sort consistently.
+	p1, p2 := Get(pair{1, 2}), Get(pair{3, 4})
+	if c1, c2 := p1.Compare(p2), p2.Compare(p1); c1 == 0 || c1 != -c2 {
+		t.Errorf("struct Compares = %d, %d", c1, c2)
+	}
+	other := New().GetByString("a")
+	if c := other.Compare(GetByString("a")); c == 0 {
+		t.Error("Values from different Interners compare equal")
+	}
+
+	shuffled := append([]*Value(nil), vals...)
+	sort.Slice(shuffled, func(i, j int) bool { return i > j })
+	sort.Slice(shuffled, func(i, j int) bool { return shuffled[i].Less(shuffled[j]) })
+	for i := range vals {
+		if shuffled[i] != vals[i] {
+			t.Fatalf("sorted[%d] = %#v; want %#v", i, shuffled[i].Get(), vals[i].Get())
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"sort"
+	"sync/atomic"
+	"unsafe"
+)
+
+// RegisterConstants registers vals as constants of the default
+// Interner. See Interner.RegisterConstants.
+func RegisterConstants(vals ...interface{}) []*Value {
+	return std.RegisterConstants(vals...)
+}
+
+// RegisterConstants interns vals, known ahead of time to be common,
+// such as enum names or HTTP methods, and returns their Values.
+//
+// The Values are kept in an immutable perfect-hash table, which Gets
+// consult before taking any lock, so that Gets of constants cost a
+// hash and a comparison and contend on nothing. Constants are never
+// collected, and Gets return the same Values for them for the life of
+// in, even after Forget.
+//
+// Each call rebuilds the table, taking time proportional to the number
+// of constants, so constants should be registered once, at startup,
+// before they're used.
+//
+// Interners that must update per-Value or windowed state on each hit,
+// as with CountHits, ManualCollect, WindowedStats, or Shadow, don't
+// consult the table; for them, RegisterConstants only interns vals and
+// keeps their Values from being collected.
+func (in *Interner) RegisterConstants(vals ...interface{}) []*Value {
+	vs := make([]*Value, len(vals))
+	for i, x := range vals {
+		vs[i] = in.Get(x)
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	old := in.constants()
+	all := make([]*Value, 0, len(vs)+old.len())
+	seen := map[key]bool{}
+	add := func(v *Value) {
+		k := keyFor(v.cmpVal)
+		if v != nilValue && !seen[k] {
+			seen[k] = true
+			all = append(all, v)
+		}
+	}
+	if old != nil {
+		for _, v := range old.vals {
+			if v != nil {
+				add(v)
+			}
+		}
+	}
+	for _, v := range vs {
+		add(v)
+	}
+	c := newConstTable(all)
+	if old != nil {
+		c.hits = old.hits
+	}
+	atomic.StorePointer(&in.consts, unsafe.Pointer(c))
+	return vs
+}
+
+// constantsUsable reports whether in's options let Gets consult its
+// constants table. Like seqlockUsable, it excludes Interners that must
+// update state on each hit.
+func (in *Interner) constantsUsable() bool {
+	return !in.countHits && !in.manual && in.window == nil && in.shadow == nil
+}
+
+// constants returns in's constants table, or nil.
+func (in *Interner) constants() *constTable {
+	return (*constTable)(atomic.LoadPointer(&in.consts))
+}
+
+// constantGet returns the Value for the constant k, or nil, counting
+// a hit unless ctx is a composite's part.
+func (in *Interner) constantGet(k key, ctx getCtx) *Value {
+	c := in.constants()
+	if c == nil || !in.constantsUsable() {
+		return nil
+	}
+	return c.find(k, !ctx.part)
+}
+
+// A constTable is an immutable table of Values, laid out by a perfect
+// hash function in the manner of "hash, displace, and compress": each
+// key's hash selects a bucket, and each bucket a displacement chosen,
+// when the table was built, so that the keys of all buckets land in
+// distinct slots.
+type constTable struct {
+	seed hashSeed
+	disp []uint32 // displacement of each bucket
+	keys []key    // by slot
+	vals []*Value // by slot; nil for empty slots
+	hits *stripedCounter
+}
+
+// maxDisplacement is the number of displacements tried for a bucket
+// before the table is rebuilt with a new seed.
+const maxDisplacement = 1 << 16
+
+// newConstTable returns a constTable holding vals, whose underlying
+// values must be distinct.
+func newConstTable(vals []*Value) *constTable {
+	// Half-full slots keep the displacement search short.
+	size := 2
+	for size < 2*len(vals) {
+		size *= 2
+	}
+	for {
+		for try := 0; try < 8; try++ {
+			if c := buildConstTable(vals, size, newHashSeed()); c != nil {
+				c.hits = new(stripedCounter)
+				return c
+			}
+		}
+		size *= 2
+	}
+}
+
+// buildConstTable lays vals out in size slots with seed, returning nil
+// if some bucket can't be placed.
+func buildConstTable(vals []*Value, size int, seed hashSeed) *constTable {
+	c := &constTable{
+		seed: seed,
+		disp: make([]uint32, size/2),
+		keys: make([]key, size),
+		vals: make([]*Value, size),
+	}
+	hashes := make([]uint64, len(vals))
+	buckets := make([][]int, len(c.disp))
+	for i, v := range vals {
+		hashes[i] = c.hash(keyFor(v.cmpVal))
+		b := hashes[i] & uint64(len(c.disp)-1)
+		buckets[b] = append(buckets[b], i)
+	}
+	order := make([]int, len(buckets))
+	for b := range order {
+		order[b] = b
+	}
+	// Place the largest buckets first, while slots are free.
+	sort.Slice(order, func(i, j int) bool { return len(buckets[order[i]]) > len(buckets[order[j]]) })
+
+	mask := uint64(size - 1)
+	slots := make([]uint64, 0, 8)
+	for _, b := range order {
+		bucket := buckets[b]
+		if len(bucket) == 0 {
+			break
+		}
+	search:
+		for d := uint32(0); ; d++ {
+			if d == maxDisplacement {
+				return nil
+			}
+			slots = slots[:0]
+			for _, i := range bucket {
+				s := displace(hashes[i], d) & mask
+				if c.vals[s] != nil {
+					continue search
+				}
+				for _, s2 := range slots {
+					if s2 == s {
+						continue search
+					}
+				}
+				slots = append(slots, s)
+			}
+			c.disp[b] = d
+			for j, i := range bucket {
+				c.keys[slots[j]] = keyFor(vals[i].cmpVal)
+				c.vals[slots[j]] = vals[i]
+			}
+			break
+		}
+	}
+	return c
+}
+
+// displace returns the slot hash of a key with hash h in a bucket with
+// displacement d.
+func displace(h uint64, d uint32) uint64 {
+	h ^= uint64(d) * 0x9e3779b97f4a7c15
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	return h
+}
+
+// hash returns the hash of k in c.
+func (c *constTable) hash(k key) uint64 {
+	if k.isString {
+		return seededHashString(&c.seed, k.s)
+	}
+	// Not seededHashValue, which may let k escape, making every
+	// caller allocate its keys. Seed a hash of hashKey128's instead.
+	sum := hashKey128(k)
+	return seededHashString(&c.seed, bytesToString(sum[:]))
+}
+
+// find returns the Value for k in c, or nil. If count is set, it
+// counts a hit.
+func (c *constTable) find(k key, count bool) *Value {
+	h := c.hash(k)
+	s := displace(h, c.disp[h&uint64(len(c.disp)-1)]) & uint64(len(c.vals)-1)
+	if v := c.vals[s]; v != nil && c.keys[s] == k {
+		if count {
+			c.hits.add(h)
+		}
+		return v
+	}
+	return nil
+}
+
+// len returns the number of Values in c, which may be nil.
+func (c *constTable) len() int {
+	if c == nil {
+		return 0
+	}
+	n := 0
+	for _, v := range c.vals {
+		if v != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// hitCount returns the number of Gets that found constants in c, which
+// may be nil.
+func (c *constTable) hitCount() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.hits.load()
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRegisterConstants(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New()
+	methods := []interface{}{"GET", "HEAD", "POST", "PUT", "DELETE", 42, nil}
+	vs := in.RegisterConstants(methods...)
+	for i, m := range methods {
+		if got := in.Get(m); got != vs[i] {
+			t.Errorf("Get(%v) = %p; want registered %p", m, got, vs[i])
+		}
+	}
+	if vs[len(vs)-1] != nilValue {
+		t.Error("nil not registered as Get(nil)")
+	}
+	in.Forget("GET")
+	if in.GetByString("GET") != vs[0] {
+		t.Error("Value changed after Forget")
+	}
+	if got, ok := in.Lookup("GET"); got != vs[0] || !ok {
+		t.Errorf("Lookup = %p, %v; want %p, true", got, ok, vs[0])
+	}
+
+	// Registering more keeps the earlier constants.
+	more := in.RegisterConstants("PATCH", "GET")
+	if more[1] != vs[0] {
+		t.Error("re-registered constant changed")
+	}
+	if in.GetByString("POST") != vs[2] || in.GetByString("PATCH") != more[0] {
+		t.Error("constants lost after second registration")
+	}
+	if got := in.constants().len(); got != 7 {
+		t.Errorf("%d constants; want 7", got)
+	}
+
+	if n := testing.AllocsPerRun(100, func() { in.GetByString("DELETE") }); n != 0 {
+		t.Errorf("constant Get allocated %v objects; want 0", n)
+	}
+	before := in.Stats().Hits
+	in.GetByString("HEAD")
+	if got := in.Stats().Hits; got != before+1 {
+		t.Errorf("Hits = %d; want %d", got, before+1)
+	}
+}
+
+func TestConstTableLarge(t *testing.T) {
+	in := New()
+	var vals []interface{}
+	for i := 0; i < 5000; i++ {
+		vals = append(vals, fmt.Sprintf("c%d", i))
+	}
+	vs := in.RegisterConstants(vals...)
+	c := in.constants()
+	for i, x := range vals {
+		if got := c.find(keyFor(x), true); got != vs[i] {
+			t.Fatalf("find(%v) = %p; want %p", x, got, vs[i])
+		}
+	}
+	if c.find(keyFor("missing"), true) != nil {
+		t.Error("found unregistered value")
+	}
+}
+
+func TestRegisterConstantsManual(t *testing.T) {
+	in := New(ManualCollect())
+	v := in.RegisterConstants("x")[0]
+	if in.constantGet(keyFor("x"), getCtx{}) != nil {
+		t.Error("constants consulted with ManualCollect")
+	}
+	if in.Collect() != 0 || in.GetByString("x") != v {
+		t.Error("constant collected")
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "context"
+
+// ctxKey is the context key for the Interner of WithContext.
+type ctxKey struct{}
+
+// WithContext returns a copy of ctx carrying in, for retrieval with
+// FromContext.
+//
+// It lets a request-scoped Interner flow through a stack of handlers
+// without an extra parameter on every function. When the request is
+// done and its contexts are unreachable, so is the Interner, and all
+// of its Values are dropped together.
+func WithContext(ctx context.Context, in *Interner) context.Context {
+	return context.WithValue(ctx, ctxKey{}, in)
+}
+
+// FromContext returns the Interner carried by ctx, as by WithContext,
+// or the default Interner if ctx carries none.
+func FromContext(ctx context.Context) *Interner {
+	if in, ok := ctx.Value(ctxKey{}).(*Interner); ok && in != nil {
+		return in
+	}
+	return std
+}
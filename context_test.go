@@ -0,0 +1,30 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	if FromContext(ctx) != Default() {
+		t.Error("FromContext without an Interner isn't Default")
+	}
+	in := New()
+	ctx = WithContext(ctx, in)
+	child, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if FromContext(child) != in {
+		t.Error("FromContext didn't return the Interner")
+	}
+	if FromContext(child).GetByString("x") != in.GetByString("x") {
+		t.Error("Interner from context is a different table")
+	}
+	if FromContext(WithContext(ctx, nil)) != Default() {
+		t.Error("FromContext of nil Interner isn't Default")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// numEvicted is the number of recently finalized values kept
+// for WriteDebug.
+const numEvicted = 16
+
+// debugTopN is the number of hot Values listed by WriteDebug.
+const debugTopN = 20
+
+// WriteDebug writes a human-readable description of in's state to w:
+// its Stats, the size and occupancy of its table, its most-hit Values
+// (if it was created with CountHits), and the values most recently
+// removed by finalization.
+//
+// The output is intended for people, such as from a /debug/intern
+// HTTP handler, and its format may change.
+func (in *Interner) WriteDebug(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	st := in.Stats()
+	fmt.Fprintf(bw, "entries: %d\n", st.Entries)
+	fmt.Fprintf(bw, "hits: %d\nmisses: %d\n", st.Hits, st.Misses)
+	fmt.Fprintf(bw, "bytes saved: %d\n", st.BytesSaved)
+	fmt.Fprintf(bw, "finalized: %d (re-armed %d, zombies %d)\n", st.Finalized, st.FinalizerRearms, st.Zombies)
+	if in.shadow != nil {
+		fmt.Fprintf(bw, "shadow: %d gets, %d duplicates, %d bytes\n", st.ShadowGets, st.ShadowDuplicates, st.ShadowBytesSaved)
+	}
+
+	in.mu.Lock()
+	switch {
+	case in.valSafe != nil:
+		fmt.Fprintf(bw, "index: safe map, %d entries\n", len(in.valSafe))
+	case in.wk != nil:
+		fmt.Fprintf(bw, "index: weak pointers, %d entries\n", in.wk.len())
+	case in.hashMap != nil:
+		fmt.Fprintf(bw, "index: hash map, %d entries, peak %d\n", len(in.hashMap), in.hashPeak)
+	default:
+		t := &in.tab
+		var load float64
+		if len(t.slots) > 0 {
+			load = float64(t.count) / float64(len(t.slots))
+		}
+		fmt.Fprintf(bw, "index: table, %d slots, %d values, %d tombstones, load %.2f\n", len(t.slots), t.count, t.tombs, load)
+	}
+	var evicted []interface{}
+	for i := 0; i < numEvicted; i++ {
+		// Newest first.
+		if v := in.evicted[(in.nextEvict-1-i+numEvicted)%numEvicted]; v != nil {
+			evicted = append(evicted, v)
+		}
+	}
+	in.mu.Unlock()
+
+	if in.countHits {
+		fmt.Fprintf(bw, "\ntop values:\n")
+		for _, hv := range in.TopN(debugTopN) {
+			fmt.Fprintf(bw, "  %8d  %#v\n", hv.Hits, hv.Value.Get())
+		}
+	}
+	fmt.Fprintf(bw, "\nrecently finalized:\n")
+	for _, v := range evicted {
+		fmt.Fprintf(bw, "  %#v\n", v)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWriteDebug(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(CountHits())
+	hot := in.GetByString("hot")
+	for i := 0; i < 3; i++ {
+		in.GetByString("hot")
+	}
+	func() { in.GetByString("gone") }()
+	if safeMap() == nil {
+		for try := 0; try < 1000 && in.Stats().Finalized == 0; try++ {
+			runtime.GC()
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := in.WriteDebug(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	want := []string{"entries: ", "hits: 3\n", "index: ", "top values:", `3  "hot"`}
+	if safeMap() == nil {
+		want = append(want, "recently finalized:\n  \"gone\"\n")
+	}
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("output missing %q:\n%s", w, out)
+		}
+	}
+	runtime.KeepAlive(hot)
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bytes"
+	"io"
+	"sort"
+)
+
+// WriteDictionary writes the strings interned in in to w, sorted, in
+// the format of WriteTable, for loading by LoadDictionary. Values that
+// aren't strings are skipped.
+//
+// A dictionary written from a warmed-up Interner captures a service's
+// hot vocabulary, to be compiled into its binary.
+func (in *Interner) WriteDictionary(w io.Writer) error {
+	var strs []string
+	in.mu.Lock()
+	in.forEachLocked(func(v *Value) {
+		if s, ok := v.cmpVal.(string); ok {
+			strs = append(strs, s)
+		}
+	})
+	in.mu.Unlock()
+	sort.Strings(strs)
+	return writeTable(w, strs)
+}
+
+// LoadDictionary returns a new frozen Interner, configured by opts,
+// holding the strings in dict, a dictionary written by WriteDictionary
+// or a symbol table written by WriteTable. It's meant for dictionaries
+// compiled into the binary, loaded at init to be the base layer of the
+// Interners a service uses, so that it starts with its hot vocabulary
+// already canonicalized:
+//
+//	//go:embed vocab.dict
+//	var vocab []byte
+//
+//	var base = intern.MustLoadDictionary(vocab)
+//	var in = intern.New(intern.WithParent(base))
+//
+// The strings are registered as constants, as by RegisterConstants, so
+// their Values are never collected and Gets find them without locking.
+// Being frozen, the Interner gains no other values: Gets of values not
+// in dict return new Values, which aren't interned, as from a
+// ReadOnlyInterner.
+func LoadDictionary(dict []byte, opts ...Option) (*Interner, error) {
+	var vals []interface{}
+	err := readTable(bytes.NewReader(dict), func(_ uint64, s string) error {
+		vals = append(vals, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	in := New(opts...)
+	in.RegisterConstants(vals...)
+	in.frozen = true
+	return in, nil
+}
+
+// MustLoadDictionary is like LoadDictionary, but panics if dict can't
+// be loaded. It simplifies initializing global variables.
+func MustLoadDictionary(dict []byte, opts ...Option) *Interner {
+	in, err := LoadDictionary(dict, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return in
+}
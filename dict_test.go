@@ -0,0 +1,77 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+)
+
+func TestDictionary(t *testing.T) {
+	warm := New()
+	vs := []*Value{warm.GetByString("GET"), warm.GetByString("POST"), warm.GetByString("Content-Type"), warm.Get(1)}
+	var buf bytes.Buffer
+	if err := warm.WriteDictionary(&buf); err != nil {
+		t.Fatal(err)
+	}
+	runtime.KeepAlive(vs)
+
+	base, err := LoadDictionary(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base.constants().len(); got != 3 {
+		t.Errorf("%d strings loaded; want 3", got)
+	}
+	get := base.GetByString("POST")
+	if get.Get() != "POST" || base.GetByString("POST") != get {
+		t.Error("loaded string not canonical")
+	}
+	if base.GetByString("PUT") == base.GetByString("PUT") {
+		t.Error("frozen Interner interned a new value")
+	}
+	if got := base.Stats().Entries; got != 3 {
+		t.Errorf("Entries = %d; want 3", got)
+	}
+
+	in := New(WithParent(base))
+	if in.GetByString("POST") != get {
+		t.Error("child didn't find base's Value")
+	}
+	put := in.GetByString("PUT")
+	if in.GetByString("PUT") != put {
+		t.Error("child didn't intern new value")
+	}
+	if _, ok := base.Lookup("PUT"); ok {
+		t.Error("child's value added to base")
+	}
+	runtime.KeepAlive(put)
+}
+
+func TestLoadSymbolTable(t *testing.T) {
+	defer swapSymbols()()
+	SymbolOfString("alpha")
+	var buf bytes.Buffer
+	if err := WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	base := MustLoadDictionary(buf.Bytes())
+	if v, ok := base.Lookup("alpha"); !ok || v.Get() != "alpha" {
+		t.Errorf("Lookup(alpha) = %v, %v", v, ok)
+	}
+}
+
+func TestLoadDictionaryErrors(t *testing.T) {
+	if _, err := LoadDictionary([]byte("junk")); err == nil {
+		t.Error("LoadDictionary of junk succeeded")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustLoadDictionary of junk didn't panic")
+		}
+	}()
+	MustLoadDictionary(nil)
+}
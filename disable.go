@@ -0,0 +1,43 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "sync/atomic"
+
+// Disabled returns an Option that creates the Interner disabled.
+// See SetDisabled.
+func Disabled() Option {
+	return func(in *Interner) { in.disabled = 1 }
+}
+
+// SetDisabled disables or re-enables the default Interner.
+// See Interner.SetDisabled.
+func SetDisabled(disabled bool) {
+	std.SetDisabled(disabled)
+}
+
+// SetDisabled disables or re-enables interning by in, so the memory
+// and CPU cost of interning can be compared with not interning, such
+// as by flipping a flag in production.
+//
+// While in is disabled, each Get returns a new Value, which isn't added
+// to the table and so isn't canonical: Gets of equal values return
+// different Values. Options that transform values still apply. Values
+// already interned remain in the table, and are returned again once
+// in is re-enabled.
+//
+// Code that compares Values, or uses them as map keys, expecting equal
+// values to have equal Values won't work while in is disabled.
+func (in *Interner) SetDisabled(disabled bool) {
+	var v uint32
+	if disabled {
+		v = 1
+	}
+	atomic.StoreUint32(&in.disabled, v)
+}
+
+func (in *Interner) isDisabled() bool {
+	return atomic.LoadUint32(&in.disabled) != 0
+}
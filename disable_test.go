@@ -0,0 +1,44 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestSetDisabled(t *testing.T) {
+	for _, opt := range []Option{CaseInsensitive(), HashKeys(), Shadow()} {
+		in := New(opt)
+		v := in.GetByString("foo")
+		in.SetDisabled(true)
+		d1, d2 := in.GetByString("foo"), in.GetByString("foo")
+		if d1 == v || d1 == d2 {
+			t.Errorf("disabled Gets returned shared Values")
+		}
+		if d1.Get() != "foo" {
+			t.Errorf("d1.Get() = %v", d1.Get())
+		}
+		if n := in.Stats().Entries; n > 1 {
+			t.Errorf("Entries = %d; want at most 1", n)
+		}
+		in.SetDisabled(false)
+		if shadowed := in.shadow != nil; !shadowed && in.GetByString("foo") != v {
+			t.Error("re-enabled Get didn't return the interned Value")
+		}
+		runtime.KeepAlive(v)
+	}
+}
+
+func TestDisabledOption(t *testing.T) {
+	in := New(Disabled(), CaseInsensitive())
+	a, b := in.GetByString("Foo"), in.GetByString("foo")
+	if a == b {
+		t.Error("disabled Interner returned shared Values")
+	}
+	if a.Get() != "foo" {
+		t.Errorf("a.Get() = %q; want folded %q", a.Get(), "foo")
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Value implements the encoding interfaces only in the marshaling
+// direction. Decoders (encoding/json, encoding/gob, and users of
+// encoding.BinaryUnmarshaler and TextUnmarshaler alike) decode into a
+// Value they allocate themselves, or one already in place, and neither
+// can be the canonical pointer for the decoded contents. A Value that
+// isn't canonical would silently break pointer comparisons, so types
+// that hold a *Value should decode the underlying value and call Get.
+
+// MarshalJSON implements json.Marshaler, encoding v as its underlying
+// value. A nil *Value encodes as null.
+func (v *Value) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(v.cmpVal)
+}
+
+// MarshalText implements encoding.TextMarshaler for values whose
+// underlying value is a string or itself implements
+// encoding.TextMarshaler. It returns an error for other values.
+//
+// Among other things, this lets a map keyed by *Value be encoded
+// as a JSON object.
+func (v *Value) MarshalText() ([]byte, error) {
+	switch x := v.Get().(type) {
+	case string:
+		return []byte(x), nil
+	case encoding.TextMarshaler:
+		return x.MarshalText()
+	}
+	return nil, fmt.Errorf("intern: can't marshal %T as text", v.Get())
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler for values whose
+// underlying value is a string or itself implements
+// encoding.BinaryMarshaler. It returns an error for other values.
+func (v *Value) MarshalBinary() ([]byte, error) {
+	switch x := v.Get().(type) {
+	case string:
+		return []byte(x), nil
+	case encoding.BinaryMarshaler:
+		return x.MarshalBinary()
+	}
+	return nil, fmt.Errorf("intern: can't marshal %T as binary", v.Get())
+}
+
+// GobEncode implements gob.GobEncoder, encoding v as a gob stream of
+// its underlying value, which must be encodable by encoding/gob. The
+// receiver's type should implement gob.GobDecoder by decoding that
+// stream and calling Get; see above.
+func (v *Value) GobEncode() ([]byte, error) {
+	if v == nil {
+		return nil, errors.New("intern: can't gob-encode a nil *Value")
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v.cmpVal); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
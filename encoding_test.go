@@ -0,0 +1,121 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	type point struct{ X, Y int }
+	type wrapper struct {
+		Name  *Value
+		Point *Value
+		Nil   *Value
+	}
+	got, err := json.Marshal(wrapper{
+		Name:  GetByString("json-name"),
+		Point: Get(point{1, 2}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"Name":"json-name","Point":{"X":1,"Y":2},"Nil":null}`
+	if string(got) != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+}
+
+type textPoint struct{ X, Y int }
+
+func (p textPoint) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d,%d", p.X, p.Y)), nil
+}
+
+func TestMarshalText(t *testing.T) {
+	got, err := json.Marshal(map[*Value]int{
+		GetByString("text-a"): 1,
+		Get(textPoint{3, 4}):  2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = `{"3,4":2,"text-a":1}`
+	if string(got) != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+	if _, err := Get(1.5).MarshalText(); err == nil {
+		t.Error("MarshalText of float64 succeeded")
+	}
+}
+
+type binaryPoint struct{ X, Y byte }
+
+func (p binaryPoint) MarshalBinary() ([]byte, error) {
+	return []byte{p.X, p.Y}, nil
+}
+
+func TestMarshalBinary(t *testing.T) {
+	for _, tt := range []struct {
+		v    *Value
+		want string
+	}{
+		{GetByString("binary-a"), "binary-a"},
+		{Get(binaryPoint{1, 2}), "\x01\x02"},
+	} {
+		got, err := tt.v.MarshalBinary()
+		if err != nil || string(got) != tt.want {
+			t.Errorf("MarshalBinary of %v = %q, %v; want %q", tt.v.Get(), got, err, tt.want)
+		}
+	}
+	if _, err := Get(1.5).MarshalBinary(); err == nil {
+		t.Error("MarshalBinary of float64 succeeded")
+	}
+}
+
+// gobName holds a *Value, decoding it as a canonical Value.
+type gobName struct{ V *Value }
+
+func (n *gobName) GobDecode(b []byte) error {
+	var s string
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&s); err != nil {
+		return err
+	}
+	n.V = GetByString(s)
+	return nil
+}
+
+func TestGobEncode(t *testing.T) {
+	type point struct{ X, Y int }
+	b, err := Get(point{1, 2}).GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p point
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&p); err != nil || p != (point{1, 2}) {
+		t.Errorf("decoded %+v, %v; want {1 2}", p, err)
+	}
+
+	var buf bytes.Buffer
+	v := GetByString("gob-name")
+	if err := gob.NewEncoder(&buf).Encode(struct{ Name *Value }{v}); err != nil {
+		t.Fatal(err)
+	}
+	var got struct{ Name gobName }
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name.V != v {
+		t.Errorf("decoded %v; want the canonical Value", got.Name.V)
+	}
+
+	if _, err := (*Value)(nil).GobEncode(); err == nil {
+		t.Error("GobEncode of nil succeeded")
+	}
+}
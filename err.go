@@ -0,0 +1,31 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// errs interns the messages of errors returned by Err. It's separate
+// from the default Interner so that options set on that, such as
+// disabling it, don't affect Err.
+var errs = New()
+
+// Err returns an error whose Error method returns msg.
+//
+// Unlike errors.New, Err returns equal errors for equal messages, as
+// long as an earlier one is still reachable, so errors.Is and == can
+// compare them, and calling Err with a message already in use doesn't
+// allocate. It suits hot paths that would otherwise create many
+// identical errors.
+func Err(msg string) error {
+	return internedError{errs.GetByString(msg)}
+}
+
+// internedError is an error returned by Err. Being a struct of one
+// pointer, it's stored in an interface without allocating.
+type internedError struct {
+	v *Value
+}
+
+func (e internedError) Error() string {
+	return e.v.Get().(string)
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErr(t *testing.T) {
+	err := Err("disk full")
+	if err.Error() != "disk full" {
+		t.Errorf("Error = %q", err.Error())
+	}
+	if err != Err("disk full") {
+		t.Error("errors with equal messages differ")
+	}
+	if err == Err("disk empty") {
+		t.Error("errors with different messages are equal")
+	}
+	if !errors.Is(fmt.Errorf("write: %w", err), Err("disk full")) {
+		t.Error("errors.Is doesn't match wrapped Err")
+	}
+	if errors.Is(err, errors.New("disk full")) {
+		t.Error("errors.Is matches an errors.New error")
+	}
+
+	n := testing.AllocsPerRun(100, func() {
+		if Err("disk full") != err {
+			t.Fatal("error changed")
+		}
+	})
+	if n > 0 {
+		t.Errorf("Err allocs = %v; want 0", n)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+// An EventKind is a kind of notable event in an Interner's life,
+// reported to the function given to OnEvent.
+type EventKind int
+
+const (
+	// EventEntries is when the number of entries in the Interner
+	// first reaches one of the thresholds given to OnEvent.
+	EventEntries EventKind = iota + 1
+	// EventEvicted is when many Values are removed at once: by
+	// memory pressure, the end of a Generation, or Collect.
+	EventEvicted
+	// EventSafeMode is when the Interner was created in
+	// safe-but-leaky mode, as set by GO4_INTERN_SAFE_BUT_LEAKY, in
+	// which no Value is ever collected. It's reported by the
+	// Interner's first Get.
+	EventSafeMode
+	// EventRejected is when a value isn't interned: because it's
+	// larger than WithMaxSize allows, it has pointers refused by
+	// RejectPointers, or its tenant is over quota.
+	EventRejected
+)
+
+var eventKindNames = [...]string{
+	EventEntries:  "entries",
+	EventEvicted:  "evicted",
+	EventSafeMode: "safemode",
+	EventRejected: "rejected",
+}
+
+func (k EventKind) String() string {
+	if k > 0 && int(k) < len(eventKindNames) {
+		return eventKindNames[k]
+	}
+	return fmt.Sprintf("EventKind(%d)", int(k))
+}
+
+// An Event is a notable event in an Interner's life.
+type Event struct {
+	Kind EventKind
+
+	// Entries is the number of entries in the Interner: for
+	// EventEntries, the threshold reached.
+	Entries int
+
+	// Count is, for EventEvicted, the number of Values removed.
+	Count int
+
+	// Reason says, for EventEvicted and EventRejected, why: one of
+	// "pressure", "generation", or "collect" for EventEvicted, and
+	// one of "size", "pointers", or "quota" for EventRejected.
+	Reason string
+
+	// Type and Size are, for EventRejected, the type of the value
+	// refused and its size, as reported by Sizes. The value itself
+	// isn't kept, as it may be large or sensitive.
+	Type string
+	Size int
+}
+
+// OnEvent returns an Option that calls f with each notable event in
+// the Interner's life, such as to log it; package internslog logs them
+// with log/slog. Events of kind EventEntries are reported as the
+// number of entries first reaches each of entryThresholds.
+//
+// f is called on the goroutine that caused the event, such as by a
+// Get, or on the janitor's, but never while the Interner is locked, so
+// it may use the Interner. It may be called concurrently.
+func OnEvent(f func(Event), entryThresholds ...int) Option {
+	return func(in *Interner) {
+		in.onEvent = f
+		in.thresholds = append([]int(nil), entryThresholds...)
+		sort.Ints(in.thresholds)
+	}
+}
+
+// WithMaxSize returns an Option that doesn't intern values larger than
+// n bytes, as measured by Sizes: Get returns a new Value for each,
+// which isn't canonical. It guards against interning something
+// unexpectedly huge, such as a whole request body, and keeping it for
+// as long as it's in use elsewhere.
+func WithMaxSize(n int) Option {
+	return func(in *Interner) { in.maxSize = n }
+}
+
+// tooBig reports whether k is too big to intern, reporting the event
+// if so.
+func (in *Interner) tooBig(k key) bool {
+	if in.maxSize <= 0 {
+		return false
+	}
+	size := int(keySize(k))
+	if size <= in.maxSize {
+		return false
+	}
+	if in.onEvent != nil {
+		in.onEvent(Event{Kind: EventRejected, Reason: "size", Type: keyType(k), Size: size})
+	}
+	return true
+}
+
+// keyType returns the name of the type of k's value.
+func keyType(k key) string {
+	if k.isString {
+		return "string"
+	}
+	return fmt.Sprintf("%T", k.cmpVal)
+}
+
+// eventLocked queues e, to be reported by flushEvents once in.mu is
+// released. in.mu must be held.
+func (in *Interner) eventLocked(e Event) {
+	if in.onEvent == nil {
+		return
+	}
+	in.events = append(in.events, e)
+	atomic.StoreUint32(&in.eventsPending, 1)
+}
+
+// insertedLocked queues any EventEntries due after an insertion.
+// in.mu must be held.
+func (in *Interner) insertedLocked() {
+	if in.nextThreshold >= len(in.thresholds) {
+		return
+	}
+	n := in.entriesLocked()
+	for in.nextThreshold < len(in.thresholds) && n >= in.thresholds[in.nextThreshold] {
+		in.eventLocked(Event{Kind: EventEntries, Entries: in.thresholds[in.nextThreshold]})
+		in.nextThreshold++
+	}
+}
+
+// flushEvents reports the queued events. in.mu must not be held.
+func (in *Interner) flushEvents() {
+	if atomic.LoadUint32(&in.eventsPending) == 0 {
+		return
+	}
+	in.mu.Lock()
+	events := in.events
+	in.events = nil
+	atomic.StoreUint32(&in.eventsPending, 0)
+	in.mu.Unlock()
+	for _, e := range events {
+		in.onEvent(e)
+	}
+}
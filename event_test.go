@@ -0,0 +1,130 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// eventLog collects Events, other than EventSafeMode, so that tests
+// run the same in safe-but-leaky mode.
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (l *eventLog) add(e Event) {
+	if e.Kind == EventSafeMode {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+}
+
+func (l *eventLog) take() []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := l.events
+	l.events = nil
+	return events
+}
+
+func TestEvents(t *testing.T) {
+	skipIfRefcounted(t)
+	var log eventLog
+	in := New(OnEvent(log.add, 5, 2), WithMaxSize(16), RejectPointers(), ManualCollect())
+	var vs []*Value
+	for i := 0; i < 6; i++ {
+		vs = append(vs, in.Get(i))
+	}
+	want := []Event{
+		{Kind: EventEntries, Entries: 2},
+		{Kind: EventEntries, Entries: 5},
+	}
+	if got := log.take(); !eventsEqual(got, want) {
+		t.Errorf("entries events = %+v; want %+v", got, want)
+	}
+
+	if v := in.GetByString(strings.Repeat("x", 17)); v == in.GetByString(strings.Repeat("x", 17)) {
+		t.Error("oversized value was interned")
+	}
+	in.TryGet(&vs)
+	want = []Event{
+		{Kind: EventRejected, Reason: "size", Type: "string", Size: 17},
+		{Kind: EventRejected, Reason: "size", Type: "string", Size: 17},
+		{Kind: EventRejected, Reason: "pointers", Type: "*[]*intern.Value", Size: 8},
+	}
+	if got := log.take(); !eventsEqual(got, want) {
+		t.Errorf("rejected events = %+v; want %+v", got, want)
+	}
+
+	for _, v := range vs[:4] {
+		v.Release()
+	}
+	in.Collect()
+	want = []Event{{Kind: EventEvicted, Count: 4, Reason: "collect"}}
+	if got := log.take(); !eventsEqual(got, want) {
+		t.Errorf("evicted events = %+v; want %+v", got, want)
+	}
+	runtime.KeepAlive(vs)
+
+	if s := EventKind(99).String(); s != "EventKind(99)" {
+		t.Errorf("unknown kind String = %q", s)
+	}
+}
+
+func eventsEqual(a, b []Event) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEventsReentrant(t *testing.T) {
+	// The event function may use the Interner.
+	var in *Interner
+	n := 0
+	in = New(OnEvent(func(e Event) { n = in.Stats().Entries }, 1))
+	v := in.GetByString("a")
+	if n != 1 {
+		t.Errorf("Entries seen by event function = %d; want 1", n)
+	}
+	runtime.KeepAlive(v)
+}
+
+func TestEventSafeMode(t *testing.T) {
+	old, ok := os.LookupEnv("GO4_INTERN_SAFE_BUT_LEAKY")
+	os.Setenv("GO4_INTERN_SAFE_BUT_LEAKY", "1")
+	var in *Interner
+	var kinds []EventKind
+	in = New(OnEvent(func(e Event) {
+		// The Interner is usable by the time it's reported.
+		in.Stats()
+		kinds = append(kinds, e.Kind)
+	}))
+	if ok {
+		os.Setenv("GO4_INTERN_SAFE_BUT_LEAKY", old)
+	} else {
+		os.Unsetenv("GO4_INTERN_SAFE_BUT_LEAKY")
+	}
+	if len(kinds) != 0 {
+		t.Errorf("events reported by New: %v", kinds)
+	}
+	in.GetByString("a")
+	in.GetByString("b")
+	if len(kinds) != 1 || kinds[0] != EventSafeMode {
+		t.Errorf("events = %v; want [safemode]", kinds)
+	}
+}
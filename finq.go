@@ -0,0 +1,67 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// maxFinQueue is the number of queued finalized Values at which a
+// finalizer processes the queue itself, rather than leaving it for
+// the next Get.
+const maxFinQueue = 1024
+
+// DeferFinalization returns an Option that defers the work of
+// finalizers.
+//
+// By default, each Value's finalizer locks the Interner to remove the
+// Value. When the garbage collector finds many unreachable Values at
+// once, their finalizers then contend with Gets for the lock, and Gets
+// see latency spikes. With DeferFinalization, finalizers instead push
+// their Values onto a lock-free queue, which is processed by the next
+// Get or Stats, which lock the Interner anyway, or by the janitor
+// (see WithJanitor). So that the queue doesn't grow without bound in
+// an idle Interner, a finalizer that finds it long processes it.
+//
+// Deferred Values remain in memory, and are counted in Stats, until
+// the queue is processed.
+func DeferFinalization() Option {
+	return func(in *Interner) { in.deferFinalize = true }
+}
+
+// A finNode is an element of Interner.finq.
+type finNode struct {
+	v    *Value
+	next *finNode
+}
+
+// queueFinalized pushes v, whose finalizer has run, onto in.finq.
+// It reports whether the queue is now long enough that the caller
+// should process it.
+func (in *Interner) queueFinalized(v *Value) bool {
+	n := &finNode{v: v}
+	for {
+		old := atomic.LoadPointer(&in.finq)
+		n.next = (*finNode)(old)
+		if atomic.CompareAndSwapPointer(&in.finq, old, unsafe.Pointer(n)) {
+			break
+		}
+	}
+	return atomic.AddInt32(&in.finqLen, 1) >= maxFinQueue
+}
+
+// drainFinalizedLocked finalizes the Values in in.finq.
+// in.mu must be held.
+func (in *Interner) drainFinalizedLocked() {
+	if atomic.LoadPointer(&in.finq) == nil {
+		return
+	}
+	n := (*finNode)(atomic.SwapPointer(&in.finq, nil))
+	for ; n != nil; n = n.next {
+		atomic.AddInt32(&in.finqLen, -1)
+		in.finalizeLocked(n.v)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeferFinalization(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("Values aren't finalized in safe-but-leaky mode")
+	}
+	in := New(DeferFinalization())
+	keep := in.GetByString("keep")
+	func() {
+		for i := 0; i < 100; i++ {
+			in.GetByString(strconv.Itoa(i))
+		}
+	}()
+	// Wait for the finalizers to queue the Values.
+	deadline := time.Now().Add(10 * time.Second)
+	for atomic.LoadInt32(&in.finqLen) < 100 {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d Values queued", atomic.LoadInt32(&in.finqLen))
+		}
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if n := tableLen(in); n != 101 {
+		t.Errorf("table has %d Values before draining; want 101", n)
+	}
+
+	// Resurrect a queued Value; it must survive the drain.
+	v := in.GetByString("7")
+	st := in.Stats()
+	if st.Entries != 2 || st.Finalized != 99 || st.FinalizerRearms != 1 {
+		t.Errorf("Stats after drain = %+v; want 2 entries, 99 finalized, 1 re-arm", st)
+	}
+	if in.GetByString("7") != v || in.GetByString("keep") != keep {
+		t.Error("live Values lost")
+	}
+	runtime.KeepAlive(keep)
+	runtime.KeepAlive(v)
+}
+
+func TestDeferFinalizationBound(t *testing.T) {
+	if safeMap() != nil {
+		t.Skip("Values aren't finalized in safe-but-leaky mode")
+	}
+	in := New(DeferFinalization())
+	func() {
+		for i := 0; i < 3*maxFinQueue; i++ {
+			in.Get(i)
+		}
+	}()
+	for i := 0; i < 100 && atomic.LoadInt32(&in.finqLen)+int32(tableLen(in)) > maxFinQueue; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&in.finqLen); n >= maxFinQueue {
+		t.Errorf("queue grew to %d without a Get", n)
+	}
+}
+
+// tableLen returns the number of Values in in's table, without
+// draining its queue of finalized Values as Stats does.
+func tableLen(in *Interner) int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return in.tab.count
+}
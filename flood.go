@@ -0,0 +1,58 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "unsafe"
+
+// Interned values are often strings chosen by an attacker, such as
+// header names. If an attacker could choose many values whose table
+// hashes collide, each insertion would probe ever further, as in a
+// list, making Gets take time quadratic in the number of values.
+//
+// The table hash is seeded randomly in each process (see tableSeed),
+// so which values collide can't be known in advance. If collisions
+// pile up regardless, as when the seed has somehow leaked, a Value
+// whose insertion would probe too far goes in a map, in.flooded,
+// instead. The runtime seeds each map's hash separately, so the same
+// values don't collide there, and once the table's probe sequences
+// reach floodProbe slots, colliding values stop lengthening them.
+// Values are never refused, so Gets stay canonical.
+
+// floodProbe is the probe distance of an insertion above which the
+// table is considered flooded with colliding values.
+// With a good hash and the table at most three-quarters full, runs of
+// occupied slots grow only logarithmically with the table, to a few
+// hundred in the largest tables, so longer distances are vanishingly
+// rare. It's a variable for tests.
+var floodProbe = 1024
+
+// probeLen returns the distance from the slot selected by h to the
+// slot an insertion with that hash would use.
+func (t *table) probeLen(h uint64) int {
+	if len(t.slots) == 0 {
+		return 0
+	}
+	mask := uint64(len(t.slots) - 1)
+	n := 0
+	for i := h & mask; t.slots[i].addr != empty && t.slots[i].addr != tombstone; i = (i + 1) & mask {
+		n++
+	}
+	return n
+}
+
+// tableInsertLocked adds v, whose key has table hash h, to in's table,
+// or to in.flooded if the table is flooded with values colliding with
+// it. v's finalizer must already be set. in.mu must be held.
+func (in *Interner) tableInsertLocked(h uint64, v *Value) {
+	addr := uintptr(unsafe.Pointer(v))
+	if in.tab.probeLen(h) <= floodProbe {
+		in.tab.insert(h, addr)
+		return
+	}
+	if in.flooded == nil {
+		in.flooded = make(map[key]uintptr)
+	}
+	in.flooded[keyFor(v.cmpVal)] = addr
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestFlood(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	defer func(n int) { floodProbe = n }(floodProbe)
+	floodProbe = 0 // any collision is a flood
+
+	for _, opts := range [][]Option{nil, {RCU()}, {Seqlock()}} {
+		in := New(opts...)
+		vs := make(map[string]*Value)
+		for i := 0; i < 1000; i++ {
+			s := "v" + strconv.Itoa(i)
+			vs[s] = in.GetByString(s)
+		}
+		st := in.TableStats()
+		if st.Flooded == 0 {
+			t.Fatalf("no values flooded: %+v", st)
+		}
+		if n := in.Stats().Entries; n != 1000 {
+			t.Errorf("Entries = %d; want 1000", n)
+		}
+		for s, v := range vs {
+			if got := in.GetByString(s); got != v {
+				t.Fatalf("Get(%q) = %p; want %p", s, got, v)
+			}
+			if !in.Forget(s) {
+				t.Fatalf("Forget(%q) didn't find it", s)
+			}
+		}
+		if st := in.TableStats(); st.Entries != 0 || st.Flooded != 0 {
+			t.Errorf("Entries, Flooded = %d, %d after forgetting all; want 0, 0", st.Entries, st.Flooded)
+		}
+		runtime.KeepAlive(vs)
+	}
+}
+
+func TestFloodByteArrays(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	defer func(n int) { floodProbe = n }(floodProbe)
+	floodProbe = 0
+
+	bi := NewBytes16Interner()
+	var vs [][16]byte
+	var held []*Value
+	for i := 0; i < 1000; i++ {
+		b := [16]byte{byte(i), byte(i >> 8)}
+		vs = append(vs, b)
+		held = append(held, bi.Get(b))
+	}
+	if st := bi.in.TableStats(); st.Flooded == 0 {
+		t.Fatalf("no values flooded: %+v", st)
+	}
+	if n := bi.Len(); n != 1000 {
+		t.Errorf("Len = %d; want 1000", n)
+	}
+	for i, b := range vs {
+		if got := bi.Get(b); got != held[i] {
+			t.Fatalf("Get(%x) = %p; want %p", b, got, held[i])
+		}
+	}
+	for _, v := range held {
+		bi.in.mu.Lock()
+		bi.in.removeLocked(v)
+		bi.in.mu.Unlock()
+	}
+	if st := bi.in.TableStats(); st.Entries != 0 || st.Flooded != 0 {
+		t.Errorf("Entries, Flooded = %d, %d after removing all; want 0, 0", st.Entries, st.Flooded)
+	}
+}
+
+func TestNoFlood(t *testing.T) {
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	in := New()
+	var vs []*Value
+	for i := 0; i < 10000; i++ {
+		vs = append(vs, in.Get(i))
+	}
+	if st := in.TableStats(); st.Flooded != 0 {
+		t.Errorf("table of ordinary values flooded: %+v", st)
+	}
+	runtime.KeepAlive(vs)
+}
+
+func TestSeededHash(t *testing.T) {
+	a, b := newHashSeed(), newHashSeed()
+	if seededHashString(&a, "x") == seededHashString(&b, "x") {
+		t.Error("string hashes with different seeds are equal")
+	}
+	if seededHashValue(&a, 1) == seededHashValue(&b, 1) {
+		t.Error("value hashes with different seeds are equal")
+	}
+	if seededHashString(&a, "x") != seededHashString(&a, "x") {
+		t.Error("string hash isn't deterministic")
+	}
+}
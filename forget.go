@@ -0,0 +1,85 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// Forget removes cmpVal from the default Interner.
+// See Interner.Forget.
+func Forget(cmpVal interface{}) bool {
+	return std.Forget(cmpVal)
+}
+
+// Forget removes cmpVal from in now, rather than waiting for its Value
+// to be garbage collected, and reports whether it was interned. It's
+// for dropping values known to be no longer needed, such as those of a
+// tenant or stream that is being torn down.
+//
+// The forgotten Value remains valid, but is no longer canonical: a
+// later Get of cmpVal returns a new Value, which doesn't equal it.
+// The Value for nil, which is never collected, can't be forgotten.
+func (in *Interner) Forget(cmpVal interface{}) bool {
+	k := in.keyOf(cmpVal)
+	if !k.isString && k.cmpVal == nil {
+		return false
+	}
+	kh := in.hashKey(k)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	var v *Value
+	if in.valSafe != nil {
+		v = in.valSafe[k]
+	} else if in.wk != nil {
+		v = in.wk.find(k)
+	} else if addr := in.findLocked(k, kh); addr != 0 {
+		v = valueAt(addr)
+	}
+	if v == nil {
+		return false
+	}
+	in.removeLocked(v)
+	in.dropSnapshot()
+	return true
+}
+
+// reset empties in's index, so that later Gets return new Values.
+// Values already returned remain valid but are no longer canonical.
+func (in *Interner) reset() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.dropSnapshot()
+	in.forEachLocked(func(v *Value) {
+		if e := v.ext(); e != nil {
+			e.tenant = nil
+		}
+		in.markRemovedLocked(v)
+	})
+	for _, t := range in.tenants {
+		t.count = 0
+	}
+	for addr, s := range in.siteOf {
+		s.Live--
+		delete(in.siteOf, addr)
+	}
+	if in.lifetimes != nil {
+		in.lifetimes.reset()
+	}
+	if in.sizes != nil {
+		in.sizes = newSizeHistogram()
+	}
+	if in.leaks != nil {
+		in.leaks.reset()
+	}
+	in.tab.reset()
+	in.flooded = nil
+	if in.valSafe != nil {
+		in.valSafe = make(map[key]*Value, in.capacity)
+	}
+	if in.wk != nil {
+		in.wk = newWeakIndex(in)
+	}
+	if in.hashMap != nil {
+		in.hashMap = make(map[[16]byte]uintptr, in.capacity)
+		in.hashPeak = 0
+	}
+}
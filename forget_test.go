@@ -0,0 +1,57 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestForget(t *testing.T) {
+	for _, opt := range []Option{HashKeys(), CaseInsensitive(), ManualCollect()} {
+		in := New(opt)
+		foo := in.GetByString("foo")
+		bar := in.Get(1)
+		if !in.Forget("foo") {
+			t.Error("Forget(foo) = false")
+		}
+		if in.Forget("foo") {
+			t.Error("second Forget(foo) = true")
+		}
+		if in.Forget("baz") {
+			t.Error("Forget(baz) = true")
+		}
+		if n := in.Stats().Entries; n != 1 {
+			t.Errorf("Entries = %d; want 1", n)
+		}
+		foo2 := in.GetByString("foo")
+		if foo2 == foo || foo2.Get() != "foo" {
+			t.Error("Get after Forget returned the old Value")
+		}
+		if in.Get(1) != bar {
+			t.Error("Forget removed the wrong Value")
+		}
+		runtime.KeepAlive(foo)
+	}
+}
+
+func TestForgetThenFinalize(t *testing.T) {
+	if safeMap() != nil {
+		t.Skip("Values aren't finalized in safe-but-leaky mode")
+	}
+	in := New()
+	func() {
+		in.GetByString("a")
+		in.Forget("a")
+	}()
+	b := in.GetByString("a")
+	for i := 0; i < 10; i++ {
+		runtime.GC()
+	}
+	if in.GetByString("a") != b {
+		t.Error("finalizing the forgotten Value removed its replacement")
+	}
+	runtime.KeepAlive(b)
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNotInterned is returned by ReadOnlyInterner.TryGet for values
+// that weren't interned when it was made.
+var ErrNotInterned = errors.New("intern: value not interned")
+
+// A ReadOnlyInterner is an immutable view of the values in an
+// Interner when Freeze was called. It answers Gets without locking,
+// for serving after a warmup phase in which the Interner was filled.
+//
+// A ReadOnlyInterner holds its Values, so they remain interned for as
+// long as it's reachable. Values interned in the Interner later aren't
+// in it.
+type ReadOnlyInterner struct {
+	in *Interner
+	m  map[key]*Value
+}
+
+// Freeze returns a ReadOnlyInterner with the values now in in.
+//
+// Freeze takes time proportional to the size of in.
+func (in *Interner) Freeze() *ReadOnlyInterner {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.drainFinalizedLocked()
+	m := map[key]*Value{}
+	in.forEachLocked(func(v *Value) {
+		// We're retaining pointers made from uintptrs.
+		if v.resurrect() {
+			m[keyFor(v.cmpVal)] = v
+		}
+	})
+	return &ReadOnlyInterner{in: in, m: m}
+}
+
+// Len returns the number of values in r.
+func (r *ReadOnlyInterner) Len() int {
+	return len(r.m)
+}
+
+// Lookup returns the Value for cmpVal, canonicalized as the Interner
+// it was made from would, and reports whether it's in r.
+func (r *ReadOnlyInterner) Lookup(cmpVal interface{}) (*Value, bool) {
+	return r.lookup(r.in.keyOf(cmpVal))
+}
+
+func (r *ReadOnlyInterner) lookup(k key) (*Value, bool) {
+	if !k.isString && k.cmpVal == nil {
+		return nilValue, true
+	}
+	v, ok := r.m[k]
+	return v, ok
+}
+
+// Get is like Interner.Get, but returns a new, uninterned Value if
+// cmpVal isn't in r.
+func (r *ReadOnlyInterner) Get(cmpVal interface{}) *Value {
+	k := r.in.keyOf(cmpVal)
+	if v, ok := r.lookup(k); ok {
+		return v
+	}
+	return k.Value(r.in)
+}
+
+// GetByString is like Get, but specialized for strings, like the
+// package-level GetByString.
+func (r *ReadOnlyInterner) GetByString(s string) *Value {
+	if r.in.canonicalize != nil {
+		return r.Get(s)
+	}
+	k := r.in.stringKey(s)
+	if v, ok := r.lookup(k); ok {
+		return v
+	}
+	return k.Value(r.in)
+}
+
+// TryGet is like Get, but returns an error wrapping ErrNotInterned
+// if cmpVal isn't in r, or wrapping ErrUncomparable if it isn't
+// comparable.
+func (r *ReadOnlyInterner) TryGet(cmpVal interface{}) (*Value, error) {
+	if err := checkComparable(cmpVal); err != nil {
+		return nil, err
+	}
+	if v, ok := r.Lookup(cmpVal); ok {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrNotInterned, cmpVal)
+}
+
+// Diff returns the values in r that aren't in old, and those in old
+// that aren't in r, each sorted as by Value.Less. With two views of
+// the same Interner frozen at different times, it shows what was
+// interned, and what was collected or removed, in between, such as
+// across a deploy or a change in traffic.
+//
+// Values are compared by their underlying values, so a value that was
+// collected and interned again in between, with a new Value, is in
+// neither result.
+func (r *ReadOnlyInterner) Diff(old *ReadOnlyInterner) (added, removed []*Value) {
+	for k, v := range r.m {
+		if _, ok := old.m[k]; !ok {
+			added = append(added, v)
+		}
+	}
+	for k, v := range old.m {
+		if _, ok := r.m[k]; !ok {
+			removed = append(removed, v)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].Less(added[j]) })
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Less(removed[j]) })
+	return added, removed
+}
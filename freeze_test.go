@@ -0,0 +1,78 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	in := New(CaseInsensitive())
+	a, one := in.GetByString("Alpha"), in.Get(1)
+	r := in.Freeze()
+	in.GetByString("later")
+
+	if r.Len() != 2 {
+		t.Errorf("Len = %d; want 2", r.Len())
+	}
+	if r.GetByString("ALPHA") != a || r.Get("alpha") != a || r.Get(1) != one {
+		t.Error("ReadOnlyInterner didn't return frozen Values")
+	}
+	if v, ok := r.Lookup("later"); ok || v != nil {
+		t.Error("value interned after Freeze found")
+	}
+	if r.GetByString("later") == in.GetByString("later") || r.Get(2) == r.Get(2) {
+		t.Error("miss returned an interned Value")
+	}
+	if r.Get(nil) != Get(nil) {
+		t.Error("Get(nil) isn't the sentinel")
+	}
+	if v, err := r.TryGet(1); err != nil || v != one {
+		t.Errorf("TryGet(1) = %p, %v", v, err)
+	}
+	if _, err := r.TryGet(2); !errors.Is(err, ErrNotInterned) {
+		t.Errorf("TryGet(2) = %v; want ErrNotInterned", err)
+	}
+	if _, err := r.TryGet([]int{}); !errors.Is(err, ErrUncomparable) {
+		t.Errorf("TryGet of slice = %v; want ErrUncomparable", err)
+	}
+
+	n := testing.AllocsPerRun(100, func() { r.GetByString("alpha") })
+	if n > 0 {
+		t.Errorf("GetByString allocs = %v; want 0", n)
+	}
+}
+
+func TestFreezeDiff(t *testing.T) {
+	in := New()
+	a := in.GetByString("a")
+	in.GetByString("b")
+	before := in.Freeze()
+	in.Forget("a")
+	c, d := in.GetByString("c"), in.Get(4)
+	in.Forget("b")
+	in.GetByString("b") // collected and interned again: no change
+	after := in.Freeze()
+
+	added, removed := after.Diff(before)
+	if len(added) != 2 || added[0] != d || added[1] != c {
+		t.Errorf("added = %v; want [4 c]", values(added))
+	}
+	if len(removed) != 1 || removed[0] != a {
+		t.Errorf("removed = %v; want [a]", values(removed))
+	}
+	if added, removed := after.Diff(after); len(added)+len(removed) != 0 {
+		t.Error("Diff with itself not empty")
+	}
+}
+
+func values(vs []*Value) []interface{} {
+	var s []interface{}
+	for _, v := range vs {
+		s = append(s, v.Get())
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func FuzzGet(f *testing.F) {
+	f.Add("foo", int64(0))
+	f.Add("", int64(-1))
+	f.Fuzz(func(t *testing.T, s string, i int64) {
+		for _, in := range []*Interner{New(), New(HashKeys()), New(CaseInsensitive())} {
+			vs := in.GetByString(s)
+			vi := in.Get(i)
+			runtime.GC()
+			if in.Get(s) != vs || in.GetByString(s) != vs {
+				t.Errorf("Get(%q) not canonical", s)
+			}
+			if in.Get(i) != vi {
+				t.Errorf("Get(%d) not canonical", i)
+			}
+			if vi.Get() != i {
+				t.Errorf("Get(%d).Get() = %v", i, vi.Get())
+			}
+			if vs == vi {
+				t.Errorf("string %q and int %d share a Value", s, i)
+			}
+		}
+	})
+}
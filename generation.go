@@ -0,0 +1,74 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// A Generation is a scope, such as the processing of one file, whose
+// newly interned values can be released all at once when it ends,
+// rather than as the garbage collector notices each one is unused.
+//
+// Values interned through a Generation's Get methods that weren't
+// already interned belong to the Generation. End removes them from the
+// Interner. Values that were already interned, whether by the
+// Generation's Get methods or not, are unaffected.
+//
+// A Generation may be used by multiple goroutines at once.
+type Generation struct {
+	in *Interner
+	id uint32
+}
+
+// BeginGeneration starts a new Generation in in.
+func (in *Interner) BeginGeneration() *Generation {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.lastGen++
+	if in.lastGen == 0 {
+		in.lastGen++ // zero means no generation
+	}
+	return &Generation{in: in, id: in.lastGen}
+}
+
+// Get is like Interner.Get, but a Value it inserts belongs to g.
+func (g *Generation) Get(cmpVal interface{}) *Value {
+	return g.in.getValue(cmpVal, getCtx{gen: g.id})
+}
+
+// GetByString is like Interner.GetByString, but a Value it inserts
+// belongs to g.
+func (g *Generation) GetByString(s string) *Value {
+	if g.in.canonicalize != nil {
+		return g.in.getValue(s, getCtx{gen: g.id})
+	}
+	return g.in.getString(s, getCtx{gen: g.id})
+}
+
+// End removes the values belonging to g from its Interner, and
+// reports how many there were.
+//
+// Values already returned remain valid, but are no longer canonical:
+// a later Get of an equal value returns a new pointer. Callers should
+// stop using a Generation's values once it ends.
+//
+// End takes time proportional to the size of the Interner.
+func (g *Generation) End() int {
+	in := g.in
+	defer in.flushEvents()
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	var drop []*Value
+	in.forEachLocked(func(v *Value) {
+		if v.gen == g.id {
+			drop = append(drop, v)
+		}
+	})
+	for _, v := range drop {
+		in.removeLocked(v)
+	}
+	if len(drop) > 0 {
+		in.dropSnapshot()
+		in.eventLocked(Event{Kind: EventEvicted, Count: len(drop), Reason: "generation"})
+	}
+	return len(drop)
+}
@@ -0,0 +1,39 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGeneration(t *testing.T) {
+	for _, opts := range [][]Option{nil, {HashKeys()}, {CaseInsensitive()}} {
+		in := New(opts...)
+		before := in.GetByString("before")
+
+		g := in.BeginGeneration()
+		if g.GetByString("before") != before {
+			t.Fatal("Generation returned a new Value for an interned string")
+		}
+		a, b := g.GetByString("a"), g.Get(2)
+		if in.GetByString("a") != a || in.Get(2) != b {
+			t.Fatal("Generation's values not shared with the Interner")
+		}
+		other := in.BeginGeneration()
+		c := other.GetByString("c")
+
+		if n := g.End(); n != 2 {
+			t.Errorf("End removed %d values; want 2", n)
+		}
+		if in.GetByString("a") == a || in.Get(2) == b {
+			t.Error("Generation's values still canonical after End")
+		}
+		if in.GetByString("before") != before || in.GetByString("c") != c {
+			t.Error("End removed values not belonging to the Generation")
+		}
+		runtime.KeepAlive(before)
+	}
+}
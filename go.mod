@@ -1,5 +1,3 @@
 module go4.org/intern
 
 go 1.13
-
-require go4.org/unsafe/assume-no-moving-gc v0.0.0-20230525183740-e7c30c78aeb2
@@ -0,0 +1,212 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "sync"
+
+// A Handle is a compact integer identifying a value interned in a
+// HandleTable. Handles of equal values from the same HandleTable are
+// equal, so, like *Value, they can be compared and used as map keys.
+//
+// Handles contain no pointers. Programs holding very many of them
+// therefore don't give the garbage collector pointers to scan, as
+// they would holding *Values.
+//
+// The zero Handle is never assigned and can be used to mean "none".
+type Handle uint64
+
+// handleSlabSize is the number of values in each of a HandleTable's
+// slabs.
+const handleSlabSize = 1 << 12
+
+// A HandleTable interns values, returning a Handle for each.
+//
+// Values are located by fixed-size slabs that are allocated as the
+// table grows and never moved. Strings' bytes are packed into large
+// arenas, located in the slabs by offset and length, and strings are
+// indexed by a hash table of Handles, so neither the slabs nor the
+// index hold pointers for the garbage collector to scan. Because
+// Handles are plain integers,
+// the table can't know when they're no longer used: it keeps every
+// value it has interned until the table itself is unreachable.
+//
+// A HandleTable is safe for concurrent use.
+type HandleTable struct {
+	mu    sync.RWMutex
+	index map[key]Handle // values other than strings
+	strs  handleIndex    // strings
+	arena stringArena    // bytes of strings
+	vals  []interface{}  // values other than strings, by handleRef.off
+	slabs [][]handleRef  // slabs[h>>12][h&(1<<12-1)] locates h's value
+	n     int            // number of Handles assigned, plus one for the zero Handle
+}
+
+// A handleRef locates the value of a Handle: a string in its
+// HandleTable's arena, or, if chunk is notString, vals[off].
+type handleRef strRef
+
+// notString is the chunk of handleRefs of values other than strings.
+const notString = ^uint32(0)
+
+// NewHandleTable returns a new, empty HandleTable.
+func NewHandleTable() *HandleTable {
+	return &HandleTable{
+		index: map[key]Handle{},
+		n:     1,
+	}
+}
+
+// Get returns the Handle for the comparable value cmpVal, assigning
+// one if cmpVal isn't yet in t.
+func (t *HandleTable) Get(cmpVal interface{}) Handle {
+	return t.get(keyFor(cmpVal))
+}
+
+// GetByString is like Get, but specialized for strings, like the
+// package-level GetByString.
+func (t *HandleTable) GetByString(s string) Handle {
+	return t.get(key{s: s, isString: true})
+}
+
+func (t *HandleTable) get(k key) Handle {
+	var hash uint64
+	if k.isString {
+		hash = tableHashString(k.s)
+	}
+	t.mu.RLock()
+	h := t.findLocked(k, hash)
+	t.mu.RUnlock()
+	if h != 0 {
+		return h
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if h := t.findLocked(k, hash); h != 0 {
+		return h
+	}
+	h = Handle(t.n)
+	var r handleRef
+	if k.isString {
+		r = handleRef(t.arena.add(k.s))
+		t.strs.insert(hash, h)
+	} else {
+		r = handleRef{chunk: notString, off: uint32(len(t.vals))}
+		t.vals = append(t.vals, k.cmpVal)
+		t.index[k] = h
+	}
+	if t.n%handleSlabSize == 0 || len(t.slabs) == 0 {
+		t.slabs = append(t.slabs, make([]handleRef, handleSlabSize))
+	}
+	t.slabs[t.n/handleSlabSize][t.n%handleSlabSize] = r
+	t.n++
+	return h
+}
+
+// findLocked returns the Handle for k, a string with hash hash or
+// another value, or zero if k isn't in t. t.mu must be held.
+func (t *HandleTable) findLocked(k key, hash uint64) Handle {
+	if !k.isString {
+		return t.index[k]
+	}
+	if len(t.strs.slots) == 0 {
+		return 0
+	}
+	mask := uint64(len(t.strs.slots) - 1)
+	for i := hash & mask; ; i = (i + 1) & mask {
+		s := t.strs.slots[i]
+		if s.h == 0 {
+			return 0
+		}
+		if s.hash == hash && t.keyLocked(s.h).s == k.s {
+			return s.h
+		}
+	}
+}
+
+// Value returns the value that h identifies. It panics if h wasn't
+// returned by t.
+func (t *HandleTable) Value(h Handle) interface{} {
+	k := t.key(h)
+	if k.isString {
+		return k.s
+	}
+	return k.cmpVal
+}
+
+// String returns the string that h identifies. It panics if h wasn't
+// returned by t, or doesn't identify a string.
+func (t *HandleTable) String(h Handle) string {
+	k := t.key(h)
+	if !k.isString {
+		panic("intern: Handle doesn't identify a string")
+	}
+	return k.s
+}
+
+func (t *HandleTable) key(h Handle) key {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if h == 0 || h >= Handle(t.n) {
+		panic("intern: invalid Handle")
+	}
+	return t.keyLocked(h)
+}
+
+// keyLocked returns the key of h, a valid Handle. t.mu must be held.
+func (t *HandleTable) keyLocked(h Handle) key {
+	r := t.slabs[h/handleSlabSize][h%handleSlabSize]
+	if r.chunk == notString {
+		return key{cmpVal: t.vals[r.off]}
+	}
+	return key{s: t.arena.string(strRef(r)), isString: true}
+}
+
+// A handleIndex is an open-addressing hash table of Handles, like
+// table but without removal.
+type handleIndex struct {
+	slots []handleSlot // len is zero or a power of two
+	count int
+}
+
+type handleSlot struct {
+	hash uint64
+	h    Handle // or zero if empty
+}
+
+// insert adds h, whose value has hash hash.
+func (x *handleIndex) insert(hash uint64, h Handle) {
+	if (x.count+1)*4 > len(x.slots)*3 {
+		size := minTableSize
+		for size*3 < (x.count+1)*4*2 {
+			size *= 2
+		}
+		old := x.slots
+		x.slots = make([]handleSlot, size)
+		for _, s := range old {
+			if s.h != 0 {
+				x.place(s)
+			}
+		}
+	}
+	x.place(handleSlot{hash, h})
+	x.count++
+}
+
+func (x *handleIndex) place(s handleSlot) {
+	mask := uint64(len(x.slots) - 1)
+	i := s.hash & mask
+	for x.slots[i].h != 0 {
+		i = (i + 1) & mask
+	}
+	x.slots[i] = s
+}
+
+// Len returns the number of values in t.
+func (t *HandleTable) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.n - 1
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestHandleTable(t *testing.T) {
+	tab := NewHandleTable()
+	foo := tab.GetByString("foo")
+	one := tab.Get(1)
+	if foo == 0 || one == 0 || foo == one {
+		t.Fatalf("handles = %d, %d", foo, one)
+	}
+	if tab.Get("foo") != foo || tab.GetByString("foo") != foo || tab.Get(1) != one {
+		t.Error("handles of equal values differ")
+	}
+	if got := tab.String(foo); got != "foo" {
+		t.Errorf("String = %q", got)
+	}
+	if got := tab.Value(one); got != 1 {
+		t.Errorf("Value = %v", got)
+	}
+	if n := tab.Len(); n != 2 {
+		t.Errorf("Len = %d; want 2", n)
+	}
+
+	for _, bad := range []Handle{0, 100} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Value(%d) didn't panic", bad)
+				}
+			}()
+			tab.Value(bad)
+		}()
+	}
+}
+
+func TestHandleTableSlabs(t *testing.T) {
+	tab := NewHandleTable()
+	const n = 3*handleSlabSize + 10
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				tab.GetByString(strconv.Itoa(i))
+			}
+		}()
+	}
+	wg.Wait()
+	if got := tab.Len(); got != n {
+		t.Fatalf("Len = %d; want %d", got, n)
+	}
+	for i := 0; i < n; i++ {
+		s := strconv.Itoa(i)
+		if got := tab.String(tab.GetByString(s)); got != s {
+			t.Fatalf("String(GetByString(%q)) = %q", s, got)
+		}
+	}
+}
+
+func TestHandleTableStrings(t *testing.T) {
+	tab := NewHandleTable()
+	big := strings.Repeat("x", strArenaMax+1)
+	vals := []interface{}{"", "a", big, 2, "b", [2]int{3, 4}}
+	var hs []Handle
+	for _, v := range vals {
+		hs = append(hs, tab.Get(v))
+	}
+	for i, v := range vals {
+		if got := tab.Value(hs[i]); got != v {
+			t.Errorf("Value(Get(%.10v)) = %.10v", v, got)
+		}
+		if tab.Get(v) != hs[i] {
+			t.Errorf("Get(%.10v) changed", v)
+		}
+	}
+
+	// The slabs hold three uint32s per value, and no pointers.
+	if size := reflect.TypeOf(handleRef{}).Size(); size != 12 {
+		t.Errorf("handleRef is %d bytes; want 12", size)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"reflect"
+)
+
+// fnv128a is a 128-bit FNV-1a hash, as in hash/fnv, but usable
+// without allocating.
+type fnv128a struct {
+	hi, lo uint64
+}
+
+func newFNV128a() fnv128a {
+	return fnv128a{hi: 0x6c62272e07bb0142, lo: 0x62b821756295c58d}
+}
+
+func (h *fnv128a) writeByte(c byte) {
+	h.lo ^= uint64(c)
+	// Multiply by the FNV-128 prime, 2**88 + 0x13b.
+	hi, lo := bits.Mul64(h.lo, 0x13b)
+	h.hi = hi + h.hi*0x13b + h.lo<<24
+	h.lo = lo
+}
+
+func (h *fnv128a) write(b []byte) {
+	for _, c := range b {
+		h.writeByte(c)
+	}
+}
+
+func (h *fnv128a) writeString(s string) {
+	for i := 0; i < len(s); i++ {
+		h.writeByte(s[i])
+	}
+}
+
+func (h *fnv128a) sum() (sum [16]byte) {
+	binary.BigEndian.PutUint64(sum[:8], h.hi)
+	binary.BigEndian.PutUint64(sum[8:], h.lo)
+	return sum
+}
+
+// hashKey returns a hash of k, such that equal keys hash equally.
+func hashKey(k key) uint64 {
+	return hashLow64(hashKey128(k))
+}
+
+// hashLow64 returns the low 64 bits of a 128-bit hash.
+func hashLow64(h [16]byte) uint64 {
+	return binary.BigEndian.Uint64(h[8:])
+}
+
+// hashKey128 is like hashKey, but returns a 128-bit hash.
+func hashKey128(k key) [16]byte {
+	if k.isString {
+		return hashString128(k.s)
+	}
+	h := newFNV128a()
+	v := reflect.ValueOf(k.cmpVal)
+	hashType(&h, v)
+	hashValue(&h, v)
+	return h.sum()
+}
+
+// hashType writes the identity of v's type to h, so that values of
+// different types with the same representation, such as int64(5) and
+// time.Duration(5), hash differently.
+func hashType(h *fnv128a, v reflect.Value) {
+	if !v.IsValid() {
+		return
+	}
+	t := v.Type()
+	for _, s := range [2]string{t.PkgPath(), t.String()} {
+		var buf [binary.MaxVarintLen64]byte
+		h.write(buf[:binary.PutUvarint(buf[:], uint64(len(s)))])
+		h.writeString(s)
+	}
+}
+
+// hashString128 returns hashKey128 of the string key s.
+// Unlike hashKey128, it doesn't cause its argument to escape.
+func hashString128(s string) [16]byte {
+	h := newFNV128a()
+	h.writeByte('s')
+	h.writeString(s)
+	return h.sum()
+}
+
+// hashValue writes a representation of the comparable value v to h,
+// such that values that are == write the same bytes.
+func hashValue(h *fnv128a, v reflect.Value) {
+	var buf [1 + binary.MaxVarintLen64]byte
+	buf[0] = byte(v.Kind())
+	n := 1
+	switch v.Kind() {
+	case reflect.Invalid:
+	case reflect.Bool:
+		if v.Bool() {
+			buf[1] = 1
+		}
+		n = 2
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n += binary.PutVarint(buf[1:], v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n += binary.PutUvarint(buf[1:], v.Uint())
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f == 0 {
+			f = 0 // -0 == +0
+		}
+		binary.LittleEndian.PutUint64(buf[1:], math.Float64bits(f))
+		n = 9
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		h.write(buf[:1])
+		hashValue(h, reflect.ValueOf(real(c)))
+		hashValue(h, reflect.ValueOf(imag(c)))
+		return
+	case reflect.String:
+		n += binary.PutUvarint(buf[1:], uint64(v.Len()))
+		h.write(buf[:n])
+		h.writeString(v.String())
+		return
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		binary.LittleEndian.PutUint64(buf[1:], uint64(pointerBits(v)))
+		n = 9
+	case reflect.Interface:
+		h.write(buf[:1])
+		hashType(h, v.Elem())
+		hashValue(h, v.Elem())
+		return
+	case reflect.Array:
+		h.write(buf[:1])
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i))
+		}
+		return
+	case reflect.Struct:
+		h.write(buf[:1])
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).Name != "_" {
+				hashValue(h, v.Field(i))
+			}
+		}
+		return
+	}
+	h.write(buf[:n])
+}
@@ -0,0 +1,17 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package intern
+
+import "reflect"
+
+// pointerBits returns the address held by v, a pointer, channel, or
+// unsafe.Pointer. Unlike v.Pointer, it doesn't let v escape, so
+// hashing a key needn't move it to the heap.
+func pointerBits(v reflect.Value) uintptr {
+	return uintptr(v.UnsafePointer())
+}
@@ -0,0 +1,16 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.18
+// +build !go1.18
+
+package intern
+
+import "reflect"
+
+// pointerBits returns the address held by v, a pointer, channel, or
+// unsafe.Pointer.
+func pointerBits(v reflect.Value) uintptr {
+	return v.Pointer()
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bytes"
+	"hash/fnv"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFNV128a(t *testing.T) {
+	for _, s := range []string{"", "a", "foobar", "the quick brown fox"} {
+		want := fnv.New128a()
+		want.Write([]byte(s))
+		h := newFNV128a()
+		h.writeString(s)
+		got := h.sum()
+		if !bytes.Equal(got[:], want.Sum(nil)) {
+			t.Errorf("fnv128a(%q) = %x; want %x", s, got, want.Sum(nil))
+		}
+	}
+}
+
+func TestHashKey(t *testing.T) {
+	type s struct {
+		a int
+		b string
+		c interface{}
+	}
+	equal := [][2]interface{}{
+		{0.0, math.Copysign(0, -1)},
+		{s{1, "x", 2}, s{1, "x", 2}},
+		{[2]string{"a", "b"}, [2]string{"a", "b"}},
+		{complex(1, 2), complex(1, 2)},
+	}
+	for _, p := range equal {
+		if hashKey(keyFor(p[0])) != hashKey(keyFor(p[1])) {
+			t.Errorf("hashKey(%v) != hashKey(%v)", p[0], p[1])
+		}
+	}
+	differ := [][2]interface{}{
+		{1, "1"},
+		{int8(1), int16(1)},
+		{s{1, "x", 2}, s{1, "x", 3}},
+		{[2]string{"ab", ""}, [2]string{"a", "b"}},
+		{int64(5), time.Duration(5)},
+		{pointA{1}, pointB{1}},
+		{s{c: int64(5)}, s{c: time.Duration(5)}},
+	}
+	for _, p := range differ {
+		if hashKey(keyFor(p[0])) == hashKey(keyFor(p[1])) {
+			t.Errorf("hashKey(%v) == hashKey(%v)", p[0], p[1])
+		}
+	}
+}
+
+// pointA and pointB have the same underlying type.
+type (
+	pointA struct{ X int }
+	pointB struct{ X int }
+)
+
+func TestHashKeysSameUnderlyingType(t *testing.T) {
+	in := New(HashKeys())
+	a, b := in.Get(pointA{1}), in.Get(pointB{1})
+	if in.Get(pointA{1}) != a || in.Get(pointB{1}) != b {
+		t.Error("values sharing an underlying type not both interned")
+	}
+	d := in.Get(time.Duration(5))
+	if in.Get(int64(5)) == d || in.Get(time.Duration(5)) != d {
+		t.Error("time.Duration and int64 not interned separately")
+	}
+}
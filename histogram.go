@@ -0,0 +1,98 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"math"
+	"sort"
+)
+
+// A Histogram is a distribution of observations, such as those
+// returned by Interner.Lifetimes, counted in buckets.
+type Histogram struct {
+	// Bounds are the inclusive upper bounds of the buckets,
+	// increasing. Each bound is double the previous one.
+	Bounds []float64
+	// Counts are the numbers of observations in each bucket:
+	// Counts[i] counts those greater than Bounds[i-1] (if any) and
+	// no greater than Bounds[i]. It has an extra, final element
+	// counting the observations greater than every bound.
+	Counts []uint64
+
+	// Count is the number of observations, and Sum their total.
+	Count uint64
+	Sum   float64
+}
+
+// Mean returns the mean observation, or 0 if there were none.
+func (h Histogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / float64(h.Count)
+}
+
+// Quantile returns an upper bound on the q-quantile of the
+// observations, for q between 0 and 1: the bound of the bucket holding
+// it, or +Inf if it's in the final bucket. It returns 0 if there were
+// no observations.
+func (h Histogram) Quantile(q float64) float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(h.Count)))
+	if rank == 0 {
+		rank = 1
+	}
+	var n uint64
+	for i, c := range h.Counts {
+		n += c
+		if n >= rank && i < len(h.Bounds) {
+			return h.Bounds[i]
+		}
+	}
+	return math.Inf(1)
+}
+
+// histogram accumulates a Histogram.
+type histogram struct {
+	bounds []float64 // shared; not modified
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// newHistogram returns a histogram with n buckets bounded by first,
+// 2*first, 4*first, and so on, plus the overflow bucket.
+func newHistogram(first float64, n int) *histogram {
+	bounds := make([]float64, n)
+	for i := range bounds {
+		bounds[i] = first * math.Pow(2, float64(i))
+	}
+	return &histogram{bounds: bounds, counts: make([]uint64, n+1)}
+}
+
+func (h *histogram) observe(x float64) {
+	h.counts[sort.SearchFloat64s(h.bounds, x)]++
+	h.count++
+	h.sum += x
+}
+
+// unobserve removes x, previously observed, from h.
+func (h *histogram) unobserve(x float64) {
+	h.counts[sort.SearchFloat64s(h.bounds, x)]--
+	h.count--
+	h.sum -= x
+}
+
+// snapshot returns a copy of h's distribution.
+func (h *histogram) snapshot() Histogram {
+	return Histogram{
+		Bounds: h.bounds,
+		Counts: append([]uint64(nil), h.counts...),
+		Count:  h.count,
+		Sum:    h.sum,
+	}
+}
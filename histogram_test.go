@@ -0,0 +1,48 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram(1, 4) // bounds 1, 2, 4, 8
+	for _, x := range []float64{0.5, 1, 3, 3, 4, 100} {
+		h.observe(x)
+	}
+	s := h.snapshot()
+	if want := []float64{1, 2, 4, 8}; !reflect.DeepEqual(s.Bounds, want) {
+		t.Errorf("Bounds = %v; want %v", s.Bounds, want)
+	}
+	if want := []uint64{2, 0, 3, 0, 1}; !reflect.DeepEqual(s.Counts, want) {
+		t.Errorf("Counts = %v; want %v", s.Counts, want)
+	}
+	if s.Count != 6 || s.Sum != 111.5 {
+		t.Errorf("Count, Sum = %v, %v; want 6, 111.5", s.Count, s.Sum)
+	}
+	for _, tt := range []struct{ q, want float64 }{
+		{0, 1},
+		{0.3, 1},
+		{0.5, 4},
+		{0.8, 4},
+		{1, math.Inf(1)},
+	} {
+		if got := s.Quantile(tt.q); got != tt.want {
+			t.Errorf("Quantile(%v) = %v; want %v", tt.q, got, tt.want)
+		}
+	}
+
+	h.observe(1)
+	if s.Counts[0] != 2 {
+		t.Error("snapshot shares its counts")
+	}
+	var zero Histogram
+	if zero.Mean() != 0 || zero.Quantile(0.5) != 0 {
+		t.Error("zero Histogram has a nonzero Mean or Quantile")
+	}
+}
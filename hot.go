@@ -0,0 +1,103 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// CountHits returns an Option that counts the Gets that find each
+// interned Value, for reporting by TopN.
+func CountHits() Option {
+	return func(in *Interner) { in.countHits = true }
+}
+
+// A HotValue is a Value and its hit count, as reported by TopN.
+type HotValue struct {
+	Value *Value
+	// Hits is the number of Gets that found Value already interned.
+	Hits uint64
+}
+
+// TopN returns the n interned Values with the most hits, in decreasing
+// order of hits, to help decide what to pin or prewarm. Hits are only
+// counted if in was created with the CountHits option; otherwise TopN
+// returns nil.
+//
+// TopN takes time proportional to the size of the Interner.
+func (in *Interner) TopN(n int) []HotValue {
+	if n <= 0 || !in.countHits {
+		return nil
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	h := make(hotHeap, 0, n)
+	in.forEachLocked(func(v *Value) {
+		hits := v.hitCount()
+		if len(h) < n {
+			heap.Push(&h, HotValue{v, hits})
+		} else if hits > h[0].Hits {
+			h[0] = HotValue{v, hits}
+			heap.Fix(&h, 0)
+		}
+	})
+	for _, hv := range h {
+		// We're handing out pointers made from uintptrs.
+		hv.Value.resurrect()
+	}
+	sort.Slice(h, func(i, j int) bool { return h[i].Hits > h[j].Hits })
+	return h
+}
+
+// forEachLocked calls f with each Value in the index.
+// f mustn't retain the Value unless it resurrects it.
+// in.mu must be held.
+func (in *Interner) forEachLocked(f func(*Value)) {
+	switch {
+	case in.valSafe != nil:
+		for _, v := range in.valSafe {
+			f(v)
+		}
+	case in.wk != nil:
+		in.wk.forEach(f)
+	case in.hashMap != nil:
+		for _, addr := range in.hashMap {
+			f(valueAt(addr))
+		}
+	default:
+		for _, s := range in.tab.slots {
+			if s.addr != empty && s.addr != tombstone {
+				f(valueAt(s.addr))
+			}
+		}
+		for _, addr := range in.flooded {
+			f(valueAt(addr))
+		}
+	}
+}
+
+// hotHeap is a min-heap of HotValues by Hits.
+type hotHeap []HotValue
+
+func (h hotHeap) Len() int            { return len(h) }
+func (h hotHeap) Less(i, j int) bool  { return h[i].Hits < h[j].Hits }
+func (h hotHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *hotHeap) Push(x interface{}) { *h = append(*h, x.(HotValue)) }
+func (h *hotHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// hitCount returns the number of Gets that found v, if counted.
+// in.mu must be held.
+func (v *Value) hitCount() uint64 {
+	if e := v.ext(); e != nil {
+		return e.hits
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestTopN(t *testing.T) {
+	in := New(CountHits())
+	var keep []*Value
+	for i := 0; i < 10; i++ {
+		for j := 0; j <= i; j++ {
+			keep = append(keep, in.Get(i))
+		}
+	}
+	top := in.TopN(3)
+	if len(top) != 3 {
+		t.Fatalf("TopN(3) returned %d values", len(top))
+	}
+	for i, hv := range top {
+		want := 9 - i
+		if hv.Value.Get() != want || hv.Hits != uint64(want) {
+			t.Errorf("TopN[%d] = %v with %d hits; want %d with %d", i, hv.Value.Get(), hv.Hits, want, want)
+		}
+	}
+	if got := in.TopN(100); len(got) != 10 {
+		t.Errorf("TopN(100) returned %d values; want 10", len(got))
+	}
+	if got := New().TopN(3); got != nil {
+		t.Errorf("TopN without CountHits = %v; want nil", got)
+	}
+	runtime.KeepAlive(keep)
+}
@@ -13,13 +13,15 @@
 package intern // import "go4.org/intern"
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"runtime"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"unsafe"
-
-	_ "go4.org/unsafe/assume-no-moving-gc"
 )
 
 // A Value pointer is the handle to an underlying comparable value.
@@ -27,14 +29,84 @@ import (
 type Value struct {
 	_      [0]func() // prevent people from accidentally using value type as comparable
 	cmpVal interface{}
-	// resurrected is guarded by mu (for all instances of Value).
-	// It is set true whenever v is synthesized from a uintptr.
-	resurrected bool
+	in     *Interner // the Interner v belongs to
+	// state is v's resurrection state, accessed atomically.
+	// It is set to stateResurrected, by resurrect, whenever v is
+	// synthesized from a uintptr.
+	state  uint32
+	rearms uint16 // times finalize was re-armed, saturating; guarded by in.mu
+	gen    uint32 // generation v was inserted in, or 0; guarded by in.mu
+
+	extp unsafe.Pointer // *valueExt, or nil; accessed atomically
+}
+
+// A valueExt holds the fields of a Value that only some options use,
+// so that they don't make every Value larger. It's allocated the
+// first time one is set.
+type valueExt struct {
+	aux    unsafe.Pointer // *auxBox, or nil; accessed atomically
+	hits   uint64         // Gets that found v, if counted; guarded by in.mu
+	tenant *tenantState   // tenant that inserted v, or nil; guarded by in.mu
+	parts  []*Value       // Values of a composite's strings; see InternComponents
+	refs   int            // Gets not yet Released, in ManualCollect mode; guarded by in.mu
+}
+
+// ext returns v's valueExt, or nil if it has none.
+func (v *Value) ext() *valueExt {
+	return (*valueExt)(atomic.LoadPointer(&v.extp))
+}
+
+// addExt returns v's valueExt, allocating it if needed.
+func (v *Value) addExt() *valueExt {
+	if e := v.ext(); e != nil {
+		return e
+	}
+	atomic.CompareAndSwapPointer(&v.extp, nil, unsafe.Pointer(new(valueExt)))
+	return v.ext()
+}
+
+// Value states. A Value starts live, is marked resurrected whenever
+// a pointer to it is made from a uintptr, and is marked live again
+// when its finalizer runs and re-arms itself. A finalizer that finds
+// it live marks it dead and removes it from the table. Once removed,
+// by its finalizer or explicitly, as by Forget, it's marked removed,
+// so that it's never removed, or its removal counted, again.
+const (
+	stateLive uint32 = iota
+	stateResurrected
+	stateDead
+	stateRemoved
+)
+
+// resurrect marks v as resurrected, after a pointer to it has been
+// made from a uintptr, and reports whether that succeeded. It fails
+// only if v's finalizer has already decided to remove it, or v has
+// been removed, in which case the caller must not return v.
+//
+// Because state is atomic, resurrect doesn't need in.mu, and code
+// that finds v's address without the lock can use it to claim v.
+func (v *Value) resurrect() bool {
+	for {
+		switch atomic.LoadUint32(&v.state) {
+		case stateResurrected:
+			return true
+		case stateDead, stateRemoved:
+			return false
+		}
+		if atomic.CompareAndSwapUint32(&v.state, stateLive, stateResurrected) {
+			return true
+		}
+	}
 }
 
 // Get returns the comparable value passed to the Get func
-// that returned v.
-func (v *Value) Get() interface{} { return v.cmpVal }
+// that returned v. If v is nil, Get returns nil.
+func (v *Value) Get() interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.cmpVal
+}
 
 // key is a key in our global value map.
 // It contains type-specialized fields to avoid allocations
@@ -55,21 +127,195 @@ func keyFor(cmpVal interface{}) key {
 	return key{cmpVal: cmpVal}
 }
 
-// Value returns a *Value built from k.
-func (k key) Value() *Value {
+// Value returns a *Value built from k, belonging to in.
+func (k key) Value(in *Interner) *Value {
 	if k.isString {
-		return &Value{cmpVal: k.s}
+		return &Value{cmpVal: k.s, in: in}
 	}
-	return &Value{cmpVal: k.cmpVal}
+	return &Value{cmpVal: k.cmpVal, in: in}
 }
 
-var (
-	// mu guards valMap, a weakref map of *Value by underlying value.
-	// It also guards the resurrected field of all *Values.
+// An Interner is a table of interned values. Values from different
+// Interners are distinct: Get(v) on two Interners returns two
+// different pointers, even though their underlying values are equal.
+//
+// The package-level functions use a default Interner.
+// Most programs need no other.
+type Interner struct {
+	// Options, set by New and read-only afterwards.
+	opts           []Option                      // as passed to New; see Clone
+	canonicalize   func(interface{}) interface{} // or nil
+	transform      func(string) string           // or nil
+	foldASCII      bool
+	countHits      bool
+	profile        bool
+	manual         bool       // see ManualCollect
+	refcounted     bool       // see Refcounted; implies manual
+	cloneMax       int        // see WithCloneThreshold
+	capacity       int        // see WithCapacity
+	byteArrays     bool       // hash byte arrays by their bytes; see Bytes16Interner
+	hashKeys       bool       // index by hashMap; see HashKeys
+	snapshots      bool       // see Snapshots
+	pressure       float64    // see WithMemoryPressure; 0 if unset
+	rejectPointers bool       // see RejectPointers
+	components     bool       // see InternComponents
+	seqlock        bool       // see Seqlock
+	rcu            bool       // see RCU
+	parent         *Interner  // or nil; see WithParent
+	siteRate       uint64     // see SampleCallSites; 0 if unset
+	window         *window    // or nil; see WindowedStats; guarded by mu
+	lifetimes      *lifetimes // or nil; see SampleLifetimes; guarded by mu
+	sizes          *histogram // or nil; see SizeStats; guarded by mu
+	leaks          *leaks     // or nil; see Leaks; guarded by mu
+
+	deferFinalize bool // see DeferFinalization
+
+	policy     RuntimePolicy // see WithRuntimePolicy
+	policySet  bool          // policy was set by WithRuntimePolicy
+	deferGuard bool          // see deferRuntimeGuard
+
+	maxSize    int         // see WithMaxSize; 0 if unset
+	minSize    int         // see WithMinSize; 0 if unset
+	tiny       bool        // see TinyStrings
+	frozen     bool        // see LoadDictionary; set before in is shared
+	onEvent    func(Event) // or nil; see OnEvent
+	thresholds []int       // sorted; see OnEvent
+
+	// disabled is non-zero if Gets bypass the table.
+	// It is accessed atomically; see SetDisabled.
+	disabled uint32
+
+	// admit, if non-nil, decides which missed keys are inserted.
+	// It is guarded by mu.
+	admit *sketch
+
+	// shadow, if non-nil, puts the Interner in shadow mode.
+	// It is guarded by mu.
+	shadow *shadowStats
+
+	// Counters, guarded by mu.
+	hits, misses      uint64
+	bytesSaved        uint64 // sum of keySize over hits
+	rearms            uint64 // finalizers re-armed
+	finalized         uint64 // Values removed by finalizer
+	finalizedRearms   uint64 // sum of rearms of finalized Values
+	zombies           int    // Values re-armed and not yet finalized
+	pressureEvictions uint64 // Values removed under memory pressure
+	lastGen           uint32 // last generation started; see BeginGeneration
+
+	// evicted holds the values of the most recently finalized
+	// Values, for WriteDebug. nextEvict indexes the next to replace.
+	// Both are guarded by mu.
+	evicted   [numEvicted]interface{}
+	nextEvict int
+
+	// snap is the published snapshot, a map[key]*Value, possibly
+	// nil. snapHits counts Gets answered from it, atomically.
+	// See Snapshots.
+	snap     atomic.Value
+	snapHits uint64
+
+	// seqlockHits counts Gets answered without the lock, atomically.
+	// See Seqlock. rcuHits does likewise for RCU, if set.
+	seqlockHits uint64
+	rcuHits     *stripedCounter
+
+	// consts is the table of constants, a *constTable, or nil. It is
+	// accessed atomically, and written with mu held.
+	// See RegisterConstants.
+	consts unsafe.Pointer
+
+	// finq is a stack of Values whose finalizers have run, awaiting
+	// finalizeLocked, and finqLen its length. Both are accessed
+	// atomically. See DeferFinalization.
+	finq    unsafe.Pointer // *finNode
+	finqLen int32
+
+	tenants map[string]*tenantState // guarded by mu; see Tenant
+
+	// events are the events awaiting flushEvents, and nextThreshold
+	// indexes the next of thresholds to report. Both are guarded by
+	// mu. eventsPending is non-zero if events may be non-empty, and
+	// is accessed atomically.
+	events        []Event
+	nextThreshold int
+	eventsPending uint32
+
+	// Call sites sampled by SampleCallSites, by stack, and of the
+	// sampled Values still interned, by address, and the count of
+	// insertions toward the next sample. All are guarded by mu.
+	sites       map[[maxSiteDepth]uintptr]*CallSite
+	siteOf      map[uintptr]*CallSite
+	siteInserts uint64
+
+	janitor   *janitor // or nil
+	closeOnce sync.Once
+	closed    uint32 // non-zero if Gets fail fast; see isClosed; accessed atomically
+
+	// mu guards tab, a weakref table of *Value by underlying value,
+	// and the alternative maps below.
 	mu      sync.Mutex
-	valMap  = map[key]uintptr{} // to uintptr(*Value)
-	valSafe = safeMap()         // non-nil in safe+leaky mode
-)
+	tab     table
+	valSafe map[key]*Value       // non-nil in safe+leaky or ManualCollect mode
+	hashMap map[[16]byte]uintptr // replaces tab if non-nil; see HashKeys
+	ints    *radix               // replaces tab if non-nil; see Uint64Interner
+	wk      *weakIndex           // replaces tab if non-nil; see WeakPointers
+	flooded map[key]uintptr      // Values kept out of tab; see tableInsertLocked
+	// hashPeak is the largest len(hashMap) since it was last rebuilt.
+	hashPeak int
+}
+
+// std is the default Interner, used by the package-level functions.
+var std = New(stdOptions()...)
+
+// Default returns the default Interner, used by the package-level
+// functions.
+func Default() *Interner { return std }
+
+// New returns a new, empty Interner configured by opts.
+func New(opts ...Option) *Interner {
+	in := &Interner{
+		valSafe:  safeMap(),
+		cloneMax: defaultCloneThreshold,
+		opts:     opts,
+	}
+	safe := in.valSafe != nil
+	if safe {
+		in.leaks = newLeaks()
+	}
+	if defaultRefcounted {
+		Refcounted()(in)
+	}
+	for _, o := range opts {
+		o(in)
+	}
+	in.guardRuntime()
+	if in.tiny && in.minSize <= maxTiny {
+		in.minSize = maxTiny + 1
+	}
+	if in.seqlock || in.rcu {
+		usable := in.seqlockUsable()
+		in.rcu = in.rcu && usable
+		in.seqlock = in.seqlock && usable && !in.rcu
+		in.tab.shared = usable
+		if in.rcu {
+			in.rcuHits = new(stripedCounter)
+		}
+	}
+	in.snapshots = in.snapshots && in.snapshotsUsable()
+	if in.capacity > 0 {
+		in.presize()
+	}
+	if safe {
+		// Reported by the first Get, once in is ready for
+		// handlers to use.
+		in.eventLocked(Event{Kind: EventSafeMode})
+	}
+	if in.janitor != nil {
+		go in.janitor.run(in)
+	}
+	return in
+}
 
 // safeMap returns a non-nil map if we're in safe-but-leaky mode,
 // as controlled by GO4_INTERN_SAFE_BUT_LEAKY.
@@ -84,58 +330,534 @@ func safeMap() map[key]*Value {
 //
 // The returned pointer will be the same for Get(v) and Get(v2)
 // if and only if v == v2, and can be used as a map key.
+//
+// Get(nil) returns a single sentinel Value, the same for every
+// Interner, that is never collected. A nil *Value behaves like it:
+// the methods of Value that read it, such as Get, Hash, Compare, and
+// MarshalJSON, treat a nil receiver as the sentinel, so an optional
+// *Value field needn't be checked for nil before use.
 func Get(cmpVal interface{}) *Value {
-	return get(keyFor(cmpVal))
+	return std.Get(cmpVal)
 }
 
 // GetByString is identical to Get, except that it is specialized for strings.
 // This avoids an allocation from putting a string into an interface{}
 // to pass as an argument to Get.
 func GetByString(s string) *Value {
-	return get(key{s: s, isString: true})
+	return std.GetByString(s)
+}
+
+// Get is like the package-level Get, but uses in's table.
+//
+// If in was configured to canonicalize values, as with WithCanonicalize
+// or WithTransform, cmpVal is canonicalized first.
+func (in *Interner) Get(cmpVal interface{}) *Value {
+	return in.getValue(cmpVal, getCtx{})
+}
+
+// GetByString is like the package-level GetByString, but uses in's table.
+func (in *Interner) GetByString(s string) *Value {
+	return in.getByString(s, getCtx{})
+}
+
+// getByString is GetByString with ctx.
+func (in *Interner) getByString(s string, ctx getCtx) *Value {
+	if in.canonicalize != nil {
+		return in.getValue(s, ctx)
+	}
+	return in.getString(s, ctx)
+}
+
+// getCtx says to what a Value inserted by a Get belongs.
+type getCtx struct {
+	gen    uint32       // generation, or 0; see BeginGeneration
+	tenant *tenantState // or nil; see Interner.Tenant
+	parts  []*Value     // parts of a composite; see InternComponents
+	shared bool         // the key's memory is never freed; see Arena
+	noRef  bool         // take no reference; see Interner.String
+	part   bool         // a composite's part, not counted in Stats
+}
+
+// getValue returns the *Value for cmpVal, applying any options.
+// If it inserts a new Value, the Value belongs to ctx.
+func (in *Interner) getValue(cmpVal interface{}, ctx getCtx) *Value {
+	if in.canonicalize != nil {
+		cmpVal = in.canonicalize(cmpVal)
+	}
+	return in.getCanonical(cmpVal, ctx)
+}
+
+// getCanonical is getValue for an already canonicalized cmpVal.
+func (in *Interner) getCanonical(cmpVal interface{}, ctx getCtx) *Value {
+	if cmpVal == nil {
+		return nilValue
+	}
+	if s, ok := cmpVal.(string); ok {
+		return in.getString(s, ctx)
+	}
+	if err := in.checkPointers(cmpVal); err != nil {
+		panic(err)
+	}
+	if in.components {
+		cmpVal, ctx.parts = in.internComponents(cmpVal, ctx)
+	}
+	return in.get(key{cmpVal: cmpVal}, ctx)
 }
 
+// getString is getValue for strings, after any canonicalize option.
+func (in *Interner) getString(s string, ctx getCtx) *Value {
+	if in.transform != nil {
+		s = in.transform(s)
+	}
+	if in.foldASCII {
+		return in.getFolded(s, ctx)
+	}
+	return in.get(key{s: s, isString: true}, ctx)
+}
+
+// ErrUncomparable is returned (wrapped) by TryGet when passed a value
+// whose dynamic type cannot be compared with ==, such as a map, slice,
+// or func, or a struct or array containing one.
+var ErrUncomparable = errors.New("intern: value is not comparable")
+
+// TryGet is like Get, but returns an error instead of panicking if
+// cmpVal is not comparable.
+//
+// Get panics with a runtime error when hashing an uncomparable value,
+// so TryGet should be used when cmpVal comes from an untrusted caller.
+// It likewise returns an error, rather than panicking, for values
+// refused by RejectPointers.
+func TryGet(cmpVal interface{}) (*Value, error) {
+	return std.TryGet(cmpVal)
+}
+
+// TryGet is like the package-level TryGet, but uses in's table.
+func (in *Interner) TryGet(cmpVal interface{}) (*Value, error) {
+	if err := in.closedErr(); err != nil {
+		return nil, err
+	}
+	if err := checkComparable(cmpVal); err != nil {
+		return nil, err
+	}
+	if in.canonicalize != nil {
+		cmpVal = in.canonicalize(cmpVal)
+	}
+	if err := in.checkPointers(cmpVal); err != nil {
+		return nil, err
+	}
+	return in.getCanonical(cmpVal, getCtx{}), nil
+}
+
+// checkComparable reports an error if cmpVal would panic when used
+// as a map key.
+func checkComparable(cmpVal interface{}) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = fmt.Errorf("%w: %T", ErrUncomparable, cmpVal)
+		}
+	}()
+	// Comparing an interface value with itself panics exactly when
+	// hashing it would: its dynamic type (or something within it)
+	// is not comparable.
+	_ = cmpVal == cmpVal
+	return nil
+}
+
+// get returns the *Value for k, inserting it if needed as belonging
+// to ctx.
+func (in *Interner) get(k key, ctx getCtx) *Value {
+	if err := in.closedErr(); err != nil {
+		panic(err)
+	}
+	if in.isShort(k) {
+		return in.shortValue(k.s)
+	}
+	if in.isDisabled() || in.tooBig(k) {
+		return k.Value(in)
+	}
+	if v := in.constantGet(k, ctx); v != nil {
+		return v
+	}
+	if in.snapshots && in.shadow == nil {
+		if v := in.snapshotGet(k, ctx); v != nil {
+			return v
+		}
+	}
+	kh := in.hashKey(k)
+	switch {
+	case in.rcu:
+		if v := in.rcuGet(k, kh, ctx); v != nil {
+			return v
+		}
+	case in.seqlock:
+		if v := in.seqlockGet(k, kh, ctx); v != nil {
+			return v
+		}
+	}
+	if in.onEvent != nil {
+		defer in.flushEvents()
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	// Drain after the lookup below, so queued Values it finds are
+	// resurrected rather than removed and replaced.
+	defer in.drainFinalizedLocked()
+	if in.shadow != nil {
+		in.shadow.observe(k)
+		return k.Value(in)
+	}
+	if v := in.lookupLocked(k, kh, ctx); v != nil {
+		return v
+	}
+	if in.parent != nil {
+		if v := in.inherited(k, ctx); v != nil {
+			return v
+		}
+	}
+	if in.frozen {
+		return k.Value(in)
+	}
+	if !ctx.part {
+		in.misses++
+		if in.window != nil {
+			in.window.record(false)
+		}
+	}
+	if in.admit != nil && !in.admit.observe(k) {
+		return k.Value(in)
+	}
+	if t := ctx.tenant; t != nil && t.count >= t.quota {
+		t.rejected++
+		in.eventLocked(Event{Kind: EventRejected, Reason: "quota", Type: keyType(k), Size: int(keySize(k))})
+		return k.Value(in)
+	}
+	if k.isString && len(k.s) <= in.cloneMax && !ctx.shared {
+		k.s = cloneString(k.s)
+	}
+	v := in.insertLocked(k, kh, ctx)
+	if in.onEvent != nil {
+		in.insertedLocked()
+	}
+	if in.profile && in.valSafe == nil && in.wk == nil {
+		valuesProfile().Add(uintptr(unsafe.Pointer(v)), 1)
+	}
+	return v
+}
+
+// keyHash is the hash of a key used by an Interner's index.
+type keyHash struct {
+	tab uint64   // for tab
+	sum [16]byte // for hashMap, with HashKeys
+}
+
+// hashKey returns the hash of k for in's index.
+func (in *Interner) hashKey(k key) keyHash {
+	if k.isString {
+		return in.hashString(k.s)
+	}
+	if in.valSafe != nil || in.wk != nil {
+		return keyHash{}
+	}
+	if in.hashKeys {
+		return keyHash{sum: hashKey128(k)}
+	}
+	if in.byteArrays {
+		return keyHash{tab: hashByteArray(k.cmpVal)}
+	}
+	return keyHash{tab: tableHashValue(k.cmpVal)}
+}
+
+// hashString is hashKey for the string key s.
+// Unlike hashKey, it doesn't cause its argument to escape.
+func (in *Interner) hashString(s string) keyHash {
+	if in.valSafe != nil || in.wk != nil {
+		return keyHash{}
+	}
+	if in.hashKeys {
+		return keyHash{sum: hashString128(s)}
+	}
+	return keyHash{tab: tableHashString(s)}
+}
+
+// lookupLocked returns the existing *Value for k, whose hash is kh,
+// or nil, taking a reference to it for ctx in ManualCollect mode.
+// in.mu must be held.
+//
 // We play unsafe games that violate Go's rules (and assume a non-moving
 // collector). So we quiet Go here.
 // See the comment below Get for more implementation details.
+//
 //go:nocheckptr
-func get(k key) *Value {
-	mu.Lock()
-	defer mu.Unlock()
-
-	var v *Value
-	if valSafe != nil {
-		v = valSafe[k]
-	} else if addr, ok := valMap[k]; ok {
-		v = (*Value)((unsafe.Pointer)(addr))
-		v.resurrected = true
-	}
-	if v != nil {
+func (in *Interner) lookupLocked(k key, kh keyHash, ctx getCtx) *Value {
+	if v := in.constantGet(k, ctx); v != nil {
+		// Counted by the constants table.
+		return v
+	}
+	if in.valSafe != nil {
+		v := in.valSafe[k]
+		if v != nil {
+			in.recordHitLocked(k, v, ctx)
+		}
 		return v
 	}
-	v = k.Value()
-	if valSafe != nil {
-		valSafe[k] = v
+	if in.wk != nil {
+		v := in.wk.find(k)
+		if v != nil {
+			in.recordHitLocked(k, v, ctx)
+		}
+		return v
+	}
+	if addr := in.findLocked(k, kh); addr != 0 {
+		v := valueAt(addr)
+		if !v.resurrect() {
+			return nil
+		}
+		in.recordHitLocked(k, v, ctx)
+		return v
+	}
+	return nil
+}
+
+// recordHitLocked updates statistics for a Get of k that found v,
+// for ctx. in.mu must be held.
+func (in *Interner) recordHitLocked(k key, v *Value, ctx getCtx) {
+	if ctx.part {
+		return
+	}
+	in.hits++
+	in.bytesSaved += uint64(keySize(k))
+	if in.window != nil {
+		in.window.record(true)
+	}
+	if in.countHits {
+		v.addExt().hits++
+	}
+	if in.manual && !ctx.noRef {
+		v.addExt().refs++
+	}
+}
+
+// findLocked returns the address of the existing Value for k, whose
+// hash is kh, or zero. Unlike lookupLocked, it has no side effects:
+// it doesn't resurrect the Value, so the caller mustn't retain a
+// pointer to it. in.mu must be held, and in must not be in safe mode
+// or use WeakPointers.
+func (in *Interner) findLocked(k key, kh keyHash) uintptr {
+	if in.hashMap != nil {
+		addr := in.hashMap[kh.sum]
+		if addr != 0 && keyFor(valueAt(addr).cmpVal) != k {
+			return 0 // hash collision
+		}
+		return addr
+	}
+	if addr := in.tab.find(k, kh.tab); addr != 0 || in.flooded == nil {
+		return addr
+	}
+	return in.flooded[k]
+}
+
+// insertLocked adds a new *Value for k, whose hash is kh,
+// belonging to ctx.
+// k must not be present. in.mu must be held.
+func (in *Interner) insertLocked(k key, kh keyHash, ctx getCtx) *Value {
+	v := k.Value(in)
+	if in.hashMap != nil && in.valSafe == nil && in.wk == nil {
+		if _, ok := in.hashMap[kh.sum]; ok {
+			// A different value with the same hash is
+			// interned. Leave v out of the table.
+			return v
+		}
+	}
+	v.gen = ctx.gen
+	if ctx.parts != nil {
+		v.addExt().parts = ctx.parts
+		if in.manual {
+			// Parts are interned without references. The
+			// composite takes one to each, until it's removed.
+			for _, p := range ctx.parts {
+				p.addExt().refs++
+			}
+		}
+	}
+	if t := ctx.tenant; t != nil {
+		v.addExt().tenant = t
+		t.count++
+	}
+	if in.sites != nil {
+		in.sampleSiteLocked(v)
+	}
+	if in.lifetimes != nil && in.wk == nil {
+		in.lifetimes.inserted(v)
+	}
+	if in.sizes != nil && in.wk == nil {
+		in.sizes.observe(float64(keySize(k)))
+	}
+	if in.valSafe != nil {
+		in.valSafe[k] = v
+		if in.leaks != nil {
+			in.leaks.inserted(v, k)
+		}
+		if in.manual && !ctx.noRef {
+			v.addExt().refs = 1
+		}
+		return v
+	}
+	if in.wk != nil {
+		in.wk.insert(k, v)
+		return v
+	}
+	// SetFinalizer before uintptr conversion (theoretical concern;
+	// see https://github.com/go4org/intern/issues/13)
+	runtime.SetFinalizer(v, finalize)
+	addr := uintptr(unsafe.Pointer(v))
+	if in.hashMap != nil {
+		in.hashMap[kh.sum] = addr
+		if len(in.hashMap) > in.hashPeak {
+			in.hashPeak = len(in.hashMap)
+		}
 	} else {
-		// SetFinalizer before uintptr conversion (theoretical concern;
-		// see https://github.com/go4org/intern/issues/13)
-		runtime.SetFinalizer(v, finalize)
-		valMap[k] = uintptr(unsafe.Pointer(v))
+		in.tableInsertLocked(kh.tab, v)
 	}
 	return v
 }
 
 func finalize(v *Value) {
-	mu.Lock()
-	defer mu.Unlock()
-	if v.resurrected {
+	in := v.in
+	if in.deferFinalize && !in.queueFinalized(v) {
+		return
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.drainFinalizedLocked()
+	in.finalizeLocked(v)
+}
+
+// finalizeLocked removes v, whose finalizer has run, from in,
+// unless it was resurrected since. in.mu must be held.
+func (in *Interner) finalizeLocked(v *Value) {
+	if !atomic.CompareAndSwapUint32(&v.state, stateLive, stateDead) {
+		if atomic.LoadUint32(&v.state) == stateRemoved {
+			// Already removed explicitly. Let it go.
+			return
+		}
 		// We lost the race. Somebody resurrected it while we
 		// were about to finalize it. Try again next round.
-		v.resurrected = false
+		atomic.StoreUint32(&v.state, stateLive)
 		runtime.SetFinalizer(v, finalize)
+		in.rearms++
+		if v.rearms == 0 {
+			in.zombies++
+		}
+		if v.rearms < math.MaxUint16 {
+			v.rearms++
+		}
+		return
+	}
+	in.finalized++
+	in.evicted[in.nextEvict] = v.cmpVal
+	in.nextEvict = (in.nextEvict + 1) % numEvicted
+	if in.profile {
+		valuesProfile().Remove(uintptr(unsafe.Pointer(v)))
+	}
+	if v.rearms > 0 {
+		in.zombies--
+		in.finalizedRearms += uint64(v.rearms)
+	}
+	in.removeLocked(v)
+}
+
+// removeLocked removes v from in's index, if it's there.
+// Future Gets of v's underlying value will return a new Value.
+// Removing v again, as when its finalizer runs after Forget, does
+// nothing. in.mu must be held.
+func (in *Interner) removeLocked(v *Value) {
+	if !in.markRemovedLocked(v) {
+		return
+	}
+	if e := v.ext(); e != nil {
+		if e.tenant != nil {
+			e.tenant.count--
+			e.tenant = nil
+		}
+		if e.parts != nil && in.manual {
+			in.releasePartsLocked(e.parts)
+		}
+	}
+	if in.sites != nil {
+		in.unsampleSiteLocked(v)
+	}
+	if in.lifetimes != nil {
+		in.lifetimes.removed(v)
+	}
+	if in.sizes != nil && in.wk == nil {
+		in.sizes.unobserve(float64(keySize(keyFor(v.cmpVal))))
+	}
+	if in.ints != nil {
+		in.ints.remove(v.cmpVal.(uint64), uintptr(unsafe.Pointer(v)))
+		return
+	}
+	k := keyFor(v.cmpVal)
+	if in.valSafe != nil {
+		if in.valSafe[k] == v {
+			delete(in.valSafe, k)
+			if in.leaks != nil {
+				in.leaks.removed(v)
+			}
+		}
+		return
+	}
+	if in.wk != nil {
+		in.wk.remove(k, v)
 		return
 	}
-	delete(valMap, keyFor(v.cmpVal))
+	kh := in.hashKey(k)
+	addr := uintptr(unsafe.Pointer(v))
+	if in.hashMap != nil {
+		if in.hashMap[kh.sum] != addr {
+			return
+		}
+		delete(in.hashMap, kh.sum)
+		if len(in.hashMap)*shrinkRatio < in.hashPeak {
+			in.compactHashMapLocked()
+		}
+		return
+	}
+	if in.flooded[k] == addr {
+		delete(in.flooded, k)
+		return
+	}
+	in.tab.remove(kh.tab, addr)
+}
+
+// releasePartsLocked drops the references a removed composite held to
+// its parts, in ManualCollect mode. With Refcounted, parts left
+// unreferenced are removed too. in.mu must be held.
+func (in *Interner) releasePartsLocked(parts []*Value) {
+	for _, p := range parts {
+		e := p.ext()
+		if e == nil || e.refs <= 0 {
+			continue
+		}
+		e.refs--
+		if e.refs == 0 && in.refcounted {
+			in.removeLocked(p)
+		}
+	}
+}
+
+// markRemovedLocked marks v removed, and reports whether it wasn't
+// already. in.mu must be held.
+func (in *Interner) markRemovedLocked(v *Value) bool {
+	switch atomic.SwapUint32(&v.state, stateRemoved) {
+	case stateRemoved:
+		return false
+	case stateLive, stateResurrected:
+		// Removed explicitly, so its finalizer won't count it.
+		if v.rearms > 0 {
+			in.zombies--
+		}
+	}
+	return true
 }
 
 // Interning is simple if you don't require that unused values be
@@ -162,6 +884,12 @@ func finalize(v *Value) {
 // ensures that the unsafely created pointer is visible to the GC, and
 // will correctly prevent collection.
 //
+// The sentinel is part of a small atomic state machine (see
+// Value.resurrect), so marking a Value resurrected doesn't require
+// the Interner's lock: the finalizer atomically moves a Value from
+// live to dead, and once it has, a concurrent attempt to resurrect
+// it fails, so the Value is never handed out after its removal.
+//
 // This technique does mean that interned values that get reused take
 // at least 3 GC cycles to fully collect (1 to clear the sentinel, 1
 // to clean up the unsafe map, 1 to be actually deleted).
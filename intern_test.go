@@ -5,12 +5,17 @@
 package intern
 
 import (
+	"errors"
 	"fmt"
+	"path"
 	"runtime"
+	"strings"
 	"testing"
+	"unsafe"
 )
 
 func TestBasics(t *testing.T) {
+	skipIfRefcounted(t)
 	clearMap()
 	foo := Get("foo")
 	bar := Get("bar")
@@ -87,7 +92,7 @@ func TestBasics(t *testing.T) {
 		t.Error("nilEface pointers differ")
 	}
 
-	if n := mapLen(); n != 5 {
+	if n := mapLen(); n != 4 {
 		t.Errorf("map len = %d; want 4", n)
 	}
 
@@ -154,26 +159,26 @@ func BenchmarkStress(b *testing.B) {
 }
 
 func mapLen() int {
-	mu.Lock()
-	defer mu.Unlock()
-	return len(valMap)
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	return std.tab.count
 }
 
 func mapKeys() (keys []string) {
-	mu.Lock()
-	defer mu.Unlock()
-	for k := range valMap {
-		keys = append(keys, fmt.Sprint(k))
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	for _, s := range std.tab.slots {
+		if s.addr != empty && s.addr != tombstone {
+			keys = append(keys, fmt.Sprint(valueAt(s.addr).cmpVal))
+		}
 	}
 	return keys
 }
 
 func clearMap() {
-	mu.Lock()
-	defer mu.Unlock()
-	for k := range valMap {
-		delete(valMap, k)
-	}
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.tab.reset()
 }
 
 var (
@@ -197,3 +202,197 @@ func BenchmarkGetByString(b *testing.B) {
 		sink = v.Get().(string)
 	}
 }
+
+func TestTryGet(t *testing.T) {
+	type hasIface struct {
+		x interface{}
+	}
+	uncomparable := []interface{}{
+		map[string]int{},
+		[]byte("foo"),
+		func() {},
+		[1][]int{},
+		hasIface{x: []int{1}},
+		struct{ m map[int]int }{},
+	}
+	for _, v := range uncomparable {
+		got, err := TryGet(v)
+		if !errors.Is(err, ErrUncomparable) {
+			t.Errorf("TryGet(%T) error = %v; want ErrUncomparable", v, err)
+		}
+		if got != nil {
+			t.Errorf("TryGet(%T) = %v; want nil", v, got)
+		}
+	}
+
+	v, err := TryGet(hasIface{x: "foo"})
+	if err != nil {
+		t.Fatalf("TryGet(comparable) error = %v", err)
+	}
+	if v != Get(hasIface{x: "foo"}) {
+		t.Error("TryGet and Get returned different pointers")
+	}
+	if v, err := TryGet("foo"); err != nil || v != GetByString("foo") {
+		t.Errorf("TryGet(\"foo\") = %v, %v; want GetByString(\"foo\"), nil", v, err)
+	}
+}
+
+func TestInterner(t *testing.T) {
+	in := New()
+	if in.Get("foo") != in.GetByString("foo") {
+		t.Error("Interner.Get and GetByString differ")
+	}
+	if in.Get("foo") == Get("foo") {
+		t.Error("Interner shares Values with the default Interner")
+	}
+	if in.Get(1) != in.Get(1) {
+		t.Error("Interner.Get(1) pointers differ")
+	}
+}
+
+func TestWithTransform(t *testing.T) {
+	in := New(WithTransform(strings.TrimSpace), WithTransform(strings.ToUpper))
+	v := in.GetByString(" foo ")
+	if v != in.Get("FOO") || v != in.GetByString("foo") {
+		t.Error("transformed strings not interned together")
+	}
+	if got := v.Get(); got != "FOO" {
+		t.Errorf("Get = %q; want FOO", got)
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	in := New(CaseInsensitive())
+	long := strings.Repeat("x", 100)
+	for _, tt := range []struct{ a, b, want string }{
+		{"Content-Type", "content-type", "content-type"},
+		{"EXAMPLE.com", "example.COM", "example.com"},
+		{long + "Y", strings.ToUpper(long) + "y", long + "y"},
+	} {
+		a, b := in.GetByString(tt.a), in.Get(tt.b)
+		if a != b {
+			t.Errorf("%q and %q not interned together", tt.a, tt.b)
+		}
+		if got := a.Get(); got != tt.want {
+			t.Errorf("Get(%q) = %q; want %q", tt.a, got, tt.want)
+		}
+	}
+	if in.GetByString("Ünïcode") == in.GetByString("ünïcode") {
+		t.Error("non-ASCII case folded")
+	}
+
+	v := in.GetByString("x-forwarded-for")
+	allocs := testing.AllocsPerRun(100, func() {
+		if in.GetByString("X-Forwarded-For") != v {
+			t.Fatal("wrong value")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("case-insensitive hit allocated %v objects; want 0", allocs)
+	}
+}
+
+func TestWithCanonicalize(t *testing.T) {
+	type point struct{ X, Y int }
+	in := New(
+		WithCanonicalize(func(v interface{}) interface{} {
+			switch v := v.(type) {
+			case string:
+				return path.Clean(v)
+			case point:
+				if v.X < 0 {
+					v.X = -v.X
+				}
+				return v
+			}
+			return v
+		}),
+		CaseInsensitive(),
+	)
+	if in.GetByString("/a/B/../c/") != in.Get("/A/c") {
+		t.Error("canonicalized strings not interned together")
+	}
+	if got := in.Get("/a//b/").Get(); got != "/a/b" {
+		t.Errorf("Get = %q; want /a/b", got)
+	}
+	if in.Get(point{-1, 2}) != in.Get(point{1, 2}) {
+		t.Error("canonicalized points not interned together")
+	}
+}
+
+func TestHashKeys(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	in := New(HashKeys())
+	type pair struct{ a, b string }
+	a, b := in.GetByString("hashed"), in.Get(pair{"x", "y"})
+	if a != in.Get("hashed") || b != in.Get(pair{"x", "y"}) {
+		t.Error("hashed values not interned")
+	}
+	if a.Get() != "hashed" || b.Get() != (pair{"x", "y"}) {
+		t.Errorf("values = %v, %v", a.Get(), b.Get())
+	}
+	if n := in.Stats().Entries; n != 2 {
+		t.Errorf("Entries = %d; want 2", n)
+	}
+
+	// Fake a collision: file a different value under "collide"'s hash.
+	in.mu.Lock()
+	in.hashMap[hashKey128(keyFor("collide"))] = in.hashMap[hashKey128(keyFor("hashed"))]
+	in.mu.Unlock()
+	c := in.GetByString("collide")
+	if c.Get() != "collide" {
+		t.Errorf("colliding Get = %v; want collide", c.Get())
+	}
+	if c == in.GetByString("collide") {
+		t.Error("colliding value was interned")
+	}
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+}
+
+func TestHashKeysAllocs(t *testing.T) {
+	in := New(HashKeys(), CaseInsensitive())
+	v := in.GetByString(globalString)
+	allocs := testing.AllocsPerRun(100, func() {
+		if in.GetByString(globalString) != v || in.GetByString("NOT A CONSTANT") != v {
+			t.Fatal("wrong value")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("hashed hit allocated %v objects; want 0", allocs)
+	}
+}
+
+func TestResurrectState(t *testing.T) {
+	v := &Value{}
+	if !v.resurrect() || v.state != stateResurrected {
+		t.Fatalf("resurrect of live Value: state = %d", v.state)
+	}
+	if !v.resurrect() {
+		t.Error("second resurrect failed")
+	}
+	v.state = stateDead
+	if v.resurrect() {
+		t.Error("resurrect of dead Value succeeded")
+	}
+}
+
+func TestValueSize(t *testing.T) {
+	skipIfRefcounted(t)
+	// Fields only some options use live in a valueExt, so that the
+	// rest don't cost every Value.
+	if got := unsafe.Sizeof(Value{}); unsafe.Sizeof(uintptr(0)) == 8 && got > 48 {
+		t.Errorf("Value is %d bytes; want at most 48", got)
+	}
+	v := New().GetByString("ext")
+	if v.ext() != nil {
+		t.Error("plain Get allocated a valueExt")
+	}
+	v.SetAux(1)
+	if v.ext() == nil || v.Aux() != 1 {
+		t.Errorf("after SetAux, Aux = %v", v.Aux())
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package testhooks lets package interntest reach state in package
+// intern that isn't part of its exported API.
+package testhooks
+
+// Set by package intern at init.
+var (
+	// ResetDefault empties the default Interner.
+	ResetDefault func()
+	// SafeMode reports whether GO4_INTERN_SAFE_BUT_LEAKY is in effect.
+	SafeMode func() bool
+	// SafeBackend is an intern.Option that makes an Interner use
+	// the safe-but-leaky backend, whatever the environment.
+	SafeBackend interface{}
+)
@@ -0,0 +1,155 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internarrow converts between columns of intern.Symbols and
+// Apache Arrow dictionary-encoded string arrays.
+//
+// A Dictionary holds strings in the layout of the buffers of an Arrow
+// utf8 array, and indices are Arrow int32 dictionary indices, so they
+// can be handed to an Arrow library without copying, and without this
+// package depending on one.
+//
+// Only Symbols of string values can be exported.
+package internarrow // import "go4.org/intern/internarrow"
+
+import (
+	"fmt"
+	"math"
+
+	"go4.org/intern"
+)
+
+// A Dictionary is a list of strings laid out as the offsets and data
+// buffers of an Arrow utf8 array: string i is
+// Data[Offsets[i]:Offsets[i+1]].
+type Dictionary struct {
+	Offsets []int32 // len is Len()+1; Offsets[0] is 0
+	Data    []byte
+}
+
+// Len returns the number of strings in d.
+func (d Dictionary) Len() int {
+	if len(d.Offsets) == 0 {
+		return 0
+	}
+	return len(d.Offsets) - 1
+}
+
+// String returns string i of d.
+func (d Dictionary) String(i int) string {
+	return string(d.Data[d.Offsets[i]:d.Offsets[i+1]])
+}
+
+// builder builds a Dictionary.
+type builder struct {
+	d Dictionary
+}
+
+func (b *builder) add(sym intern.Symbol) error {
+	v := intern.ValueOf(sym)
+	if v == nil {
+		return fmt.Errorf("internarrow: Symbol %d not assigned", sym)
+	}
+	s, ok := v.Get().(string)
+	if !ok {
+		return fmt.Errorf("internarrow: Symbol %d has non-string value of type %T", sym, v.Get())
+	}
+	if len(b.d.Offsets) == 0 {
+		b.d.Offsets = append(b.d.Offsets, 0)
+	}
+	if b.d.Len() >= math.MaxInt32 {
+		return fmt.Errorf("internarrow: dictionary exceeds %d entries", math.MaxInt32)
+	}
+	if len(b.d.Data)+len(s) > math.MaxInt32 {
+		return fmt.Errorf("internarrow: dictionary data exceeds %d bytes", math.MaxInt32)
+	}
+	b.d.Data = append(b.d.Data, s...)
+	b.d.Offsets = append(b.d.Offsets, int32(len(b.d.Data)))
+	return nil
+}
+
+// Table returns a Dictionary of every assigned Symbol's value, in
+// which string i is the value of Symbol i+1. Using it as a column's
+// dictionary, the column's indices are just its Symbols less one; see
+// Indices.
+func Table() (Dictionary, error) {
+	var b builder
+	n := intern.NumSymbols()
+	for sym := intern.Symbol(1); int(sym) <= n; sym++ {
+		if err := b.add(sym); err != nil {
+			return Dictionary{}, err
+		}
+	}
+	return b.d, nil
+}
+
+// Indices returns the indices of col's Symbols in a Dictionary
+// returned by Table. The zero Symbol has index -1, which should be
+// marked null in the Arrow array. Symbols too large for an int32
+// index, which no Table can hold, are an error.
+func Indices(col []intern.Symbol) ([]int32, error) {
+	idx := make([]int32, len(col))
+	for i, sym := range col {
+		if int64(sym)-1 > math.MaxInt32 {
+			return nil, fmt.Errorf("internarrow: Symbol %d exceeds an int32 index", sym)
+		}
+		idx[i] = int32(int64(sym) - 1)
+	}
+	return idx, nil
+}
+
+// Encode dictionary-encodes col, returning a Dictionary of its
+// distinct values, in order of first appearance, and the index of each
+// of its elements in the Dictionary. The zero Symbol has index -1,
+// which should be marked null in the Arrow array.
+func Encode(col []intern.Symbol) (Dictionary, []int32, error) {
+	var b builder
+	idx := make([]int32, len(col))
+	seen := map[intern.Symbol]int32{}
+	for i, sym := range col {
+		if sym == 0 {
+			idx[i] = -1
+			continue
+		}
+		j, ok := seen[sym]
+		if !ok {
+			if err := b.add(sym); err != nil {
+				return Dictionary{}, nil, err
+			}
+			j = int32(len(seen))
+			seen[sym] = j
+		}
+		idx[i] = j
+	}
+	return b.d, idx, nil
+}
+
+// Import interns each of d's strings, assigning it a Symbol if it
+// doesn't have one, and returns their Symbols, indexed like d. It can
+// prewarm the symbol table with a dictionary read from Arrow data.
+func Import(d Dictionary) []intern.Symbol {
+	syms := make([]intern.Symbol, d.Len())
+	for i := range syms {
+		syms[i] = intern.SymbolOfString(d.String(i))
+	}
+	return syms
+}
+
+// Decode returns the Symbols of a column dictionary-encoded as d and
+// indices, as Import assigns them. Negative indices, marking nulls,
+// give the zero Symbol.
+func Decode(d Dictionary, indices []int32) ([]intern.Symbol, error) {
+	syms := Import(d)
+	col := make([]intern.Symbol, len(indices))
+	for i, j := range indices {
+		switch {
+		case j < 0:
+		case int(j) < len(syms):
+			col[i] = syms[j]
+		default:
+			return nil, fmt.Errorf("internarrow: index %d out of range for dictionary of %d", j, len(syms))
+		}
+	}
+	return col, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internarrow
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go4.org/intern"
+)
+
+// TestTable runs first, before other tests assign Symbols to non-string
+// values.
+func TestTable(t *testing.T) {
+	sym := intern.SymbolOfString("table-entry")
+	d, err := Table()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != intern.NumSymbols() {
+		t.Errorf("Len = %d; want %d", d.Len(), intern.NumSymbols())
+	}
+	idx, err := Indices([]intern.Symbol{sym, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.String(int(idx[0])) != "table-entry" || idx[1] != -1 {
+		t.Errorf("Indices = %v", idx)
+	}
+	if _, err := Indices([]intern.Symbol{math.MaxInt32 + 2}); err == nil {
+		t.Error("Indices of a Symbol beyond int32 succeeded")
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	nz, us := intern.SymbolOfString("NZ"), intern.SymbolOfString("US")
+	col := []intern.Symbol{nz, us, 0, nz}
+	d, idx, err := Encode(col)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{0, 1, -1, 0}; !reflect.DeepEqual(idx, want) {
+		t.Errorf("indices = %v; want %v", idx, want)
+	}
+	if want := (Dictionary{Offsets: []int32{0, 2, 4}, Data: []byte("NZUS")}); !reflect.DeepEqual(d, want) {
+		t.Errorf("Dictionary = %+v; want %+v", d, want)
+	}
+	got, err := Decode(d, idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, col) {
+		t.Errorf("Decode = %v; want %v", got, col)
+	}
+	if _, err := Decode(d, []int32{2}); err == nil {
+		t.Error("Decode of out of range index succeeded")
+	}
+	_, _, err = Encode([]intern.Symbol{intern.SymbolOf(42)})
+	if err == nil || !strings.Contains(err.Error(), "non-string") {
+		t.Errorf("Encode of non-string Symbol: err = %v", err)
+	}
+	unassigned := intern.Symbol(intern.NumSymbols() + 1)
+	_, _, err = Encode([]intern.Symbol{unassigned})
+	if err == nil || !strings.Contains(err.Error(), "not assigned") {
+		t.Errorf("Encode of unassigned Symbol: err = %v", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	d := Dictionary{Offsets: []int32{0, 5, 9}, Data: []byte("arrowtest")}
+	syms := Import(d)
+	if len(syms) != 2 || syms[0] != intern.SymbolOfString("arrow") || syms[1] != intern.SymbolOfString("test") {
+		t.Errorf("Import = %v", syms)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package interncsv reads CSV records, interning the values of
+// selected columns with package intern.
+//
+// Loading a file with low-cardinality columns, such as the dimension
+// columns of a fact table, otherwise allocates a new copy of each
+// repeated value in every record.
+package interncsv // import "go4.org/intern/interncsv"
+
+import (
+	"encoding/csv"
+	"io"
+
+	"go4.org/intern"
+)
+
+// A Reader reads records from a csv.Reader, interning the values of
+// its columns.
+//
+// Each column has its own Interner, so that Stats can report how well
+// interning is working for it. A column with many misses is probably
+// not worth interning.
+type Reader struct {
+	r    *csv.Reader
+	cols map[int]*intern.Interner
+}
+
+// NewReader returns a Reader reading from r that interns the values
+// of the given columns, numbered from zero.
+func NewReader(r *csv.Reader, columns ...int) *Reader {
+	cr := &Reader{r: r, cols: map[int]*intern.Interner{}}
+	for _, c := range columns {
+		cr.cols[c] = intern.New()
+	}
+	return cr
+}
+
+// Read reads one record from r, as csv.Reader.Read does, and interns
+// the values of r's columns.
+func (r *Reader) Read() ([]string, error) {
+	rec, err := r.r.Read()
+	if err != nil {
+		return rec, err
+	}
+	for c, in := range r.cols {
+		if c < len(rec) {
+			rec[c] = in.String(rec[c])
+		}
+	}
+	return rec, nil
+}
+
+// ReadAll reads all the remaining records from r, as
+// csv.Reader.ReadAll does, and interns the values of r's columns.
+func (r *Reader) ReadAll() ([][]string, error) {
+	var recs [][]string
+	for {
+		rec, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				return recs, nil
+			}
+			return recs, err
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// Stats returns the statistics of the Interner for column c, and
+// whether r interns c. Its Hits are the values read that had been read
+// before, and its Misses those that hadn't.
+func (r *Reader) Stats(c int) (intern.Stats, bool) {
+	in, ok := r.cols[c]
+	if !ok {
+		return intern.Stats{}, false
+	}
+	return in.Stats(), true
+}
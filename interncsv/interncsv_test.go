@@ -0,0 +1,59 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interncsv
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+const data = `id,country,status
+1,NZ,active
+2,US,active
+3,NZ,closed
+4,NZ,active
+`
+
+func TestReader(t *testing.T) {
+	r := NewReader(csv.NewReader(strings.NewReader(data)), 1, 2)
+	recs, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 5 {
+		t.Fatalf("got %d records; want 5", len(recs))
+	}
+	if stringData(recs[1][1]) != stringData(recs[4][1]) || stringData(recs[1][2]) != stringData(recs[2][2]) {
+		t.Error("column values not interned")
+	}
+	if stringData(recs[1][0]) == stringData(recs[4][0]) {
+		t.Error("unselected column interned")
+	}
+
+	st, ok := r.Stats(1)
+	if !ok || st.Hits != 2 || st.Misses != 3 {
+		t.Errorf("country Stats = %+v, %v; want 2 hits, 3 misses", st, ok)
+	}
+	if _, ok := r.Stats(0); ok {
+		t.Error("Stats of unselected column ok")
+	}
+}
+
+func TestReaderShortRecord(t *testing.T) {
+	cr := csv.NewReader(strings.NewReader("a\nb,c\n"))
+	cr.FieldsPerRecord = -1
+	r := NewReader(cr, 1)
+	for i := 0; i < 2; i++ {
+		if _, err := r.Read(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internhook provides string-interning callbacks, of the
+// func([]byte) string form that binary codecs such as CBOR and
+// MessagePack decoders accept for turning decoded bytes into strings.
+//
+// Plugging a Func into a decoder's string hook makes it intern the
+// strings it decodes, without forking the codec. For example, with a
+// decoder that takes a hook in its options:
+//
+//	opts.StringHook = internhook.Limit(64, internhook.Bytes)
+//
+// Because the hooks only allocate for strings not already interned,
+// they also save the allocation a decoder would otherwise make for
+// each repeated string.
+package internhook // import "go4.org/intern/internhook"
+
+import "go4.org/intern"
+
+// A Func returns the string with the contents of a decoded byte slice.
+// It must not retain the slice.
+type Func func(b []byte) string
+
+// Bytes is a Func returning the canonical string for b in the default
+// Interner, as intern.Bytes does.
+func Bytes(b []byte) string {
+	return intern.Bytes(b)
+}
+
+// For returns a Func interning strings in in.
+func For(in *intern.Interner) Func {
+	return in.Bytes
+}
+
+// Limit returns a Func that calls f for slices no longer than n
+// bytes, and converts longer ones, which are unlikely to repeat, to
+// strings without interning them.
+func Limit(n int, f Func) Func {
+	return func(b []byte) string {
+		if len(b) > n {
+			return string(b)
+		}
+		return f(b)
+	}
+}
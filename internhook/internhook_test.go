@@ -0,0 +1,44 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internhook
+
+import (
+	"testing"
+	"unsafe"
+
+	"go4.org/intern"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+func TestHooks(t *testing.T) {
+	buf := []byte("status")
+	var f Func = Bytes
+	a := f(buf)
+	buf[0] = 'S' // the hook mustn't retain buf
+	if a != "status" || stringData(a) != stringData(f([]byte("status"))) {
+		t.Errorf("Bytes = %q; not interned", a)
+	}
+
+	in := intern.New()
+	g := For(in)
+	if stringData(g([]byte("x"))) != stringData(in.String("x")) {
+		t.Error("For doesn't use its Interner")
+	}
+
+	l := Limit(3, g)
+	if stringData(l([]byte("abc"))) != stringData(l([]byte("abc"))) {
+		t.Error("Limit doesn't intern short strings")
+	}
+	if stringData(l([]byte("abcd"))) == stringData(l([]byte("abcd"))) {
+		t.Error("Limit interns long strings")
+	}
+	n := testing.AllocsPerRun(100, func() { l(buf[:3]) })
+	if n > 0 {
+		t.Errorf("allocs = %v; want 0", n)
+	}
+}
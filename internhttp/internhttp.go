@@ -0,0 +1,186 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internhttp interns HTTP header names and common header
+// values with package intern.
+//
+// Proxies and servers that retain many requests' headers otherwise
+// hold a copy of "Content-Type", "gzip", and the like for each one.
+// MediaType and LanguageTag canonicalize and intern the small
+// vocabularies of media types and language tags found in header
+// values. The package's tables are prewarmed with the standard header
+// names and common values, which are never collected.
+package internhttp // import "go4.org/intern/internhttp"
+
+import (
+	"net/http"
+	"net/textproto"
+
+	"go4.org/intern"
+)
+
+// MaxValueLen is the length, in bytes, of the longest header value
+// that Value interns. Longer values, such as cookies and tokens, are
+// unlikely to repeat and are left alone.
+const MaxValueLen = 64
+
+// names interns header names case-insensitively, as their canonical
+// forms.
+var names = intern.New(intern.WithTransform(textproto.CanonicalMIMEHeaderKey))
+
+// prewarmed holds the Values of standardNames and commonValues, so
+// that they're never collected.
+var prewarmed []*intern.Value
+
+func init() {
+	for _, n := range standardNames {
+		prewarmed = append(prewarmed, names.GetByString(n))
+	}
+	for _, v := range commonValues {
+		prewarmed = append(prewarmed, intern.GetByString(v))
+	}
+}
+
+// Name returns the canonical form of the header name s, as by
+// textproto.CanonicalMIMEHeaderKey, interned. Names differing only in
+// case share one string.
+func Name(s string) string {
+	return names.GetByString(s).Get().(string)
+}
+
+// Value returns the header value s, interned with intern.GetByString
+// if it's no longer than MaxValueLen. Longer values are returned
+// unchanged.
+func Value(s string) string {
+	if len(s) > MaxValueLen {
+		return s
+	}
+	return intern.GetByString(s).Get().(string)
+}
+
+// Canonicalize replaces h's keys with their interned canonical forms,
+// as by Name, and its short values with interned copies, as by Value.
+// Values of keys that differ only in case are merged, in the order
+// the keys are visited.
+func Canonicalize(h http.Header) {
+	for k, vs := range h {
+		for i, v := range vs {
+			vs[i] = Value(v)
+		}
+		ck := Name(k)
+		if ck == k {
+			h[ck] = vs // replace key's string with the interned one
+			continue
+		}
+		delete(h, k)
+		h[ck] = append(h[ck], vs...)
+	}
+}
+
+// standardNames are the header names that Name is prewarmed with:
+// those registered for HTTP by the RFCs and in wide use.
+var standardNames = []string{
+	"Accept",
+	"Accept-Charset",
+	"Accept-Encoding",
+	"Accept-Language",
+	"Accept-Ranges",
+	"Access-Control-Allow-Credentials",
+	"Access-Control-Allow-Headers",
+	"Access-Control-Allow-Methods",
+	"Access-Control-Allow-Origin",
+	"Access-Control-Expose-Headers",
+	"Access-Control-Max-Age",
+	"Access-Control-Request-Headers",
+	"Access-Control-Request-Method",
+	"Age",
+	"Allow",
+	"Alt-Svc",
+	"Authorization",
+	"Cache-Control",
+	"Connection",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Length",
+	"Content-Location",
+	"Content-Range",
+	"Content-Security-Policy",
+	"Content-Type",
+	"Cookie",
+	"Date",
+	"Etag",
+	"Expect",
+	"Expires",
+	"Forwarded",
+	"From",
+	"Host",
+	"If-Match",
+	"If-Modified-Since",
+	"If-None-Match",
+	"If-Range",
+	"If-Unmodified-Since",
+	"Keep-Alive",
+	"Last-Modified",
+	"Link",
+	"Location",
+	"Max-Forwards",
+	"Origin",
+	"Pragma",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Range",
+	"Referer",
+	"Retry-After",
+	"Server",
+	"Set-Cookie",
+	"Strict-Transport-Security",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"User-Agent",
+	"Vary",
+	"Via",
+	"Warning",
+	"Www-Authenticate",
+	"X-Content-Type-Options",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Frame-Options",
+	"X-Request-Id",
+}
+
+// commonValues are the header values that Value is prewarmed with.
+var commonValues = []string{
+	"*",
+	"0",
+	"application/json",
+	"application/octet-stream",
+	"application/x-www-form-urlencoded",
+	"br",
+	"bytes",
+	"chunked",
+	"close",
+	"deflate",
+	"gzip",
+	"gzip, deflate",
+	"gzip, deflate, br",
+	"identity",
+	"keep-alive",
+	"max-age=0",
+	"no-cache",
+	"no-store",
+	"nosniff",
+	"private",
+	"public",
+	"text/html",
+	"text/html; charset=utf-8",
+	"text/plain",
+	"text/plain; charset=utf-8",
+	"trailers",
+	"upgrade",
+	"websocket",
+}
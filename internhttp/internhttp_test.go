@@ -0,0 +1,76 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internhttp
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+func TestName(t *testing.T) {
+	a := Name(string([]byte("content-type")))
+	b := Name(string([]byte("CONTENT-TYPE")))
+	if a != "Content-Type" || b != a {
+		t.Errorf("Name = %q, %q; want Content-Type", a, b)
+	}
+	if stringData(a) != stringData(b) {
+		t.Error("names differing in case not shared")
+	}
+	if got := Name("x-custom-thing"); got != "X-Custom-Thing" {
+		t.Errorf("Name = %q", got)
+	}
+}
+
+func TestValue(t *testing.T) {
+	a := Value(string([]byte("gzip")))
+	if stringData(a) != stringData(Value("gzip")) {
+		t.Error("values not shared")
+	}
+	long := strings.Repeat("x", MaxValueLen+1)
+	if stringData(Value(long)) != stringData(long) {
+		t.Error("long value interned")
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	h := http.Header{
+		"content-type": {string([]byte("text/plain"))},
+		"Content-Type": {"text/html"},
+		"Accept":       {"*"},
+	}
+	Canonicalize(h)
+	if len(h) != 2 {
+		t.Fatalf("got %d keys; want 2: %v", len(h), h)
+	}
+	vs := h["Content-Type"]
+	if len(vs) != 2 {
+		t.Fatalf("Content-Type = %q; want two values", vs)
+	}
+	for _, v := range vs {
+		if stringData(v) != stringData(Value(v)) {
+			t.Errorf("value %q not interned", v)
+		}
+	}
+	if !reflect.DeepEqual(h["Accept"], []string{"*"}) {
+		t.Errorf("Accept = %q", h["Accept"])
+	}
+}
+
+func TestPrewarmed(t *testing.T) {
+	n := testing.AllocsPerRun(100, func() {
+		Name("Content-Type")
+		Value("gzip")
+	})
+	if n > 0 {
+		t.Errorf("allocs = %v; want 0", n)
+	}
+}
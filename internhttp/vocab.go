@@ -0,0 +1,124 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internhttp
+
+import (
+	"mime"
+	"strings"
+
+	"go4.org/intern"
+)
+
+var (
+	// mediaTypes interns media types in canonical form.
+	mediaTypes = intern.New(intern.WithTransform(canonicalMediaType))
+
+	// languageTags interns language tags in canonical form.
+	languageTags = intern.New(intern.WithTransform(canonicalLanguageTag))
+)
+
+func init() {
+	for _, s := range commonMediaTypes {
+		prewarmed = append(prewarmed, mediaTypes.GetByString(s))
+	}
+	for _, s := range commonLanguageTags {
+		prewarmed = append(prewarmed, languageTags.GetByString(s))
+	}
+}
+
+// MediaType returns the media type s, such as the value of a
+// Content-Type header, in canonical form and interned. The type,
+// subtype, and parameter names are lowercased and the parameters
+// formatted uniformly, so "Text/HTML;Charset=UTF-8" becomes
+// "text/html; charset=UTF-8". A value that can't be parsed as a
+// media type is interned unchanged.
+func MediaType(s string) string {
+	return mediaTypes.GetByString(s).Get().(string)
+}
+
+// LanguageTag returns the BCP 47 language tag s, such as an element
+// of an Accept-Language header, in canonical form and interned:
+// the language and other subtags in lower case, scripts in title case,
+// and regions in upper case, so "EN-us" becomes "en-US" and
+// "zh_hant_tw" becomes "zh-Hant-TW".
+func LanguageTag(s string) string {
+	return languageTags.GetByString(s).Get().(string)
+}
+
+func canonicalMediaType(s string) string {
+	mt, params, err := mime.ParseMediaType(s)
+	if err != nil {
+		return s
+	}
+	if f := mime.FormatMediaType(mt, params); f != "" {
+		return f
+	}
+	return s
+}
+
+func canonicalLanguageTag(s string) string {
+	subtags := strings.Split(strings.Replace(s, "_", "-", -1), "-")
+	for i, st := range subtags {
+		st = strings.ToLower(st)
+		switch {
+		case i == 0:
+		case len(subtags[i-1]) == 1:
+			// After a singleton, such as the x of a private use
+			// section, subtags keep their lower case forms.
+			for j := i; j < len(subtags); j++ {
+				subtags[j] = strings.ToLower(subtags[j])
+			}
+			return strings.Join(subtags, "-")
+		case len(st) == 2:
+			st = strings.ToUpper(st) // region
+		case len(st) == 4 && isAlpha(st):
+			st = strings.ToUpper(st[:1]) + st[1:] // script
+		}
+		subtags[i] = st
+	}
+	return strings.Join(subtags, "-")
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// commonMediaTypes are the media types that MediaType is prewarmed
+// with.
+var commonMediaTypes = []string{
+	"application/javascript",
+	"application/json",
+	"application/octet-stream",
+	"application/pdf",
+	"application/x-www-form-urlencoded",
+	"application/xml",
+	"image/gif",
+	"image/jpeg",
+	"image/png",
+	"image/svg+xml",
+	"image/webp",
+	"multipart/form-data",
+	"text/css",
+	"text/html",
+	"text/html; charset=utf-8",
+	"text/javascript",
+	"text/plain",
+	"text/plain; charset=utf-8",
+	"text/xml",
+}
+
+// commonLanguageTags are the language tags that LanguageTag is
+// prewarmed with.
+var commonLanguageTags = []string{
+	"ar", "de", "de-DE", "en", "en-GB", "en-US", "es", "es-ES", "fr",
+	"fr-FR", "hi", "it", "ja", "ja-JP", "ko", "ko-KR", "nl", "pl", "pt",
+	"pt-BR", "ru", "sv", "tr", "zh", "zh-CN", "zh-Hans", "zh-Hant",
+	"zh-TW",
+}
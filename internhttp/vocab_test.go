@@ -0,0 +1,42 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internhttp
+
+import "testing"
+
+func TestMediaType(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"text/HTML", "text/html"},
+		{"Text/HTML;Charset=UTF-8", "text/html; charset=UTF-8"},
+		{"application/json", "application/json"},
+		{"not a media type;;", "not a media type;;"},
+	} {
+		if got := MediaType(tt.in); got != tt.want {
+			t.Errorf("MediaType(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+	a := MediaType(string([]byte("TEXT/plain")))
+	if stringData(a) != stringData(MediaType("text/plain")) {
+		t.Error("media types not shared")
+	}
+}
+
+func TestLanguageTag(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"EN-us", "en-US"},
+		{"en", "en"},
+		{"zh_hant_tw", "zh-Hant-TW"},
+		{"es-419", "es-419"},
+		{"en-US-x-TWAIN", "en-US-x-twain"},
+		{"SGN-BE-FR", "sgn-BE-FR"},
+	} {
+		if got := LanguageTag(tt.in); got != tt.want {
+			t.Errorf("LanguageTag(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+	if stringData(LanguageTag("EN-us")) != stringData(LanguageTag("en-US")) {
+		t.Error("language tags not shared")
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internjson decodes JSON, interning object keys and short
+// string values with package intern as it goes.
+//
+// JSON documents tend to repeat the same keys, and often the same
+// short values, many times over. Decoding with this package means a
+// program retaining the decoded values holds one copy of each.
+package internjson // import "go4.org/intern/internjson"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"go4.org/intern"
+)
+
+// DefaultMaxValueLen is the length of the longest string value that
+// a Decoder with a zero MaxValueLen interns.
+const DefaultMaxValueLen = 64
+
+// A Decoder reads and decodes JSON values from an input stream,
+// like a json.Decoder, interning object keys and short string values.
+type Decoder struct {
+	// MaxValueLen is the length, in bytes, of the longest string
+	// value (as opposed to object key) that is interned. Longer
+	// strings are unlikely to repeat and are left alone.
+	// If zero, DefaultMaxValueLen is used. If negative, only object
+	// keys are interned.
+	MaxValueLen int
+
+	d         *json.Decoder
+	useNumber bool
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{d: json.NewDecoder(r)}
+}
+
+// UseNumber causes the Decoder to unmarshal a number into an
+// interface{} as a json.Number instead of as a float64.
+func (d *Decoder) UseNumber() {
+	d.useNumber = true
+	d.d.UseNumber()
+}
+
+// DisallowUnknownFields is like json.Decoder.DisallowUnknownFields.
+func (d *Decoder) DisallowUnknownFields() { d.d.DisallowUnknownFields() }
+
+// More reports whether there is another element in the current array
+// or object being parsed.
+func (d *Decoder) More() bool { return d.d.More() }
+
+// Decode reads the next JSON-encoded value from its input and stores
+// it in the value pointed to by v, as json.Decoder.Decode does.
+//
+// If v is a *interface{}, the value is built from the token stream,
+// with keys and strings interned as they're read. Otherwise, v is
+// decoded by encoding/json and the strings it contains are then
+// replaced with their interned equivalents.
+func (d *Decoder) Decode(v interface{}) error {
+	if p, ok := v.(*interface{}); ok && p != nil {
+		tok, err := d.d.Token()
+		if err != nil {
+			return err
+		}
+		x, err := d.value(tok)
+		if err != nil {
+			return err
+		}
+		*p = x
+		return nil
+	}
+	if err := d.d.Decode(v); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		d.internAll(rv.Elem())
+	}
+	return nil
+}
+
+// Unmarshal is like json.Unmarshal, but interns object keys and short
+// string values as a Decoder with default settings does.
+func Unmarshal(data []byte, v interface{}) error {
+	d := NewDecoder(bytes.NewReader(data))
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	if _, err := d.d.Token(); err != io.EOF {
+		return fmt.Errorf("internjson: invalid data after top-level value")
+	}
+	return nil
+}
+
+// value returns the interface{} value starting with tok.
+func (d *Decoder) value(tok json.Token) (interface{}, error) {
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			m := map[string]interface{}{}
+			for d.d.More() {
+				ktok, err := d.d.Token()
+				if err != nil {
+					return nil, err
+				}
+				k, ok := ktok.(string)
+				if !ok {
+					return nil, fmt.Errorf("internjson: unexpected object key %v", ktok)
+				}
+				vtok, err := d.d.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := d.value(vtok)
+				if err != nil {
+					return nil, err
+				}
+				m[intern.GetByString(k).Get().(string)] = v
+			}
+			_, err := d.d.Token() // '}'
+			return m, err
+		case '[':
+			s := []interface{}{}
+			for d.d.More() {
+				tok, err := d.d.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := d.value(tok)
+				if err != nil {
+					return nil, err
+				}
+				s = append(s, v)
+			}
+			_, err := d.d.Token() // ']'
+			return s, err
+		}
+		return nil, fmt.Errorf("internjson: unexpected %v", t)
+	case string:
+		return d.internValue(t), nil
+	}
+	return tok, nil
+}
+
+// internValue returns the interned form of s, if s is short
+// enough to intern.
+func (d *Decoder) internValue(s string) string {
+	max := d.MaxValueLen
+	if max == 0 {
+		max = DefaultMaxValueLen
+	}
+	if len(s) > max {
+		return s
+	}
+	return intern.GetByString(s).Get().(string)
+}
+
+var stringType = reflect.TypeOf("")
+
+// internAll replaces the strings within v, which must be settable,
+// with their interned forms.
+func (d *Decoder) internAll(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Type() == stringType {
+			v.SetString(d.internValue(v.String()))
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			d.internAll(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		e := v.Elem()
+		c := reflect.New(e.Type()).Elem()
+		c.Set(e)
+		d.internAll(c)
+		v.Set(c)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.CanSet() {
+				d.internAll(f)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			d.internAll(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		internKeys := v.Type().Key() == stringType
+		iter := v.MapRange()
+		for iter.Next() {
+			k, e := iter.Key(), iter.Value()
+			c := reflect.New(e.Type()).Elem()
+			c.Set(e)
+			d.internAll(c)
+			if internKeys {
+				// Assigning to an existing string key
+				// replaces the key as well as the value.
+				k = reflect.ValueOf(intern.GetByString(k.String()).Get().(string))
+			}
+			v.SetMapIndex(k, c)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internjson
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// sameString reports whether a and b share their backing bytes.
+func sameString(a, b string) bool {
+	return (*reflect.StringHeader)(unsafe.Pointer(&a)).Data == (*reflect.StringHeader)(unsafe.Pointer(&b)).Data
+}
+
+func TestDecodeInterface(t *testing.T) {
+	long := strings.Repeat("z", DefaultMaxValueLen+1)
+	d := NewDecoder(strings.NewReader(`
+		{"status": "ok", "list": ["ok", 1, true, null], "long": "` + long + `"}
+		{"status": "ok", "long": "` + long + `"}
+	`))
+	var v1, v2 interface{}
+	if err := d.Decode(&v1); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Decode(&v2); err != nil {
+		t.Fatal(err)
+	}
+	m1, m2 := v1.(map[string]interface{}), v2.(map[string]interface{})
+	want := map[string]interface{}{
+		"status": "ok",
+		"list":   []interface{}{"ok", 1.0, true, nil},
+		"long":   long,
+	}
+	if !reflect.DeepEqual(m1, want) {
+		t.Errorf("first value = %v; want %v", m1, want)
+	}
+
+	var k1, k2 string
+	for k := range m1 {
+		if k == "status" {
+			k1 = k
+		}
+	}
+	for k := range m2 {
+		if k == "status" {
+			k2 = k
+		}
+	}
+	if !sameString(k1, k2) {
+		t.Error("object keys not interned")
+	}
+	if !sameString(m1["status"].(string), m2["status"].(string)) {
+		t.Error("short string values not interned")
+	}
+	if !sameString(m1["status"].(string), m1["list"].([]interface{})[0].(string)) {
+		t.Error("short string array elements not interned")
+	}
+	if sameString(m1["long"].(string), m2["long"].(string)) {
+		t.Error("long string values interned")
+	}
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	type record struct {
+		Name   string
+		Tags   []string
+		Labels map[string]string
+		Extra  interface{}
+		hidden string
+	}
+	const doc = `{"Name": "n", "Tags": ["t"], "Labels": {"k": "v"}, "Extra": {"x": "y"}}`
+	var r1, r2 record
+	if err := Unmarshal([]byte(doc), &r1); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unmarshal([]byte(doc), &r2); err != nil {
+		t.Fatal(err)
+	}
+	if !sameString(r1.Name, r2.Name) || !sameString(r1.Tags[0], r2.Tags[0]) {
+		t.Error("struct strings not interned")
+	}
+	if !sameString(r1.Labels["k"], r2.Labels["k"]) {
+		t.Error("map values not interned")
+	}
+	var k1, k2 string
+	for k := range r1.Labels {
+		k1 = k
+	}
+	for k := range r2.Labels {
+		k2 = k
+	}
+	if !sameString(k1, k2) {
+		t.Error("map keys not interned")
+	}
+	e1, e2 := r1.Extra.(map[string]interface{}), r2.Extra.(map[string]interface{})
+	if !sameString(e1["x"].(string), e2["x"].(string)) {
+		t.Error("strings within interface not interned")
+	}
+}
+
+func TestUnmarshalTrailingData(t *testing.T) {
+	var v interface{}
+	if err := Unmarshal([]byte(`{} {}`), &v); err == nil {
+		t.Error("Unmarshal succeeded with trailing data")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internpath interns slash-separated paths component by
+// component, with package intern.
+//
+// A Path is a single pointer to an interned (parent, name) pair, so
+// paths sharing a prefix share its nodes, and each component name,
+// such as "usr" or "lib", is stored once however many paths contain
+// it. Paths can be compared with == and used as map keys.
+package internpath // import "go4.org/intern/internpath"
+
+import (
+	"strings"
+
+	"go4.org/intern"
+)
+
+// A Path is an interned, cleaned, slash-separated path.
+// The zero Path is ".", the empty relative path.
+type Path struct {
+	v *intern.Value // of intern.Pair{parent *intern.Value or nil, name string}
+}
+
+// nodes interns Paths' nodes. With InternComponents, the names of
+// all nodes share one copy of each string.
+var nodes = intern.New(intern.InternComponents())
+
+// root is the name of the first node of an absolute path.
+const root = "/"
+
+// New returns the Path for p, after cleaning it as path.Clean does.
+func New(p string) Path {
+	var start Path
+	if strings.HasPrefix(p, "/") {
+		start = Path{child(nil, root)}
+	}
+	return start.Join(p)
+}
+
+func child(parent *intern.Value, name string) *intern.Value {
+	var a interface{}
+	if parent != nil {
+		a = parent
+	}
+	return nodes.GetPair(a, name)
+}
+
+// node returns p's parent node, or nil if it has none, and its name.
+func (p Path) node() (parent *intern.Value, name string) {
+	pr := p.v.Get().(intern.Pair)
+	parent, _ = pr.A.(*intern.Value)
+	return parent, pr.B.(string)
+}
+
+// Join returns p with each of elem appended, as by path.Join, but
+// without converting p to a string. Like path.Join, it cleans the
+// result.
+func (p Path) Join(elem ...string) Path {
+	for _, e := range elem {
+		for _, name := range strings.Split(e, "/") {
+			p = p.join(name)
+		}
+	}
+	return p
+}
+
+func (p Path) join(name string) Path {
+	switch name {
+	case "", ".":
+		return p
+	case "..":
+		if p.v != nil {
+			if _, n := p.node(); n == root {
+				return p // "/.." is "/"
+			} else if n != ".." {
+				return p.Dir()
+			}
+		}
+	}
+	return Path{child(p.v, name)}
+}
+
+// Dir returns all but the last element of p. The Dir of "/" is "/",
+// and that of a single relative element is ".".
+func (p Path) Dir() Path {
+	if p.v == nil {
+		return p
+	}
+	parent, name := p.node()
+	if name == root {
+		return p
+	}
+	return Path{parent}
+}
+
+// Base returns the last element of p, as path.Base does.
+func (p Path) Base() string {
+	if p.v == nil {
+		return "."
+	}
+	_, name := p.node()
+	return name
+}
+
+// String returns p as a slash-separated path.
+func (p Path) String() string {
+	if p.v == nil {
+		return "."
+	}
+	var names []string
+	n := 0
+	for v := p.v; v != nil; {
+		parent, name := Path{v}.node()
+		names = append(names, name)
+		n += len(name) + 1
+		v = parent
+	}
+	var b strings.Builder
+	b.Grow(n)
+	for i := len(names) - 1; i >= 0; i-- {
+		name := names[i]
+		if name == root {
+			b.WriteString(root)
+			continue
+		}
+		if i < len(names)-1 && names[i+1] != root {
+			b.WriteByte('/')
+		}
+		b.WriteString(name)
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internpath
+
+import (
+	"path"
+	"testing"
+	"unsafe"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+func TestPath(t *testing.T) {
+	for _, p := range []string{
+		"", ".", "/", "//", "/usr/lib", "usr/lib/", "a/./b", "a/../b",
+		"../a", "../../a/..", "/..", "/../a", "a/b/c/../../d",
+	} {
+		got := New(p)
+		if want := path.Clean(p); got.String() != want {
+			t.Errorf("New(%q) = %q; want %q", p, got, want)
+		}
+		if got != New(path.Clean(p)) {
+			t.Errorf("New(%q) != New(Clean)", p)
+		}
+		if want := path.Base(path.Clean(p)); got.Base() != want {
+			t.Errorf("Base(%q) = %q; want %q", p, got.Base(), want)
+		}
+		if want := path.Dir(path.Clean(p)); got.Dir().String() != want {
+			t.Errorf("Dir(%q) = %q; want %q", p, got.Dir(), want)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	usr := New("/usr")
+	if got := usr.Join("lib", "x/../../share"); got != New("/usr/share") {
+		t.Errorf("Join = %q; want /usr/share", got)
+	}
+	if (Path{}).Join("a", "b") != New("a/b") {
+		t.Error("Join onto zero Path differs")
+	}
+	if New("/usr/lib") == New("usr/lib") {
+		t.Error("absolute and relative paths equal")
+	}
+}
+
+func TestSharedNames(t *testing.T) {
+	a := New(string([]byte("/usr/lib")))
+	b := New(string([]byte("/opt/lib")))
+	if stringData(a.Base()) != stringData(b.Base()) {
+		t.Error("component names not shared")
+	}
+	if a.Dir().Dir() != b.Dir().Dir() {
+		t.Error("roots differ")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internproto interns the string fields of decoded protocol
+// buffer messages with package intern.
+//
+// Services that retain many decoded messages often hold the same
+// field values, such as enum-like names, hosts, and keys, in message
+// after message. Calling Strings after unmarshaling replaces them with
+// shared copies.
+//
+// The package walks the Go structs generated for messages with
+// package reflect, rather than with protoreflect, so it doesn't
+// depend on any protobuf module and works with messages generated by
+// any of the Go protobuf code generators.
+package internproto // import "go4.org/intern/internproto"
+
+import (
+	"reflect"
+
+	"go4.org/intern"
+)
+
+// Strings replaces the values of the string fields of msg, a pointer
+// to a generated message struct, and of the messages it contains,
+// with interned copies. That includes repeated fields, the string
+// keys and values of map fields, and fields of oneofs.
+//
+// Unexported fields, such as those holding unknown fields and
+// internal state, are left alone.
+func Strings(msg interface{}) {
+	StringsMax(msg, -1)
+}
+
+// StringsMax is like Strings, but only interns strings of at most n
+// bytes, leaving longer ones alone. If n is negative, all strings are
+// interned.
+func StringsMax(msg interface{}, n int) {
+	w := walker{max: n}
+	w.walk(reflect.ValueOf(msg))
+}
+
+var (
+	stringType = reflect.TypeOf("")
+	bytesType  = reflect.TypeOf([]byte(nil))
+)
+
+type walker struct {
+	max int
+}
+
+func (w walker) intern(s string) string {
+	if w.max >= 0 && len(s) > w.max {
+		return s
+	}
+	return intern.String(s)
+}
+
+// walk replaces the strings within v with their interned forms. v
+// must be settable, or a pointer.
+func (w walker) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() && v.Type() == stringType {
+			v.SetString(w.intern(v.String()))
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			w.walk(v.Elem())
+		}
+	case reflect.Interface:
+		// A oneof field, holding a pointer to a wrapper struct.
+		if !v.IsNil() && v.Elem().Kind() == reflect.Ptr {
+			w.walk(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.CanSet() {
+				w.walk(f)
+			}
+		}
+	case reflect.Slice:
+		if v.Type() == bytesType {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			w.walk(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		internKeys := v.Type().Key() == stringType
+		iter := v.MapRange()
+		for iter.Next() {
+			k, e := iter.Key(), iter.Value()
+			c := reflect.New(e.Type()).Elem()
+			c.Set(e)
+			w.walk(c)
+			if internKeys {
+				// Assigning to an existing string key
+				// replaces the key as well as the value.
+				k = reflect.ValueOf(w.intern(k.String()))
+			}
+			v.SetMapIndex(k, c)
+		}
+	}
+}
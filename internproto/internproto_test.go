@@ -0,0 +1,97 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internproto
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+// The types below mimic the shapes of generated message structs.
+
+type Request struct {
+	state   int32 // like protoimpl.MessageState
+	Host    string
+	Tags    []string
+	Labels  map[string]string
+	Body    []byte
+	Inner   *Inner
+	Inners  []*Inner
+	Payload isRequest_Payload
+	unknown []byte
+}
+
+type Inner struct {
+	Name string
+}
+
+type isRequest_Payload interface{ isRequest_Payload() }
+
+type Request_Kind struct {
+	Kind string
+}
+
+func (*Request_Kind) isRequest_Payload() {}
+
+// dup returns a copy of s with its own memory.
+func dup(s string) string { return string([]byte(s)) }
+
+func newRequest() *Request {
+	return &Request{
+		Host:    dup("example.com"),
+		Tags:    []string{dup("a"), dup("b")},
+		Labels:  map[string]string{dup("env"): dup("prod")},
+		Body:    []byte("body"),
+		Inner:   &Inner{Name: dup("x")},
+		Inners:  []*Inner{{Name: dup("x")}},
+		Payload: &Request_Kind{Kind: dup("k")},
+	}
+}
+
+func TestStrings(t *testing.T) {
+	a, b := newRequest(), newRequest()
+	Strings(a)
+	Strings(b)
+	same := func(what, x, y string) {
+		t.Helper()
+		if x != y || stringData(x) != stringData(y) {
+			t.Errorf("%s not interned", what)
+		}
+	}
+	same("Host", a.Host, b.Host)
+	same("Tags", a.Tags[1], b.Tags[1])
+	same("Labels value", a.Labels["env"], b.Labels["env"])
+	for ka := range a.Labels {
+		for kb := range b.Labels {
+			same("Labels key", ka, kb)
+		}
+	}
+	same("Inner", a.Inner.Name, b.Inner.Name)
+	same("Inners", a.Inners[0].Name, a.Inner.Name)
+	same("oneof", a.Payload.(*Request_Kind).Kind, b.Payload.(*Request_Kind).Kind)
+	if string(a.Body) != "body" {
+		t.Errorf("Body = %q", a.Body)
+	}
+}
+
+func TestStringsMax(t *testing.T) {
+	long := strings.Repeat("h", 10)
+	a, b := &Inner{Name: dup(long)}, &Inner{Name: dup(long)}
+	StringsMax(a, 5)
+	StringsMax(b, 5)
+	if stringData(a.Name) == stringData(b.Name) {
+		t.Error("long string interned")
+	}
+	Strings(a)
+	Strings(b)
+	if stringData(a.Name) != stringData(b.Name) {
+		t.Error("Strings didn't intern long string")
+	}
+}
@@ -0,0 +1,18 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internslog interns the keys and short string values of
+// log/slog attributes with package intern.
+//
+// Wrapping a Handler with NewHandler interns attributes as records
+// pass through it, without changing the code that logs them. Handlers
+// that retain or batch records, such as those buffering logs for
+// shipping elsewhere, then hold one copy of each repeated key and
+// value. Other logging sinks can use Attr directly.
+//
+// Logger goes the other way, logging an Interner's notable events,
+// such as mass evictions and rejected values, to a slog.Logger.
+//
+// The package requires Go 1.21 or later, which added log/slog.
+package internslog // import "go4.org/intern/internslog"
@@ -0,0 +1,48 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package internslog
+
+import (
+	"context"
+	"log/slog"
+
+	"go4.org/intern"
+)
+
+// Logger returns an intern.Option that logs an Interner's notable
+// events, as reported by intern.OnEvent, to l, with their details as
+// attributes. Events below level aren't logged. Entry counts are
+// logged as they first reach each of entryThresholds.
+//
+// Rejected values and the safe-but-leaky mode are logged at
+// slog.LevelWarn, and other events at slog.LevelInfo.
+func Logger(l *slog.Logger, level slog.Level, entryThresholds ...int) intern.Option {
+	return intern.OnEvent(func(e intern.Event) {
+		lvl, msg := slog.LevelInfo, ""
+		var attrs []slog.Attr
+		switch e.Kind {
+		case intern.EventEntries:
+			msg = "intern: entries reached threshold"
+			attrs = append(attrs, slog.Int("entries", e.Entries))
+		case intern.EventEvicted:
+			msg = "intern: values evicted"
+			attrs = append(attrs, slog.Int("count", e.Count), slog.String("reason", e.Reason))
+		case intern.EventSafeMode:
+			lvl, msg = slog.LevelWarn, "intern: in safe-but-leaky mode; values are never collected"
+		case intern.EventRejected:
+			lvl, msg = slog.LevelWarn, "intern: value rejected"
+			attrs = append(attrs, slog.String("reason", e.Reason), slog.String("type", e.Type), slog.Int("size", e.Size))
+		default:
+			msg = "intern: " + e.Kind.String()
+		}
+		if lvl < level {
+			return
+		}
+		l.LogAttrs(context.Background(), lvl, msg, attrs...)
+	}, entryThresholds...)
+}
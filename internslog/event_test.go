@@ -0,0 +1,63 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package internslog
+
+import (
+	"bytes"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go4.org/intern"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+	in := intern.New(Logger(l, slog.LevelInfo, 3), intern.WithMaxSize(8))
+	var vs []*intern.Value
+	for i := 0; i < 4; i++ {
+		vs = append(vs, in.GetByString(strconv.Itoa(i)))
+	}
+	in.GetByString("much too long")
+
+	lines := logLines(&buf)
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines; want 2:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{
+		`level=INFO msg="intern: entries reached threshold" entries=3`,
+		`level=WARN msg="intern: value rejected" reason=size type=string size=13`,
+	} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q; want %q", i, lines[i], want)
+		}
+	}
+
+	buf.Reset()
+	in = intern.New(Logger(l, slog.LevelWarn, 1))
+	vs = append(vs, in.GetByString("x"))
+	if len(logLines(&buf)) != 0 {
+		t.Errorf("logged below the threshold level: %s", buf.String())
+	}
+	runtime.KeepAlive(vs)
+}
+
+// logLines returns the lines logged to buf, without the warning logged
+// in safe-but-leaky mode, so that tests run the same in that mode.
+func logLines(buf *bytes.Buffer) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line != "" && !strings.Contains(line, "safe-but-leaky") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package internslog
+
+import (
+	"context"
+	"log/slog"
+
+	"go4.org/intern"
+)
+
+// DefaultMaxValueLen is the length of the longest string value that
+// Attr, and a Handler with a zero MaxValueLen, interns.
+const DefaultMaxValueLen = 64
+
+// HandlerOptions are options for a Handler.
+type HandlerOptions struct {
+	// MaxValueLen is the length, in bytes, of the longest string
+	// value that is interned. Longer values, such as messages and
+	// IDs, are unlikely to repeat and are left alone.
+	// If zero, DefaultMaxValueLen is used. If negative, only keys
+	// are interned.
+	MaxValueLen int
+}
+
+// A Handler is a slog.Handler that interns its records' attributes
+// before passing them to another Handler.
+type Handler struct {
+	h      slog.Handler
+	maxLen int
+}
+
+// NewHandler returns a Handler that passes records to h with their
+// attributes interned. If opts is nil, the default options are used.
+func NewHandler(h slog.Handler, opts *HandlerOptions) *Handler {
+	maxLen := DefaultMaxValueLen
+	if opts != nil && opts.MaxValueLen != 0 {
+		maxLen = opts.MaxValueLen
+	}
+	return &Handler{h: h, maxLen: maxLen}
+}
+
+// Enabled implements slog.Handler.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(internAttr(a, h.maxLen))
+		return true
+	})
+	return h.h.Handle(ctx, nr)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	ia := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		ia[i] = internAttr(a, h.maxLen)
+	}
+	return &Handler{h: h.h.WithAttrs(ia), maxLen: h.maxLen}
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{h: h.h.WithGroup(internString(name)), maxLen: h.maxLen}
+}
+
+// Attr returns a with its key, and its value if it's a string no
+// longer than DefaultMaxValueLen, interned. The attributes of a group
+// are interned likewise.
+func Attr(a slog.Attr) slog.Attr {
+	return internAttr(a, DefaultMaxValueLen)
+}
+
+func internAttr(a slog.Attr, maxLen int) slog.Attr {
+	a.Key = internString(a.Key)
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if s := a.Value.String(); len(s) <= maxLen {
+			a.Value = slog.StringValue(internString(s))
+		}
+	case slog.KindGroup:
+		g := a.Value.Group()
+		ig := make([]slog.Attr, len(g))
+		for i, ga := range g {
+			ig[i] = internAttr(ga, maxLen)
+		}
+		a.Value = slog.GroupValue(ig...)
+	}
+	return a
+}
+
+func internString(s string) string {
+	if s == "" {
+		return ""
+	}
+	return intern.GetByString(s).Get().(string)
+}
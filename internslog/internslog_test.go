@@ -0,0 +1,73 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package internslog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+// recorder is a slog.Handler that keeps the attributes it's given.
+type recorder struct {
+	attrs []slog.Attr
+}
+
+func (r *recorder) Enabled(context.Context, slog.Level) bool { return true }
+
+func (r *recorder) Handle(_ context.Context, rec slog.Record) error {
+	rec.Attrs(func(a slog.Attr) bool {
+		r.attrs = append(r.attrs, a)
+		return true
+	})
+	return nil
+}
+
+func (r *recorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	r.attrs = append(r.attrs, attrs...)
+	return r
+}
+
+func (r *recorder) WithGroup(string) slog.Handler { return r }
+
+func TestHandler(t *testing.T) {
+	rec := new(recorder)
+	long := strings.Repeat("x", DefaultMaxValueLen+1)
+	l := slog.New(NewHandler(rec, nil)).With(string([]byte("service")), "api")
+	l.Info("hi", string([]byte("status")), string([]byte("ok")), "body", long,
+		slog.Group("req", string([]byte("method")), "GET"))
+	l.Info("hi", "status", "ok", slog.Group("req", "method", "GET"))
+
+	if len(rec.attrs) != 6 {
+		t.Fatalf("got %d attrs: %v", len(rec.attrs), rec.attrs)
+	}
+	a, b := rec.attrs[1], rec.attrs[4]
+	if stringData(a.Key) != stringData(b.Key) || stringData(a.Value.String()) != stringData(b.Value.String()) {
+		t.Error("string attr not interned")
+	}
+	if stringData(rec.attrs[2].Value.String()) != stringData(long) {
+		t.Error("long value interned")
+	}
+	ga, gb := rec.attrs[3].Value.Group()[0], rec.attrs[5].Value.Group()[0]
+	if stringData(ga.Key) != stringData(gb.Key) {
+		t.Error("group attr not interned")
+	}
+}
+
+func TestAttr(t *testing.T) {
+	a := Attr(slog.Int(string([]byte("n")), 1))
+	if a.Value.Int64() != 1 || stringData(a.Key) != stringData(Attr(slog.Int("n", 2)).Key) {
+		t.Error("Attr didn't intern key")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internsql interns string columns read with database/sql,
+// with package intern.
+//
+// Queries often return the same enum-like values, such as statuses
+// and country codes, in row after row, and database/sql allocates a
+// new string for each. Scanning into a String, or scanning with Rows,
+// interns them instead.
+package internsql // import "go4.org/intern/internsql"
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"go4.org/intern"
+)
+
+// A String is a nullable string that is interned when scanned. Like
+// sql.NullString, it implements sql.Scanner and driver.Valuer.
+type String struct {
+	String string
+	Valid  bool // Valid is true if String is not NULL
+}
+
+// Scan implements sql.Scanner.
+func (s *String) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		s.String, s.Valid = "", false
+		return nil
+	case string:
+		s.String = intern.String(v)
+	case []byte:
+		s.String = intern.Bytes(v)
+	default:
+		var ns sql.NullString
+		if err := ns.Scan(src); err != nil {
+			return fmt.Errorf("internsql: %w", err)
+		}
+		s.String = intern.String(ns.String)
+	}
+	s.Valid = true
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (s String) Value() (driver.Value, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	return s.String, nil
+}
+
+// Rows wraps sql.Rows, interning string columns on Scan.
+type Rows struct {
+	*sql.Rows
+}
+
+// Scan is like sql.Rows.Scan, but interns the values scanned into
+// destinations of type *string and *sql.NullString.
+func (r Rows) Scan(dest ...interface{}) error {
+	if err := r.Rows.Scan(dest...); err != nil {
+		return err
+	}
+	for _, d := range dest {
+		switch d := d.(type) {
+		case *string:
+			*d = intern.String(*d)
+		case *sql.NullString:
+			if d.Valid {
+				d.String = intern.String(d.String)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"unsafe"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+// fakeDriver serves every query with the rows of fakeRows.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("no transactions") }
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("no exec") }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return &fakeRows{}, nil }
+
+type fakeRows struct{ n int }
+
+func (*fakeRows) Columns() []string { return []string{"status", "country"} }
+func (*fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.n == 3 {
+		return io.EOF
+	}
+	r.n++
+	dest[0] = []byte("ACTIVE")
+	if r.n == 2 {
+		dest[1] = nil
+	} else {
+		dest[1] = []byte("NZ")
+	}
+	return nil
+}
+
+func init() {
+	sql.Register("internsqltest", fakeDriver{})
+}
+
+func TestRows(t *testing.T) {
+	db, err := sql.Open("internsqltest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlRows, err := db.Query("SELECT status, country FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := Rows{sqlRows}
+	defer rows.Close()
+	var statuses []string
+	var countries []sql.NullString
+	for rows.Next() {
+		var status string
+		var country sql.NullString
+		if err := rows.Scan(&status, &country); err != nil {
+			t.Fatal(err)
+		}
+		statuses = append(statuses, status)
+		countries = append(countries, country)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 3 || stringData(statuses[0]) != stringData(statuses[2]) {
+		t.Errorf("statuses %q not interned", statuses)
+	}
+	if countries[1].Valid || stringData(countries[0].String) != stringData(countries[2].String) {
+		t.Errorf("countries %v not interned", countries)
+	}
+}
+
+func TestString(t *testing.T) {
+	var a, b, n String
+	if err := a.Scan([]byte("ACTIVE")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Scan("ACTIVE"); err != nil {
+		t.Fatal(err)
+	}
+	if !a.Valid || a.String != "ACTIVE" || stringData(a.String) != stringData(b.String) {
+		t.Errorf("Scan = %+v, %+v; want interned ACTIVE", a, b)
+	}
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("Scan(nil) = %+v, %v", n, err)
+	}
+	var i String
+	if err := i.Scan(int64(42)); err != nil || i.String != "42" {
+		t.Errorf("Scan(42) = %+v, %v", i, err)
+	}
+	if v, err := n.Value(); v != nil || err != nil {
+		t.Errorf("Value of NULL = %v, %v", v, err)
+	}
+	if v, err := a.Value(); v != "ACTIVE" || err != nil {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interntest
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"go4.org/intern"
+	"go4.org/intern/internal/testhooks"
+)
+
+// Differential interprets script as a sequence of operations, running
+// each on two Interners configured by opts, one using the default
+// backend and the other the safe-but-leaky backend, and fails t if
+// their observable behavior ever differs. It catches drift between the
+// backends, such as a change to the default backend's unsafe tricks
+// that a new Go release breaks, whichever backend the environment
+// selects for the default Interner.
+//
+// Each byte of script selects a key and one of: Get it as a string;
+// Get it as an int; Lookup it; Forget it; TryGet an uncomparable value;
+// Get a pair of it as both; compare Stats; or run the garbage
+// collector. Every Value returned is kept reachable until the script
+// ends, so the default backend never collects one and the two must
+// agree exactly: in what they return, and in which results are the
+// same Value.
+func Differential(t testing.TB, script []byte, opts ...intern.Option) {
+	t.Helper()
+	safe := testhooks.SafeBackend.(intern.Option)
+	var sides [2]side
+	sides[0].in = intern.New(opts...)
+	sides[1].in = intern.New(append(opts[:len(opts):len(opts)], safe)...)
+	for _, s := range sides {
+		defer s.in.Close()
+	}
+	for n, b := range script {
+		i := int(b>>3) % numExerciseKeys
+		op := b & 7
+		var res [2]string
+		for j := range sides {
+			res[j] = sides[j].do(op, i)
+		}
+		if res[0] != res[1] {
+			t.Fatalf("op %d (%s of key %d): default backend gave %s; safe backend gave %s",
+				n, diffOps[op], i, res[0], res[1])
+		}
+	}
+	for _, s := range sides {
+		runtime.KeepAlive(s.held)
+	}
+}
+
+// diffOps names Differential's operations, for failure messages.
+var diffOps = [8]string{"GetByString", "Get", "Lookup", "Forget", "TryGet", "GetPair", "Stats", "GC"}
+
+// A side is one of the two Interners compared by Differential.
+type side struct {
+	in   *intern.Interner
+	held []*intern.Value
+	ids  map[*intern.Value]int // by first appearance
+}
+
+// do performs op on key i, returning a description of its result
+// that's independent of the backend.
+func (s *side) do(op byte, i int) string {
+	key := exerciseKey(i)
+	switch op {
+	case 0:
+		return s.describe(s.in.GetByString(key))
+	case 1:
+		return s.describe(s.in.Get(i))
+	case 2:
+		v, ok := s.in.Lookup(key)
+		return fmt.Sprintf("%s, %v", s.describe(v), ok)
+	case 3:
+		return fmt.Sprint(s.in.Forget(key))
+	case 4:
+		v, err := s.in.TryGet([]byte(key))
+		return fmt.Sprintf("%s, %v", s.describe(v), err)
+	case 5:
+		return s.describe(s.in.GetPair(key, i))
+	case 6:
+		st := s.in.Stats()
+		return fmt.Sprintf("entries=%d hits=%d misses=%d", st.Entries, st.Hits, st.Misses)
+	default:
+		runtime.GC()
+		return ""
+	}
+}
+
+// describe returns a description of v: its value, and which Value, in
+// the order in which s first saw them, it is.
+func (s *side) describe(v *intern.Value) string {
+	if v == nil {
+		return "nil"
+	}
+	if s.ids == nil {
+		s.ids = make(map[*intern.Value]int)
+	}
+	id, ok := s.ids[v]
+	if !ok {
+		id = len(s.ids)
+		s.ids[v] = id
+		s.held = append(s.held, v)
+	}
+	return fmt.Sprintf("Value #%d of %#v", id, v.Get())
+}
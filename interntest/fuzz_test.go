@@ -0,0 +1,30 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package interntest
+
+import (
+	"testing"
+
+	"go4.org/intern"
+)
+
+func FuzzExercise(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 0})
+	f.Add([]byte{0, 3, 1, 2, 3, 2})
+	f.Fuzz(func(t *testing.T, script []byte) {
+		Exercise(t, intern.New(), script)
+	})
+}
+
+func FuzzDifferential(f *testing.F) {
+	f.Add([]byte{0, 0, 2, 3, 2, 0, 6})
+	f.Add([]byte{1, 9, 1, 6, 3, 1, 6})
+	f.Fuzz(func(t *testing.T, script []byte) {
+		Differential(t, script)
+	})
+}
@@ -0,0 +1,82 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interntest
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+
+	"go4.org/intern"
+)
+
+// numExerciseKeys is the number of distinct keys used by Exercise.
+const numExerciseKeys = 16
+
+// Exercise interprets script as a sequence of operations on in,
+// failing t if in ever breaks its guarantees: that Gets of equal
+// values return the same Value while it's reachable, and that a
+// Value's Get returns the value it was created from.
+//
+// Each byte of script selects a key and one of: Get the key and keep
+// the Value; drop the kept Value; allocate garbage and run the garbage
+// collector; or Get the key from another goroutine concurrently with
+// a collection. Dropping Values and collecting between Gets exercises
+// the race between finalization and resurrection that the Interner
+// must get right.
+//
+// Exercise is intended for fuzz targets, which pass it arbitrary
+// scripts, and for regression tests with scripts found by fuzzing.
+func Exercise(t testing.TB, in *intern.Interner, script []byte) {
+	t.Helper()
+	var held [numExerciseKeys]*intern.Value
+	check := func(i int, v *intern.Value) {
+		t.Helper()
+		if got, want := v.Get(), exerciseKey(i); got != want {
+			t.Fatalf("Get returned Value for %q; want %q", got, want)
+		}
+		if held[i] != nil && held[i] != v {
+			t.Fatalf("Get(%q) returned a new Value while the old one was reachable", exerciseKey(i))
+		}
+	}
+	for _, b := range script {
+		i := int(b>>2) % numExerciseKeys
+		switch b & 3 {
+		case 0:
+			v := in.GetByString(exerciseKey(i))
+			check(i, v)
+			held[i] = v
+		case 1:
+			held[i] = nil
+		case 2:
+			garbage = make([]byte, 1<<10)
+			runtime.GC()
+		case 3:
+			var wg sync.WaitGroup
+			var v *intern.Value
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v = in.GetByString(exerciseKey(i))
+			}()
+			runtime.GC()
+			wg.Wait()
+			check(i, v)
+		}
+	}
+	for i, v := range held {
+		if v != nil {
+			check(i, in.GetByString(exerciseKey(i)))
+		}
+	}
+}
+
+// garbage is a sink for Exercise's allocations.
+var garbage []byte
+
+func exerciseKey(i int) string {
+	return "k" + strconv.Itoa(i)
+}
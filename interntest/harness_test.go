@@ -0,0 +1,45 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interntest
+
+import (
+	"testing"
+
+	"go4.org/intern"
+)
+
+func TestExercise(t *testing.T) {
+	scripts := [][]byte{
+		nil,
+		{0, 2, 0},
+		{0, 1, 2, 0, 3, 1, 2, 2, 3},
+		{0, 4, 8, 1, 2, 5, 3, 7, 11, 2, 0, 4},
+	}
+	for _, s := range scripts {
+		Exercise(t, intern.New(), s)
+		Exercise(t, intern.New(intern.HashKeys()), s)
+	}
+}
+
+func TestDifferential(t *testing.T) {
+	scripts := [][]byte{
+		nil,
+		{0, 0, 2, 3, 2, 0, 6},
+		{1, 9, 1, 6, 3, 1, 6},
+		{4, 5, 13, 0, 7, 8, 5, 6, 11, 16, 6},
+	}
+	opts := [][]intern.Option{
+		nil,
+		{intern.CaseInsensitive()},
+		{intern.HashKeys()},
+		{intern.Seqlock()},
+		{intern.WithMinSize(3), intern.TinyStrings()},
+	}
+	for _, s := range scripts {
+		for _, o := range opts {
+			Differential(t, s, o...)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package interntest provides helpers for tests of packages that use
+// go4.org/intern's default Interner, so they needn't depend on the
+// timing of garbage collection or on intern's internals.
+package interntest // import "go4.org/intern/interntest"
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"go4.org/intern"
+	"go4.org/intern/internal/testhooks"
+)
+
+// Len returns the number of Values in the default Interner.
+func Len() int {
+	return intern.Default().Stats().Entries
+}
+
+// Reset empties the default Interner, so that the test t starts from
+// a known state.
+//
+// Values obtained before Reset remain valid but are no longer
+// canonical: getting the same value again returns a different *Value.
+// So Reset must not be used while other tests are running in parallel.
+func Reset(t testing.TB) {
+	t.Helper()
+	testhooks.ResetDefault()
+}
+
+// RequireCollected runs the garbage collector until every Value in the
+// default Interner has been collected, failing t if any remain after
+// timeout. Callers should Reset first if earlier tests may have left
+// Values that are still reachable.
+//
+// In safe-but-leaky mode (GO4_INTERN_SAFE_BUT_LEAKY), Values are never
+// collected, and RequireCollected skips t.
+func RequireCollected(t testing.TB, timeout time.Duration) {
+	t.Helper()
+	if testhooks.SafeMode() {
+		t.Skip("Values are never collected in GO4_INTERN_SAFE_BUT_LEAKY mode")
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		runtime.GC()
+		n := Len()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d Values still interned after %v", n, timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
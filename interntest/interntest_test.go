@@ -0,0 +1,45 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interntest
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"go4.org/intern"
+)
+
+func TestResetLen(t *testing.T) {
+	old := intern.GetByString("interntest-old")
+	t.Run("reset", func(t *testing.T) {
+		Reset(t)
+		if n := Len(); n != 0 {
+			t.Fatalf("Len after Reset = %d; want 0", n)
+		}
+		v := intern.GetByString("interntest-old")
+		if v == old {
+			t.Error("Get after Reset returned the pre-Reset Value")
+		}
+		if n := Len(); n != 1 {
+			t.Errorf("Len = %d; want 1", n)
+		}
+		runtime.KeepAlive(v)
+	})
+	runtime.KeepAlive(old)
+}
+
+func TestRequireCollected(t *testing.T) {
+	Reset(t)
+	func() {
+		for i := 0; i < 10; i++ {
+			intern.Get(i)
+		}
+	}()
+	RequireCollected(t, 10*time.Second)
+	if n := Len(); n != 0 {
+		t.Errorf("Len = %d; want 0", n)
+	}
+}
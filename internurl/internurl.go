@@ -0,0 +1,82 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internurl normalizes URLs and interns them, or their
+// components, with package intern.
+//
+// Crawlers and proxies see the same URLs, and the same schemes and
+// hosts, spelled in different ways many times over. Normalizing them
+// first means equivalent URLs share one interned Value.
+package internurl // import "go4.org/intern/internurl"
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"go4.org/intern"
+)
+
+// defaultPorts maps schemes to the port used when a URL has none.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+// Normalize normalizes u in place: it lowercases its scheme and host,
+// and removes its port if it's the default for the scheme.
+//
+// An http or https URL with a host and an empty path gets the path
+// "/", to which it's equivalent.
+func Normalize(u *url.URL) {
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Host)
+	if h, port, err := net.SplitHostPort(host); err == nil && port == defaultPorts[u.Scheme] {
+		host = h
+		if strings.Contains(h, ":") {
+			host = "[" + h + "]" // IPv6 literal
+		}
+	}
+	u.Host = host
+	if u.Path == "" && u.Opaque == "" && host != "" && (u.Scheme == "http" || u.Scheme == "https") {
+		u.Path = "/"
+		u.RawPath = ""
+	}
+}
+
+// Parse parses s as a URL, normalizes it as by Normalize, and interns
+// its scheme, host, and path.
+func Parse(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	Normalize(u)
+	u.Scheme = internString(u.Scheme)
+	u.Host = internString(u.Host)
+	u.Path = internString(u.Path)
+	return u, nil
+}
+
+// Get returns the Value of the normalized form of the URL s, as by
+// Parse, so that URLs differing only in ways Normalize removes share
+// a Value. The Value's underlying value is the normalized URL string.
+func Get(s string) (*intern.Value, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	Normalize(u)
+	return intern.GetByString(u.String()), nil
+}
+
+func internString(s string) string {
+	if s == "" {
+		return ""
+	}
+	return intern.GetByString(s).Get().(string)
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internurl
+
+import (
+	"net/url"
+	"testing"
+	"unsafe"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+func TestNormalize(t *testing.T) {
+	for _, tt := range []struct{ in, want string }{
+		{"HTTP://Example.COM:80/a", "http://example.com/a"},
+		{"https://example.com:443", "https://example.com/"},
+		{"https://example.com:8443/x?Q=1", "https://example.com:8443/x?Q=1"},
+		{"http://[::1]:80/", "http://[::1]/"},
+		{"http://[::1]:8080/", "http://[::1]:8080/"},
+		{"mailto:Someone@Example.com", "mailto:Someone@Example.com"},
+		{"/relative/Path", "/relative/Path"},
+	} {
+		u, err := url.Parse(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		Normalize(u)
+		if got := u.String(); got != tt.want {
+			t.Errorf("Normalize(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	a, err := Get("HTTP://Example.com:80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := Get("http://example.com/")
+	if a != b {
+		t.Error("equivalent URLs got different Values")
+	}
+	if a.Get() != "http://example.com/" {
+		t.Errorf("Get = %q", a.Get())
+	}
+	if _, err := Get("http://[bad"); err == nil {
+		t.Error("Get of invalid URL succeeded")
+	}
+}
+
+func TestParse(t *testing.T) {
+	a, err := Parse("http://EXAMPLE.com/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := Parse("http://example.com:80/index.html")
+	if a.Host != "example.com" || stringData(a.Host) != stringData(b.Host) {
+		t.Error("hosts not interned")
+	}
+	if stringData(a.Path) != stringData(b.Path) || stringData(a.Scheme) != stringData(b.Scheme) {
+		t.Error("components not interned")
+	}
+}
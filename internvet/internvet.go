@@ -0,0 +1,171 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internvet checks Go code for misuse of package intern, in
+// the manner of go vet. The internvet command runs it.
+//
+// It reports:
+//
+//   - uses of intern.Value other than through a pointer, such as
+//     intern.Value{} or *v, which make Values that aren't canonical;
+//   - Gets of values whose static type isn't comparable, which panic;
+//   - Gets of strings made by unsafe conversion from memory that may
+//     later change, such as a []byte, since the interned string then
+//     changes with it.
+package internvet // import "go4.org/intern/internvet"
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// internPath is the import path of package intern.
+const internPath = "go4.org/intern"
+
+// A Diagnostic is a problem found by Check.
+type Diagnostic struct {
+	Pos     token.Pos
+	Message string
+}
+
+// Check checks files, which make up a package type-checked with the
+// results recorded in info, and returns the problems found. info must
+// have its Types, Defs, Uses, and Selections maps set.
+func Check(files []*ast.File, info *types.Info) []Diagnostic {
+	c := &checker{info: info}
+	for _, f := range files {
+		ast.Inspect(f, c.visit)
+	}
+	return c.diags
+}
+
+type checker struct {
+	info  *types.Info
+	diags []Diagnostic
+}
+
+func (c *checker) reportf(pos token.Pos, format string, args ...interface{}) {
+	c.diags = append(c.diags, Diagnostic{pos, fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.CompositeLit:
+		if isValue(c.info.TypeOf(n)) {
+			c.reportf(n.Pos(), "intern.Value constructed directly; use intern.Get to get a canonical *Value")
+		}
+	case *ast.StarExpr:
+		// A dereference, rather than a pointer type.
+		if tv, ok := c.info.Types[n]; ok && tv.IsValue() && isValue(tv.Type) {
+			c.reportf(n.Pos(), "intern.Value dereferenced; use the *Value, whose identity is what's canonical")
+		}
+	case *ast.ValueSpec:
+		for _, name := range n.Names {
+			if obj := c.info.Defs[name]; obj != nil && isValue(obj.Type()) {
+				c.reportf(name.Pos(), "variable %s of type intern.Value; use *intern.Value", name.Name)
+			}
+		}
+	case *ast.CallExpr:
+		c.checkCall(n)
+	}
+	return true
+}
+
+// getFuncs are the functions and methods of package intern taking a
+// value to intern, by name.
+var getFuncs = map[string]bool{
+	"Get": true, "TryGet": true, "GetByString": true, "String": true,
+	"Lookup": true, "GetRef": true, "GetRefByString": true,
+	"SymbolOf": true, "SymbolOfString": true,
+}
+
+func (c *checker) checkCall(call *ast.CallExpr) {
+	fn := c.callee(call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != internPath || !getFuncs[fn.Name()] || len(call.Args) != 1 {
+		return
+	}
+	arg := call.Args[0]
+	if t := c.info.TypeOf(arg); t != nil && !types.IsInterface(t) && !types.Comparable(t) {
+		c.reportf(arg.Pos(), "%s of uncomparable type %s panics", fn.Name(), t)
+	}
+	if c.isUnsafeString(arg) {
+		c.reportf(arg.Pos(), "%s of a string made by unsafe conversion: if its memory changes, so does the interned string; convert with string(b) or use intern.Bytes", fn.Name())
+	}
+}
+
+// callee returns the function or method called by call, or nil.
+func (c *checker) callee(call *ast.CallExpr) *types.Func {
+	var id *ast.Ident
+	switch f := call.Fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return nil
+	}
+	fn, _ := c.info.Uses[id].(*types.Func)
+	return fn
+}
+
+// isUnsafeString reports whether e is a string made with package
+// unsafe: *(*string)(unsafe.Pointer(...)) or unsafe.String(...).
+func (c *checker) isUnsafeString(e ast.Expr) bool {
+	e = unparen(e)
+	switch e := e.(type) {
+	case *ast.StarExpr:
+		conv, ok := unparen(e.X).(*ast.CallExpr)
+		if !ok || len(conv.Args) != 1 {
+			return false
+		}
+		if tv, ok := c.info.Types[conv.Fun]; !ok || !tv.IsType() {
+			return false
+		}
+		return isUnsafePointer(c.info.TypeOf(conv.Args[0]))
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		b, ok := c.info.Uses[sel.Sel].(*types.Builtin)
+		return ok && b.Name() == "String" && isUnsafePkg(c.info, sel.X)
+	}
+	return false
+}
+
+func isUnsafePointer(t types.Type) bool {
+	b, ok := t.(*types.Basic)
+	return ok && b.Kind() == types.UnsafePointer
+}
+
+func isUnsafePkg(info *types.Info, e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	pn, ok := info.Uses[id].(*types.PkgName)
+	return ok && pn.Imported().Path() == "unsafe"
+}
+
+// isValue reports whether t is intern.Value.
+func isValue(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := n.Obj()
+	return obj.Name() == "Value" && obj.Pkg() != nil && obj.Pkg().Path() == internPath
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
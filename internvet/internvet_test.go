@@ -0,0 +1,111 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internvet
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// internSrc stands in for package intern.
+const internSrc = `package intern
+
+type Value struct{ _ [0]func() }
+
+type Interner struct{}
+
+func Get(cmpVal interface{}) *Value                 { return nil }
+func GetByString(s string) *Value                   { return nil }
+func String(s string) string                        { return s }
+func (in *Interner) Get(cmpVal interface{}) *Value  { return nil }
+`
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+func check(t *testing.T, src string) []string {
+	t.Helper()
+	fset := token.NewFileSet()
+	std := importer.Default()
+	ipf, err := parser.ParseFile(fset, "intern.go", internSrc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipkg, err := (&types.Config{}).Check(internPath, fset, []*ast.File{ipf}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imp := importerFunc(func(path string) (*types.Package, error) {
+		if path == internPath {
+			return ipkg, nil
+		}
+		return std.Import(path)
+	})
+	f, err := parser.ParseFile(fset, "x.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{Importer: imp}
+	if _, err := conf.Check("x", fset, []*ast.File{f}, info); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range Check([]*ast.File{f}, info) {
+		got = append(got, fmt.Sprintf("%d: %s", fset.Position(d.Pos).Line, d.Message))
+	}
+	return got
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string // substring of the diagnostic, or "" for none
+	}{
+		{"ok", `intern.Get(1); intern.GetByString("x"); var v *intern.Value; _ = v`, ""},
+		{"ok-interface", `var x interface{} = 1; intern.Get(x)`, ""},
+		{"ok-bytes", `var b []byte; intern.GetByString(string(b))`, ""},
+		{"literal", `_ = intern.Value{}`, "constructed directly"},
+		{"pointer-literal", `_ = &intern.Value{}`, "constructed directly"},
+		{"deref", `v := intern.Get(1); w := *v; _ = w`, "dereferenced"},
+		{"var", `var v intern.Value; _ = &v`, "variable v of type intern.Value"},
+		{"slice", `intern.Get([]int{1})`, "uncomparable type []int"},
+		{"map", `intern.Get(map[string]int{})`, "uncomparable type map[string]int"},
+		{"func", `intern.Get(func() {})`, "uncomparable type func()"},
+		{"struct", `intern.Get(struct{ b []byte }{})`, "uncomparable type"},
+		{"method", `var in intern.Interner; in.Get([]int{1})`, "uncomparable type []int"},
+		{"unsafe-pointer", `var b []byte; intern.GetByString(*(*string)(unsafe.Pointer(&b)))`, "unsafe conversion"},
+		{"unsafe-string", `var b []byte; intern.String(unsafe.String(&b[0], len(b)))`, "unsafe conversion"},
+		{"unsafe-get", `var b []byte; intern.Get((*(*string)(unsafe.Pointer(&b))))`, "unsafe conversion"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "package x\n\nimport (\n\t\"unsafe\"\n\n\t\"go4.org/intern\"\n)\n\nvar _ unsafe.Pointer\n\nfunc f() {\n" + tt.body + "\n}\n"
+			got := check(t, src)
+			if tt.want == "" {
+				if len(got) > 0 {
+					t.Errorf("got %q; want none", got)
+				}
+				return
+			}
+			if len(got) != 1 || !strings.Contains(got[0], tt.want) {
+				t.Errorf("got %q; want one containing %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package internxml interns XML element and attribute names, and
+// selected attribute values, with package intern as they're decoded.
+//
+// encoding/xml allocates each name anew every time it appears, though
+// a document typically uses a handful of names over and over.
+package internxml // import "go4.org/intern/internxml"
+
+import (
+	"encoding/xml"
+	"io"
+
+	"go4.org/intern"
+)
+
+// NewDecoder returns an xml.Decoder reading from r whose tokens'
+// names are interned, as are the values of attributes with the given
+// local names.
+//
+// The returned Decoder is built with xml.NewTokenDecoder from raw
+// tokens read by an xml.Decoder with default settings, so setting its
+// Strict, AutoClose, Entity, and CharsetReader fields has no effect.
+func NewDecoder(r io.Reader, attrs ...string) *xml.Decoder {
+	return xml.NewTokenDecoder(NewTokenReader(rawReader{xml.NewDecoder(r)}, attrs...))
+}
+
+// rawReader is an xml.TokenReader returning the raw tokens of d, so
+// that namespaces are translated only by the Decoder reading from it.
+type rawReader struct {
+	d *xml.Decoder
+}
+
+func (r rawReader) Token() (xml.Token, error) {
+	return r.d.RawToken()
+}
+
+// NewTokenReader returns an xml.TokenReader that reads tokens from tr
+// and interns their names, and the values of attributes with the
+// given local names.
+func NewTokenReader(tr xml.TokenReader, attrs ...string) xml.TokenReader {
+	r := &tokenReader{tr: tr}
+	if len(attrs) > 0 {
+		r.attrs = map[string]bool{}
+		for _, a := range attrs {
+			r.attrs[a] = true
+		}
+	}
+	return r
+}
+
+type tokenReader struct {
+	tr    xml.TokenReader
+	attrs map[string]bool // local names of attributes whose values to intern
+}
+
+func (r *tokenReader) Token() (xml.Token, error) {
+	tok, err := r.tr.Token()
+	switch t := tok.(type) {
+	case xml.StartElement:
+		t.Name = internName(t.Name)
+		if len(t.Attr) > 0 {
+			attrs := make([]xml.Attr, len(t.Attr))
+			for i, a := range t.Attr {
+				a.Name = internName(a.Name)
+				if r.attrs[a.Name.Local] {
+					a.Value = intern.String(a.Value)
+				}
+				attrs[i] = a
+			}
+			t.Attr = attrs
+		}
+		tok = t
+	case xml.EndElement:
+		t.Name = internName(t.Name)
+		tok = t
+	}
+	return tok, err
+}
+
+func internName(n xml.Name) xml.Name {
+	if n.Space != "" {
+		n.Space = intern.String(n.Space)
+	}
+	n.Local = intern.String(n.Local)
+	return n
+}
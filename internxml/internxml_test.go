@@ -0,0 +1,75 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package internxml
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"go4.org/intern"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+const doc = `<feed xmlns:a="urn:a">
+<item type="book" id="1"><a:title>x</a:title></item>
+<item type="book" id="2"><a:title>y</a:title></item>
+</feed>`
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder(strings.NewReader(doc), "type")
+	var starts []xml.StartElement
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			starts = append(starts, se.Copy())
+		}
+	}
+	if len(starts) != 5 {
+		t.Fatalf("got %d start elements; want 5", len(starts))
+	}
+	i1, t1, i2, t2 := starts[1], starts[2], starts[3], starts[4]
+	if stringData(i1.Name.Local) != stringData(i2.Name.Local) {
+		t.Error("element names not interned")
+	}
+	if t1.Name.Space != "urn:a" || stringData(t1.Name.Space) != stringData(t2.Name.Space) {
+		t.Errorf("namespace %q not translated and interned", t1.Name.Space)
+	}
+	if stringData(i1.Attr[0].Name.Local) != stringData(i2.Attr[0].Name.Local) {
+		t.Error("attribute names not interned")
+	}
+	if stringData(i1.Attr[0].Value) != stringData(i2.Attr[0].Value) {
+		t.Error("selected attribute values not interned")
+	}
+	if i1.Attr[1].Value != "1" || stringData(i1.Attr[1].Value) == stringData(intern.String("1")) {
+		t.Error("unselected attribute value interned")
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var feed struct {
+		Items []struct {
+			Type  string `xml:"type,attr"`
+			Title string `xml:"urn:a title"`
+		} `xml:"item"`
+	}
+	if err := NewDecoder(strings.NewReader(doc), "type").Decode(&feed); err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Items) != 2 || feed.Items[1].Title != "y" || feed.Items[0].Type != "book" {
+		t.Errorf("decoded %+v", feed)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "time"
+
+// WithJanitor returns an Option that starts a background goroutine to
+// maintain the Interner every interval: compacting its index and
+// performing any other periodic work its options require.
+//
+// The goroutine runs until Close is called. Until then, it keeps the
+// Interner from being garbage collected. If interval is zero or less,
+// no goroutine is started.
+func WithJanitor(interval time.Duration) Option {
+	return func(in *Interner) {
+		if interval <= 0 {
+			in.janitor = nil
+			return
+		}
+		in.janitor = &janitor{
+			interval: interval,
+			stop:     make(chan struct{}),
+			done:     make(chan struct{}),
+		}
+	}
+}
+
+// janitor is the state of an Interner's background goroutine.
+type janitor struct {
+	interval time.Duration
+	stop     chan struct{} // closed by Close
+	done     chan struct{} // closed when run returns
+}
+
+func (j *janitor) run(in *Interner) {
+	defer close(j.done)
+	t := time.NewTicker(j.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-t.C:
+			in.tidy()
+		}
+	}
+}
+
+// tidy performs in's periodic maintenance.
+func (in *Interner) tidy() {
+	if in.deferFinalize {
+		in.mu.Lock()
+		in.drainFinalizedLocked()
+		in.mu.Unlock()
+	}
+	if in.pressure > 0 && memoryPressure(in.pressure) {
+		in.evictCold()
+	}
+	in.Compact()
+	if in.snapshots {
+		in.PublishSnapshot()
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestJanitor(t *testing.T) {
+	before := runtime.NumGoroutine()
+	in := New(WithJanitor(time.Millisecond))
+	vals := make([]*Value, 100)
+	for i := range vals {
+		vals[i] = in.Get(i)
+	}
+	in.mu.Lock()
+	for i := 0; i < 20; i++ {
+		in.tab.remove(tableHashValue(i), uintptr(unsafe.Pointer(vals[i])))
+	}
+	in.mu.Unlock()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		in.mu.Lock()
+		tombs := in.tab.tombs
+		in.mu.Unlock()
+		if tombs == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("janitor didn't compact the table")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := in.Close(); err != nil {
+		t.Fatal(err)
+	}
+	in.Close()
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines after Close; had %d before New", after, before)
+	}
+}
+
+func TestJanitorBadInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		in := New(WithJanitor(d))
+		if in.janitor != nil {
+			t.Errorf("WithJanitor(%v) started a janitor", d)
+		}
+		in.GetByString("x")
+		in.Close()
+	}
+}
+
+func TestCloseWithoutJanitor(t *testing.T) {
+	if err := New().Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package labels interns sets of labels, such as those identifying a
+// Prometheus time series, using package intern.
+//
+// A Set is a sorted set of name/value pairs reduced to a single
+// interned handle: equal sets, however they were built, have equal
+// Sets, which can be compared with == and used as map keys, and each
+// distinct set is stored once.
+//
+// Label names and values are interned individually too, and a set is
+// identified by its Labels' interned Values, so sets that share a
+// name or value, as the series of one metric share its label names,
+// share one copy of it.
+package labels // import "go4.org/intern/labels"
+
+import (
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go4.org/intern"
+)
+
+// A Label is a name/value pair.
+type Label struct {
+	Name, Value string
+}
+
+// A Set is an interned set of Labels, sorted by name, with distinct
+// names. The zero Set is the empty set.
+type Set struct {
+	v *intern.Value // of the set's key; nil if empty
+}
+
+var (
+	// interner holds Sets' keys: for each Label, the addresses of
+	// the Values of its name and value. It's separate from intern's
+	// default Interner so that the aux values attached to its Values
+	// are always *setInfo.
+	interner = intern.New()
+
+	// components holds Labels' names and values.
+	components = intern.New()
+)
+
+// setInfo is attached to each Set's Value with AuxOnce.
+//
+// Its parts keep the Values whose addresses make up the Set's key
+// reachable for as long as the Set's Value is, so the addresses can't
+// be reused by other Values while the key is interned.
+type setInfo struct {
+	labels []Label
+	parts  []*intern.Value
+	hash   uint64
+}
+
+// New returns the Set of ls. If a name appears more than once in ls,
+// the last of its Labels is used.
+func New(ls ...Label) Set {
+	if len(ls) == 0 {
+		return Set{}
+	}
+	sorted := make([]Label, len(ls))
+	copy(sorted, ls)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	out := sorted[:0]
+	for i, l := range sorted {
+		if i+1 < len(sorted) && sorted[i+1].Name == l.Name {
+			continue
+		}
+		out = append(out, l)
+	}
+	parts := make([]*intern.Value, 0, 2*len(out))
+	key := make([]byte, 0, 2*8*len(out))
+	for _, l := range out {
+		for _, str := range [2]string{l.Name, l.Value} {
+			v := components.GetByString(str)
+			parts = append(parts, v)
+			key = appendUint64(key, uint64(v.Pointer()))
+		}
+	}
+	v := interner.GetByString(string(key))
+	v.AuxOnce(func() interface{} { return newSetInfo(parts) })
+	return Set{v}
+}
+
+func appendUint64(b []byte, x uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], x)
+	return append(b, buf[:]...)
+}
+
+// FromStrings returns the Set of the Labels named by alternating names
+// and values in ss, as New does. It panics if len(ss) is odd.
+func FromStrings(ss ...string) Set {
+	if len(ss)%2 != 0 {
+		panic("labels: FromStrings with odd number of strings")
+	}
+	ls := make([]Label, 0, len(ss)/2)
+	for i := 0; i < len(ss); i += 2 {
+		ls = append(ls, Label{ss[i], ss[i+1]})
+	}
+	return New(ls...)
+}
+
+// FromMap returns the Set of the Labels in m.
+func FromMap(m map[string]string) Set {
+	ls := make([]Label, 0, len(m))
+	for name, value := range m {
+		ls = append(ls, Label{name, value})
+	}
+	return New(ls...)
+}
+
+// Offset basis and prime of 64-bit FNV-1a.
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// newSetInfo returns the setInfo of the Set whose Labels' names and
+// values have the Values parts.
+func newSetInfo(parts []*intern.Value) *setInfo {
+	si := &setInfo{parts: parts, hash: fnvOffset}
+	var lenBuf [binary.MaxVarintLen64]byte
+	hash := func(b string) {
+		for i := 0; i < len(b); i++ {
+			si.hash ^= uint64(b[i])
+			si.hash *= fnvPrime
+		}
+	}
+	strs := make([]string, len(parts))
+	for i, v := range parts {
+		strs[i] = v.Get().(string)
+		hash(string(lenBuf[:binary.PutUvarint(lenBuf[:], uint64(len(strs[i])))]))
+		hash(strs[i])
+	}
+	si.labels = make([]Label, len(parts)/2)
+	for i := range si.labels {
+		si.labels[i] = Label{strs[2*i], strs[2*i+1]}
+	}
+	return si
+}
+
+// emptyInfo is the setInfo of the empty Set.
+var emptyInfo = newSetInfo(nil)
+
+// info returns s's setInfo.
+func (s Set) info() *setInfo {
+	if s.v == nil {
+		return emptyInfo
+	}
+	return s.v.Aux().(*setInfo)
+}
+
+// Len returns the number of Labels in s.
+func (s Set) Len() int {
+	return len(s.info().labels)
+}
+
+// Labels returns a copy of s's Labels, sorted by name.
+func (s Set) Labels() []Label {
+	ls := s.info().labels
+	return append(make([]Label, 0, len(ls)), ls...)
+}
+
+// Range calls f with each of s's Labels, in order by name.
+func (s Set) Range(f func(Label)) {
+	for _, l := range s.info().labels {
+		f(l)
+	}
+}
+
+// Get returns the value of the Label in s with the given name, and
+// whether there is one.
+func (s Set) Get(name string) (string, bool) {
+	ls := s.info().labels
+	i := sort.Search(len(ls), func(i int) bool { return ls[i].Name >= name })
+	if i < len(ls) && ls[i].Name == name {
+		return ls[i].Value, true
+	}
+	return "", false
+}
+
+// Hash returns a hash of s's contents, for use as a series identifier
+// or shard key. Unlike intern.Value.Hash, it doesn't vary between
+// processes: it's the 64-bit FNV-1a hash of s's names and values, in
+// order, each preceded by its length as a uvarint.
+func (s Set) Hash() uint64 {
+	return s.info().hash
+}
+
+// Value returns the interned Value identifying s, or nil if s is
+// empty. Its underlying value is an opaque string, which is valid
+// only within this process.
+func (s Set) Value() *intern.Value {
+	return s.v
+}
+
+// String returns s in the Prometheus style, as in {a="1", b="2"}.
+func (s Set) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, l := range s.info().labels {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(l.Name)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(l.Value))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package labels
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestSet(t *testing.T) {
+	s := FromStrings("job", "api", "instance", "a:80")
+	if s != New(Label{"instance", "a:80"}, Label{"job", "api"}) {
+		t.Error("equal sets built differently differ")
+	}
+	if s != FromMap(map[string]string{"job": "api", "instance": "a:80"}) {
+		t.Error("FromMap differs")
+	}
+	if s == FromStrings("job", "api") || s == FromStrings("job", "api", "instance", "b:80") {
+		t.Error("different sets are equal")
+	}
+	want := []Label{{"instance", "a:80"}, {"job", "api"}}
+	if got := s.Labels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels = %v; want %v", got, want)
+	}
+	if s.Len() != 2 {
+		t.Errorf("Len = %d; want 2", s.Len())
+	}
+	if v, ok := s.Get("job"); !ok || v != "api" {
+		t.Errorf(`Get("job") = %q, %v`, v, ok)
+	}
+	if _, ok := s.Get("zone"); ok {
+		t.Error(`Get("zone") found`)
+	}
+	if got, want := s.String(), `{instance="a:80", job="api"}`; got != want {
+		t.Errorf("String = %s; want %s", got, want)
+	}
+
+	if FromStrings("a", "1", "a", "2") != FromStrings("a", "2") {
+		t.Error("last duplicate name doesn't win")
+	}
+	if New() != (Set{}) || FromMap(nil) != (Set{}) || (Set{}).Len() != 0 {
+		t.Error("empty set isn't the zero Set")
+	}
+	if (Set{}).String() != "{}" {
+		t.Errorf("empty String = %s", Set{}.String())
+	}
+}
+
+func TestSetEncodingUnambiguous(t *testing.T) {
+	a := FromStrings("a", "b=c")
+	b := FromStrings("a=b", "c")
+	c := FromStrings("a", "b", "c", "")
+	if a == b || a == c || b == c {
+		t.Error("sets with separator-like contents confused")
+	}
+	if a.Hash() == b.Hash() || a.Hash() == c.Hash() {
+		t.Error("hashes of different sets collide")
+	}
+}
+
+func TestSetHashStable(t *testing.T) {
+	// The hash is part of the API, and mustn't change.
+	if got, want := FromStrings("a", "1").Hash(), uint64(0xcb003077e97d2ec9); got != want {
+		t.Errorf("Hash = %#x; want %#x", got, want)
+	}
+	if got, want := (Set{}).Hash(), uint64(14695981039346656037); got != want {
+		t.Errorf("empty Hash = %#x; want %#x", got, want)
+	}
+}
+
+func TestSetSharesComponents(t *testing.T) {
+	a := FromStrings("job", string([]byte("api")), "instance", "a:80")
+	b := FromStrings("job", string([]byte("api")), "instance", "b:80")
+	va, _ := a.Get("job")
+	vb, _ := b.Get("job")
+	if stringData(va) != stringData(vb) {
+		t.Error("sets don't share a common value")
+	}
+
+	// A reachable Set keeps its components, and so its key, valid.
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+	}
+	if c := FromStrings("instance", "a:80", "job", "api"); c != a {
+		t.Error("Set changed after GC")
+	}
+}
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
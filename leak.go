@@ -0,0 +1,111 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"sort"
+	"time"
+)
+
+// A RetainedValue is a Value retained by an Interner in safe-but-leaky
+// mode, as reported by Leaks.
+type RetainedValue struct {
+	Value *Value
+	// Size is the size of the value in bytes, as measured by Sizes.
+	Size int
+	// Age is how long ago the value was interned.
+	Age time.Duration
+}
+
+// A LeakReport describes the values retained by an Interner in
+// safe-but-leaky mode, as reported by Leaks.
+type LeakReport struct {
+	// Entries is the number of values retained, and Bytes their
+	// total size, as measured by Sizes.
+	Entries int
+	Bytes   int64
+
+	// Largest holds the largest values retained, in decreasing
+	// order of size, and Oldest the oldest, in decreasing order of
+	// age.
+	Largest []RetainedValue
+	Oldest  []RetainedValue
+}
+
+// Leaks reports on the values retained by the default Interner.
+// See Interner.Leaks.
+func Leaks(n int) LeakReport {
+	return std.Leaks(n)
+}
+
+// Leaks reports on the values retained by in in safe-but-leaky mode,
+// as enabled by GO4_INTERN_SAFE_BUT_LEAKY, in which no value is ever
+// collected. It lists the n largest and n oldest, so that programs
+// that opt into leaking can monitor the damage, and bound it, as with
+// Forget or WithMaxSize.
+//
+// Leaks returns a zero LeakReport unless in is in safe-but-leaky mode.
+// It takes time proportional to the size of in.
+func (in *Interner) Leaks(n int) LeakReport {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	l := in.leaks
+	if l == nil {
+		return LeakReport{}
+	}
+	now := l.now()
+	vals := make([]RetainedValue, 0, len(l.born))
+	for v, t := range l.born {
+		vals = append(vals, RetainedValue{
+			Value: v,
+			Size:  int(keySize(keyFor(v.cmpVal))),
+			Age:   now.Sub(t),
+		})
+	}
+	if n > len(vals) {
+		n = len(vals)
+	}
+	if n < 0 {
+		n = 0
+	}
+	r := LeakReport{Entries: len(vals), Bytes: l.bytes}
+	sort.Slice(vals, func(i, j int) bool { return vals[i].Size > vals[j].Size })
+	r.Largest = append([]RetainedValue(nil), vals[:n]...)
+	sort.Slice(vals, func(i, j int) bool { return vals[i].Age > vals[j].Age })
+	r.Oldest = append([]RetainedValue(nil), vals[:n]...)
+	return r
+}
+
+// leaks tracks the values retained in safe-but-leaky mode.
+// It is guarded by the Interner's mu.
+type leaks struct {
+	now   func() time.Time
+	born  map[*Value]time.Time // insertion time of each retained Value
+	bytes int64                // sum of keySize over born
+}
+
+func newLeaks() *leaks {
+	return &leaks{now: time.Now, born: make(map[*Value]time.Time)}
+}
+
+// inserted records the insertion of v, whose key is k.
+func (l *leaks) inserted(v *Value, k key) {
+	l.born[v] = l.now()
+	l.bytes += int64(keySize(k))
+}
+
+// removed records the removal of v.
+func (l *leaks) removed(v *Value) {
+	if _, ok := l.born[v]; ok {
+		delete(l.born, v)
+		l.bytes -= int64(keySize(keyFor(v.cmpVal)))
+	}
+}
+
+// reset forgets all retained Values.
+func (l *leaks) reset() {
+	l.born = make(map[*Value]time.Time)
+	l.bytes = 0
+}
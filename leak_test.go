@@ -0,0 +1,62 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// newLeaky returns an Interner in safe-but-leaky mode, whatever the
+// environment, with a fake clock.
+func newLeaky(now *time.Time) *Interner {
+	in := New(safeBackend)
+	in.leaks.now = func() time.Time { return *now }
+	return in
+}
+
+func TestLeaks(t *testing.T) {
+	skipIfRefcounted(t)
+	now := time.Unix(1000, 0)
+	in := newLeaky(&now)
+	old := in.GetByString("old")
+	now = now.Add(time.Minute)
+	big := in.GetByString(strings.Repeat("x", 100))
+	in.GetByString("mid-size")
+	in.GetByString("gone")
+	in.Forget("gone")
+	now = now.Add(time.Minute)
+
+	r := in.Leaks(1)
+	if r.Entries != 3 || r.Bytes != 3+100+8 {
+		t.Errorf("Entries, Bytes = %d, %d; want 3, 111", r.Entries, r.Bytes)
+	}
+	if len(r.Largest) != 1 || r.Largest[0].Value != big || r.Largest[0].Size != 100 {
+		t.Errorf("Largest = %+v; want the 100-byte value", r.Largest)
+	}
+	if len(r.Oldest) != 1 || r.Oldest[0].Value != old || r.Oldest[0].Age != 2*time.Minute {
+		t.Errorf("Oldest = %+v; want %q, 2m old", r.Oldest, "old")
+	}
+	if r := in.Leaks(10); len(r.Largest) != 3 || len(r.Oldest) != 3 {
+		t.Errorf("Leaks(10) listed %d and %d values; want 3", len(r.Largest), len(r.Oldest))
+	}
+
+	in.Close()
+	if r := in.Leaks(10); r.Entries != 0 || r.Bytes != 0 {
+		t.Errorf("after Close, Entries, Bytes = %d, %d; want 0, 0", r.Entries, r.Bytes)
+	}
+}
+
+func TestLeaksNotLeaky(t *testing.T) {
+	if safeMap() != nil {
+		t.Skip("in safe-but-leaky mode")
+	}
+	in := New()
+	in.GetByString("x")
+	if r := in.Leaks(10); r.Entries != 0 || r.Largest != nil {
+		t.Errorf("Leaks = %+v; want zero", r)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"time"
+	"unsafe"
+)
+
+const (
+	// lifetimeMin is the bound of a lifetime histogram's first
+	// bucket, and lifetimeBuckets the number of buckets, doubling
+	// each time, up to about 9 hours.
+	lifetimeMin     = time.Millisecond
+	lifetimeBuckets = 25
+)
+
+// SampleLifetimes returns an Option that records, for one in every
+// rate Values inserted, the time from its insertion to its removal,
+// for reporting by Lifetimes. Knowing how long Values live helps in
+// choosing sensible TTLs and generation lengths, and in seeing whether
+// collection of unused Values is keeping up with the garbage
+// collector. A rate of 1 records every Value; SampleLifetimes does
+// nothing if rate is less than 1.
+//
+// A Value is removed when its finalizer runs after it becomes
+// unreachable, or by Forget, Collect, or memory pressure.
+// Lifetimes aren't recorded for Interners using WeakPointers.
+func SampleLifetimes(rate int) Option {
+	return func(in *Interner) {
+		if rate < 1 {
+			return
+		}
+		in.lifetimes = &lifetimes{
+			rate: uint64(rate),
+			now:  time.Now,
+			born: make(map[uintptr]time.Time),
+			hist: newHistogram(lifetimeMin.Seconds(), lifetimeBuckets),
+		}
+	}
+}
+
+// Lifetimes returns the distribution, in seconds, of the lifetimes of
+// the removed Values sampled by SampleLifetimes, and the number of
+// sampled Values not yet removed. It returns a zero Histogram if in
+// wasn't created with SampleLifetimes.
+func (in *Interner) Lifetimes() (h Histogram, live int) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	l := in.lifetimes
+	if l == nil {
+		return Histogram{}, 0
+	}
+	return l.hist.snapshot(), len(l.born)
+}
+
+// lifetimes records the lifetimes of sampled Values.
+// It is guarded by the Interner's mu.
+type lifetimes struct {
+	rate    uint64
+	inserts uint64 // toward the next sample
+	now     func() time.Time
+	born    map[uintptr]time.Time // insertion time of sampled Values, by address
+	hist    *histogram
+}
+
+// inserted records the insertion of v, if it's sampled.
+func (l *lifetimes) inserted(v *Value) {
+	l.inserts++
+	if l.inserts%l.rate == 0 {
+		l.born[uintptr(unsafe.Pointer(v))] = l.now()
+	}
+}
+
+// removed records the removal of v.
+func (l *lifetimes) removed(v *Value) {
+	addr := uintptr(unsafe.Pointer(v))
+	if t, ok := l.born[addr]; ok {
+		l.hist.observe(l.now().Sub(t).Seconds())
+		delete(l.born, addr)
+	}
+}
+
+// reset forgets the sampled Values not yet removed.
+func (l *lifetimes) reset() {
+	l.born = make(map[uintptr]time.Time)
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLifetimes(t *testing.T) {
+	in := New(SampleLifetimes(2))
+	now := time.Unix(1e9, 0)
+	in.lifetimes.now = func() time.Time { return now }
+
+	var vs []*Value
+	for i := 0; i < 6; i++ {
+		vs = append(vs, in.Get(i)) // samples 1, 3, and 5
+	}
+	now = now.Add(3 * time.Second)
+	in.Forget(1)
+	in.Forget(2)
+	now = now.Add(time.Minute)
+	in.Forget(3)
+
+	h, live := in.Lifetimes()
+	if h.Count != 2 || live != 1 {
+		t.Fatalf("Lifetimes = %d observations, %d live; want 2, 1", h.Count, live)
+	}
+	if h.Sum != 66 {
+		t.Errorf("Sum = %v; want 66 seconds", h.Sum)
+	}
+	if q := h.Quantile(0.5); q < 3 || q > 6 {
+		t.Errorf("median bound = %v; want between 3s and 6s", q)
+	}
+	runtime.KeepAlive(vs)
+
+	if h, live := New().Lifetimes(); h.Count != 0 || live != 0 || h.Bounds != nil {
+		t.Error("Lifetimes without SampleLifetimes isn't zero")
+	}
+}
+
+func TestLifetimesFinalized(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(SampleLifetimes(1))
+	in.Get(struct{ x int }{1})
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		if h, _ := in.Lifetimes(); h.Count == 1 {
+			return
+		}
+	}
+	if safeMap() == nil {
+		t.Error("finalized Value's lifetime wasn't recorded")
+	}
+}
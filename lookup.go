@@ -0,0 +1,92 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// Lookup returns the Value for cmpVal if one exists in the default
+// Interner, without creating one if not. See Interner.Lookup.
+func Lookup(cmpVal interface{}) (*Value, bool) {
+	return std.Lookup(cmpVal)
+}
+
+// Lookup returns the Value for cmpVal if cmpVal is interned in in,
+// applying any canonicalization as Get does, and reports whether it
+// was found. Unlike Get, it never adds cmpVal to the table, so it
+// suits read-only paths, such as matching queries against interned
+// values, that mustn't grow the table. Nor does it take a reference
+// in ManualCollect mode, so there is nothing to Release.
+//
+// A Value that is no longer reachable may still be found, until it's
+// collected. Lookup always fails after Close, and while in is disabled
+// or in shadow mode, since Values aren't interned then, except that
+// Lookup of nil always finds the sentinel Value returned by Get(nil).
+// Likewise, strings shorter than WithMinSize are never found, except
+// those in the TinyStrings table, which always are.
+func (in *Interner) Lookup(cmpVal interface{}) (*Value, bool) {
+	k := in.keyOf(cmpVal)
+	if !k.isString && k.cmpVal == nil {
+		return nilValue, true
+	}
+	if in.isClosed() {
+		return nil, false
+	}
+	if in.isShort(k) {
+		if in.tiny && len(k.s) <= maxTiny {
+			return tinyValue(k.s), true
+		}
+		return nil, false
+	}
+	if in.isDisabled() || in.shadow != nil {
+		return nil, false
+	}
+	kh := in.hashKey(k)
+	in.mu.Lock()
+	v := in.lookupLocked(k, kh, getCtx{noRef: true})
+	in.mu.Unlock()
+	if v == nil && in.parent != nil {
+		v = in.inherited(k, getCtx{})
+	}
+	return v, v != nil
+}
+
+// keyOf returns the key for cmpVal after applying in's options,
+// as Get does.
+func (in *Interner) keyOf(cmpVal interface{}) key {
+	if in.canonicalize != nil {
+		cmpVal = in.canonicalize(cmpVal)
+	}
+	if s, ok := cmpVal.(string); ok {
+		return in.stringKey(s)
+	}
+	return key{cmpVal: cmpVal}
+}
+
+// stringKey is keyOf for a string that has already been through any
+// canonicalize option.
+func (in *Interner) stringKey(s string) key {
+	if in.transform != nil {
+		s = in.transform(s)
+	}
+	if in.foldASCII {
+		s = lowerASCII(s)
+	}
+	return key{s: s, isString: true}
+}
+
+// lowerASCII returns s with ASCII upper case letters mapped to lower
+// case, as by the CaseInsensitive option.
+func lowerASCII(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; 'A' <= c && c <= 'Z' {
+			b := []byte(s)
+			for j := i; j < len(b); j++ {
+				if c := b[j]; 'A' <= c && c <= 'Z' {
+					b[j] = c + 'a' - 'A'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for _, opt := range []Option{HashKeys(), CaseInsensitive(), WithTransform(func(s string) string { return s + "!" })} {
+		in := New(opt)
+		foo := in.GetByString("foo")
+		one := in.Get(1)
+		if v, ok := in.Lookup("foo"); !ok || v != foo {
+			t.Errorf("Lookup(foo) = %p, %v; want %p, true", v, ok, foo)
+		}
+		if v, ok := in.Lookup(1); !ok || v != one {
+			t.Errorf("Lookup(1) = %p, %v; want %p, true", v, ok, one)
+		}
+		before := in.Stats().Entries
+		if v, ok := in.Lookup("bar"); ok || v != nil {
+			t.Errorf("Lookup(bar) = %p, %v; want nil, false", v, ok)
+		}
+		if v, ok := in.Lookup(2); ok || v != nil {
+			t.Errorf("Lookup(2) = %p, %v; want nil, false", v, ok)
+		}
+		if after := in.Stats().Entries; after != before {
+			t.Errorf("Lookup grew the table from %d to %d entries", before, after)
+		}
+		runtime.KeepAlive(foo)
+		runtime.KeepAlive(one)
+	}
+
+	in := New(CaseInsensitive())
+	v := in.GetByString("abc")
+	if got, ok := in.Lookup("ABC"); !ok || got != v {
+		t.Error("Lookup didn't fold case")
+	}
+	in.SetDisabled(true)
+	if _, ok := in.Lookup("abc"); ok {
+		t.Error("Lookup succeeded while disabled")
+	}
+	runtime.KeepAlive(v)
+}
+
+func TestLowerASCII(t *testing.T) {
+	for in, want := range map[string]string{
+		"":      "",
+		"abc":   "abc",
+		"aBC":   "abc",
+		"ÄBc":   "Äbc",
+		"A1-_Z": "a1-_z",
+	} {
+		if got := lowerASCII(in); got != want {
+			t.Errorf("lowerASCII(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
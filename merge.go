@@ -0,0 +1,34 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// Merge interns each value in src into dst, as by dst.Get, and
+// returns a map from each of src's Values to dst's Value for the same
+// value, so results computed with src, such as by a worker in a
+// parallel phase, can be rebound to dst's canonical Values.
+//
+// The map holds every Value in src, including those whose values dst
+// already had. Each maps to exactly one of dst's Values, though
+// several may map to the same one if dst's options canonicalize them
+// alike. src itself is unchanged; the clone of an Interner, from
+// Clone, can be merged back into it this way, and then dropped.
+func Merge(dst, src *Interner) map[*Value]*Value {
+	src.mu.Lock()
+	src.drainFinalizedLocked()
+	var vals []*Value
+	src.forEachLocked(func(v *Value) {
+		// We're retaining pointers made from uintptrs.
+		if v.resurrect() {
+			vals = append(vals, v)
+		}
+	})
+	src.mu.Unlock()
+
+	m := make(map[*Value]*Value, len(vals))
+	for _, v := range vals {
+		m[v] = dst.Get(v.cmpVal)
+	}
+	return m
+}
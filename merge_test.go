@@ -0,0 +1,43 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	dst := New()
+	shared := dst.GetByString("shared")
+	src := New()
+	s1, s2, s3 := src.GetByString("shared"), src.GetByString("new"), src.Get(3)
+
+	m := Merge(dst, src)
+	if len(m) != 3 {
+		t.Errorf("got %d rebindings; want 3", len(m))
+	}
+	if m[s1] != shared {
+		t.Error("existing value not rebound to dst's Value")
+	}
+	if m[s2] != dst.GetByString("new") || m[s3] != dst.Get(3) {
+		t.Error("new values not interned in dst")
+	}
+	if v, ok := src.Lookup("new"); !ok || v != s2 {
+		t.Error("src changed")
+	}
+}
+
+func TestMergeClone(t *testing.T) {
+	in := New()
+	a := in.GetByString("a")
+	c := in.Clone()
+	c.GetByString("a")
+	b := c.GetByString("b")
+	m := Merge(in, c)
+	if len(m) != 1 || m[b] != in.GetByString("b") {
+		t.Errorf("Merge of clone = %v; want only b", m)
+	}
+	if in.GetByString("a") != a {
+		t.Error("original's Value changed")
+	}
+}
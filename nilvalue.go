@@ -0,0 +1,22 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// nilValue is the Value returned by Get(nil), from every Interner.
+//
+// It's never in any table, so it's never collected, forgotten, or
+// counted in Stats, and it's the same Value regardless of the
+// Interner's options, whether it's disabled, and so on. Its in field
+// is nil.
+var nilValue = &Value{}
+
+// orNil returns v, or nilValue if v is nil, so that methods on a nil
+// *Value can behave as if called on Get(nil).
+func (v *Value) orNil() *Value {
+	if v == nil {
+		return nilValue
+	}
+	return v
+}
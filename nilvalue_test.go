@@ -0,0 +1,83 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestNilValue(t *testing.T) {
+	in := New()
+	nv := in.Get(nil)
+	if nv != Get(nil) || nv != New(CountHits()).Get(nil) || nv != in.Tenant("t", 0).Get(nil) {
+		t.Error("Get(nil) differs between Interners")
+	}
+	if v, err := in.TryGet(nil); err != nil || v != nv {
+		t.Errorf("TryGet(nil) = %p, %v; want %p, nil", v, err, nv)
+	}
+	if v, ok := in.Lookup(nil); !ok || v != nv {
+		t.Errorf("Lookup(nil) = %p, %v; want %p, true", v, ok, nv)
+	}
+	if in.Forget(nil) {
+		t.Error("Forget(nil) = true")
+	}
+	if in.Get(nil) != nv {
+		t.Error("Get(nil) changed after Forget")
+	}
+	if n := in.Stats().Entries; n != 0 {
+		t.Errorf("Entries = %d after Get(nil); want 0", n)
+	}
+
+	disabled := New(Disabled())
+	if disabled.Get(nil) != nv {
+		t.Error("Get(nil) of disabled Interner isn't the sentinel")
+	}
+
+	runtime.GC()
+	runtime.GC()
+	if w, ok := nv.Weak().Strong(); !ok || w != nv {
+		t.Error("Get(nil) collected")
+	}
+}
+
+func TestNilReceiver(t *testing.T) {
+	var v *Value
+	if v.Get() != nil {
+		t.Error("Get on nil not nil")
+	}
+	if v.Aux() != nil {
+		t.Error("Aux on nil not nil")
+	}
+	if v.Hash() != Get(nil).Hash() {
+		t.Error("Hash on nil differs from Get(nil)")
+	}
+	if v.Pointer() != 0 {
+		t.Error("Pointer on nil not zero")
+	}
+	if v.Compare(Get(nil)) != 0 || Get(nil).Compare(v) != 0 {
+		t.Error("nil doesn't compare equal to Get(nil)")
+	}
+	if !v.Less(GetByString("a")) {
+		t.Error("nil doesn't order before a string")
+	}
+	if b, err := v.MarshalJSON(); err != nil || string(b) != "null" {
+		t.Errorf("MarshalJSON on nil = %q, %v", b, err)
+	}
+	if _, err := v.MarshalText(); err == nil {
+		t.Error("MarshalText on nil succeeded")
+	}
+	if v.Symbol() != Get(nil).Symbol() {
+		t.Error("Symbol on nil differs from Get(nil)")
+	}
+	if w, ok := v.Weak().Strong(); !ok || w != Get(nil) {
+		t.Errorf("Weak on nil Strong = %p, %v; want Get(nil)", w, ok)
+	}
+	v.Release() // doesn't panic
+
+	in := New(ManualCollect())
+	in.Get(nil).Release()
+	in.Get(nil).Release() // never over-released
+}
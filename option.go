@@ -0,0 +1,131 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// An Option configures an Interner created by New.
+type Option func(*Interner)
+
+// WithCanonicalize returns an Option that passes every value through
+// f before it is interned, so that all callers of the Interner share
+// one normalization policy (trimming whitespace, cleaning paths,
+// resolving aliases, and so on) rather than each call site applying
+// its own. Values that f maps to the same result share a Value.
+//
+// f must return a comparable value, and if called on a value it
+// returned, must return it unchanged. It runs before any WithTransform
+// or CaseInsensitive processing of strings.
+//
+// Because f takes an interface{}, an Interner with a WithCanonicalize
+// Option allocates in GetByString to box the string argument.
+func WithCanonicalize(f func(interface{}) interface{}) Option {
+	return func(in *Interner) {
+		if prev := in.canonicalize; prev != nil {
+			in.canonicalize = func(v interface{}) interface{} { return f(prev(v)) }
+		} else {
+			in.canonicalize = f
+		}
+	}
+}
+
+// WithTransform returns an Option that canonicalizes every string
+// with f before it is interned, so that strings with the same
+// canonical form share a Value, whose underlying value is the
+// canonical form.
+//
+// Only string values (whether passed to Get or GetByString) are
+// transformed. If f is called on a string it returned, it must return
+// that string unchanged.
+//
+// For example, to intern internationalized identifiers so that
+// visually identical strings with different Unicode compositions
+// share a Value, normalize them to NFC with golang.org/x/text:
+//
+//	in := intern.New(intern.WithTransform(norm.NFC.String))
+//
+// (This package has no dependencies, so it doesn't provide a
+// normalization option of its own.)
+func WithTransform(f func(string) string) Option {
+	return func(in *Interner) {
+		if prev := in.transform; prev != nil {
+			in.transform = func(s string) string { return f(prev(s)) }
+		} else {
+			in.transform = f
+		}
+	}
+}
+
+// CaseInsensitive returns an Option that interns strings without
+// regard to ASCII case, as is appropriate for HTTP header names and
+// DNS labels. Strings are canonicalized to lower case.
+//
+// It is like WithTransform with an ASCII-only strings.ToLower, but
+// doesn't allocate a lowered copy of a string that's already interned.
+// It is applied after any WithTransform function.
+func CaseInsensitive() Option {
+	return func(in *Interner) { in.foldASCII = true }
+}
+
+// getFolded is GetByString for an Interner with foldASCII set.
+func (in *Interner) getFolded(s string, ctx getCtx) *Value {
+	upper := -1
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; 'A' <= c && c <= 'Z' {
+			upper = i
+			break
+		}
+	}
+	if upper < 0 {
+		return in.get(key{s: s, isString: true}, ctx)
+	}
+
+	var buf [64]byte
+	var b []byte
+	if len(s) <= len(buf) {
+		b = buf[:len(s)]
+	} else {
+		b = make([]byte, len(s))
+	}
+	copy(b, s[:upper])
+	for i := upper; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b[i] = c
+	}
+
+	// Look up the lowered string in place, only copying
+	// it out of b if we need to insert it.
+	k := key{s: bytesToString(b), isString: true}
+	if !in.isDisabled() {
+		kh := in.hashString(k.s)
+		in.mu.Lock()
+		v := in.lookupLocked(k, kh, ctx)
+		in.mu.Unlock()
+		if v != nil {
+			return v
+		}
+	}
+	return in.get(key{s: string(b), isString: true}, ctx)
+}
+
+// HashKeys returns an Option that indexes the table by a 128-bit hash
+// of each value rather than by the value itself.
+//
+// The index then contains no pointers, so it is smaller, and the
+// garbage collector needn't scan it, which matters for tables of many
+// long strings. The cost is hashing each value on every Get, and
+// a bit of reflection for non-string values.
+//
+// Hash collisions are detected. A value whose hash collides with a
+// different interned value isn't interned: Get returns a fresh Value
+// for it, as if the Interner had been configured with WithMinCount
+// and the value had not yet been admitted.
+func HashKeys() Option {
+	return func(in *Interner) {
+		in.hashKeys = true
+		in.hashMap = map[[16]byte]uintptr{}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// WithParent returns an Option that makes the Interner a child of
+// parent, such as a per-session dictionary over a shared base
+// vocabulary.
+//
+// A Get that doesn't find its value in the child looks in parent, and
+// its ancestors, and returns the Value found there, if any. Only
+// values found in neither are added, to the child. Lookup likewise
+// falls back to parent. Closing the child drops the values it added,
+// leaving parent's alone.
+//
+// Values are canonicalized by the child's options, not parent's, on
+// their way to parent, so a child should be configured like its
+// parent. A value parent gains after the child has added it is still
+// found in the child first.
+func WithParent(parent *Interner) Option {
+	return func(in *Interner) { in.parent = parent }
+}
+
+// inherited returns the Value for k in in's ancestors, or nil if
+// there is none. It takes no reference to the Value, and counts no
+// hit if ctx is a composite's part.
+func (in *Interner) inherited(k key, ctx getCtx) *Value {
+	pctx := getCtx{noRef: true, part: ctx.part}
+	for p := in.parent; p != nil; p = p.parent {
+		if p.isDisabled() || p.shadow != nil {
+			continue
+		}
+		if v := p.constantGet(k, pctx); v != nil {
+			return v
+		}
+		kh := p.hashKey(k)
+		p.mu.Lock()
+		v := p.lookupLocked(k, kh, pctx)
+		p.mu.Unlock()
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// Clone returns a new Interner, configured with the same options as
+// in, that shares in's values copy-on-write: it's a child of in, as by
+// WithParent, so Gets of values in in return in's Values, while values
+// added to the clone go only to the clone. Cloning takes constant
+// time, however many values in holds.
+//
+// A clone lets a pipeline stage intern speculatively, then either
+// merge its values back into in, with Merge, or discard them by
+// closing or dropping the clone. Values added to in after cloning
+// are visible in the clone too.
+func (in *Interner) Clone() *Interner {
+	opts := append(in.opts[:len(in.opts):len(in.opts)], WithParent(in))
+	return New(opts...)
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "testing"
+
+func TestParent(t *testing.T) {
+	base := New()
+	shared := base.GetByString("GET")
+	sess := New(WithParent(base))
+	sub := New(WithParent(sess))
+
+	if sess.GetByString("GET") != shared || sub.Get("GET") != shared {
+		t.Error("child didn't find parent's Value")
+	}
+	own := sess.GetByString("session-only")
+	if sess.GetByString("session-only") != own || sub.GetByString("session-only") != own {
+		t.Error("child's Value not canonical")
+	}
+	if _, ok := base.Lookup("session-only"); ok {
+		t.Error("child's insert went to parent")
+	}
+	if v, ok := sub.Lookup("GET"); !ok || v != shared {
+		t.Error("Lookup didn't fall back to ancestors")
+	}
+	if n := sess.Stats().Entries; n != 1 {
+		t.Errorf("child has %d entries; want 1", n)
+	}
+
+	// A value the child added first stays the child's.
+	base.GetByString("session-only")
+	if sess.GetByString("session-only") != own {
+		t.Error("child's Value replaced by parent's")
+	}
+
+	sess.Close()
+	if sess.Stats().Entries != 0 {
+		t.Error("Close didn't drop child's additions")
+	}
+	if base.GetByString("GET") != shared {
+		t.Error("Close of child dropped parent's Values")
+	}
+}
+
+func TestParentAllocs(t *testing.T) {
+	base := New()
+	base.GetByString("x")
+	child := New(WithParent(base))
+	n := testing.AllocsPerRun(100, func() { child.GetByString("x") })
+	if n > 0 {
+		t.Errorf("GetByString found in parent allocs = %v; want 0", n)
+	}
+}
+
+func TestClone(t *testing.T) {
+	in := New(CaseInsensitive())
+	a := in.GetByString("a")
+	c := in.Clone()
+	if c.GetByString("A") != a {
+		t.Error("clone didn't share existing Value")
+	}
+	b := c.GetByString("B")
+	if b.Get() != "b" {
+		t.Errorf("clone lost options: Get = %q", b.Get())
+	}
+	if _, ok := in.Lookup("b"); ok {
+		t.Error("clone's Value added to original")
+	}
+	if c.Clone().GetByString("b") != b {
+		t.Error("clone of clone didn't share clone's Value")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrHasPointers is returned (wrapped) by TryGet, for an Interner
+// created with RejectPointers, when passed a value containing
+// pointers.
+var ErrHasPointers = errors.New("intern: value contains pointers")
+
+// RejectPointers returns an Option that makes the Interner refuse
+// values whose types contain pointers, including interfaces, other
+// than strings: Get panics and TryGet returns an error wrapping
+// ErrHasPointers.
+//
+// An interned value keeps everything it points to reachable for as
+// long as it's interned, which may be several GC cycles after its
+// Value is last used. That's rarely intended, and is easy to do by
+// accident, such as by interning a struct with a pointer field.
+//
+// Whether a type contains pointers is determined once per type.
+func RejectPointers() Option {
+	return func(in *Interner) { in.rejectPointers = true }
+}
+
+// checkPointers returns an error if in rejects pointers and cmpVal's
+// type contains them.
+func (in *Interner) checkPointers(cmpVal interface{}) error {
+	if !in.rejectPointers || cmpVal == nil {
+		return nil
+	}
+	if _, ok := cmpVal.(string); ok {
+		return nil
+	}
+	if t := reflect.TypeOf(cmpVal); typeHasPointers(t) {
+		if in.onEvent != nil {
+			in.onEvent(Event{Kind: EventRejected, Reason: "pointers", Type: t.String(), Size: int(t.Size())})
+		}
+		return fmt.Errorf("%w: %T", ErrHasPointers, cmpVal)
+	}
+	return nil
+}
+
+// hasPointers caches typeHasPointers: a map of reflect.Type to bool.
+var hasPointers sync.Map
+
+// typeHasPointers reports whether values of type t contain pointers
+// other than those of strings.
+func typeHasPointers(t reflect.Type) bool {
+	if v, ok := hasPointers.Load(t); ok {
+		return v.(bool)
+	}
+	has := computeHasPointers(t)
+	hasPointers.Store(t, has)
+	return has
+}
+
+func computeHasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.String:
+		return false
+	case reflect.Array:
+		return t.Len() > 0 && computeHasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if computeHasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
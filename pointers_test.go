@@ -0,0 +1,59 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+)
+
+func TestRejectPointers(t *testing.T) {
+	type flat struct {
+		a int
+		s string
+		b [2]bool
+	}
+	type withPtr struct {
+		a int
+		p *int
+	}
+	type nested struct {
+		f  flat
+		ws [1]withPtr
+	}
+	x := 1
+	in := New(RejectPointers())
+	for _, v := range []interface{}{
+		1, "foo", 1.5, true, flat{1, "x", [2]bool{true}}, [0]*int{}, nil,
+	} {
+		if _, err := in.TryGet(v); err != nil {
+			t.Errorf("TryGet(%#v) = %v; want success", v, err)
+		}
+	}
+	for _, v := range []interface{}{
+		&x, withPtr{1, &x}, nested{}, [1]interface{}{1},
+		make(chan int), unsafe.Pointer(&x), struct{ e error }{},
+	} {
+		if _, err := in.TryGet(v); !errors.Is(err, ErrHasPointers) {
+			t.Errorf("TryGet(%#v) = %v; want ErrHasPointers", v, err)
+		}
+	}
+
+	func() {
+		defer func() {
+			if err, _ := recover().(error); !errors.Is(err, ErrHasPointers) {
+				t.Errorf("Get recovered %v; want ErrHasPointers", err)
+			}
+		}()
+		in.Get(withPtr{})
+		t.Error("Get didn't panic")
+	}()
+
+	// Without the option, pointers are interned as before.
+	if _, err := New().TryGet(&x); err != nil {
+		t.Errorf("TryGet without RejectPointers = %v", err)
+	}
+}
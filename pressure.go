@@ -0,0 +1,56 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "sort"
+
+// evictFraction is the fraction of Values evicted each time memory
+// pressure is found.
+const evictFraction = 4 // one quarter
+
+// WithMemoryPressure returns an Option that evicts the least-used
+// Values when the process's memory use exceeds fraction of its memory
+// limit (see runtime/debug.SetMemoryLimit and GOMEMLIMIT), so that the
+// Interner gives up memory for the rest of the program, like a cache
+// of soft references.
+//
+// Memory use is checked on each tick of the janitor, so the Interner
+// must also be created with WithJanitor. Each time it exceeds the
+// threshold, the quarter of Values with the fewest hits (see
+// CountHits, which WithMemoryPressure implies) are removed.
+//
+// An evicted Value that is still in use stays valid, but, as after
+// Forget, is no longer canonical: a later Get of its value returns a
+// different Value. So WithMemoryPressure suits uses that don't
+// compare Values obtained at different times, such as deduplicating
+// strings with String.
+//
+// WithMemoryPressure requires Go 1.19, and has no effect without a
+// memory limit.
+func WithMemoryPressure(fraction float64) Option {
+	return func(in *Interner) {
+		in.pressure = fraction
+		in.countHits = true
+	}
+}
+
+// evictCold removes the least-hit fraction of in's Values.
+func (in *Interner) evictCold() {
+	defer in.flushEvents()
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	var vals []*Value
+	in.forEachLocked(func(v *Value) { vals = append(vals, v) })
+	sort.Slice(vals, func(i, j int) bool { return vals[i].hitCount() < vals[j].hitCount() })
+	n := (len(vals) + evictFraction - 1) / evictFraction
+	for _, v := range vals[:n] {
+		in.removeLocked(v)
+	}
+	in.pressureEvictions += uint64(n)
+	if n > 0 {
+		in.dropSnapshot()
+		in.eventLocked(Event{Kind: EventEvicted, Count: n, Reason: "pressure"})
+	}
+}
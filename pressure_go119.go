@@ -0,0 +1,37 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.19
+// +build go1.19
+
+package intern
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+)
+
+// memoryPressure reports whether the Go runtime's memory use exceeds
+// fraction of its memory limit, measured as the runtime measures it
+// against the limit: all memory mapped by the runtime, less heap
+// memory returned to the OS.
+func memoryPressure(fraction float64) bool {
+	limit := debug.SetMemoryLimit(-1)
+	if limit <= 0 || limit == math.MaxInt64 {
+		return false
+	}
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	for _, s := range samples {
+		if s.Value.Kind() != metrics.KindUint64 {
+			return false
+		}
+	}
+	used := samples[0].Value.Uint64() - samples[1].Value.Uint64()
+	return float64(used) > fraction*float64(limit)
+}
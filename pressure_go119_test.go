@@ -0,0 +1,30 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.19
+// +build go1.19
+
+package intern
+
+import (
+	"math"
+	"runtime/debug"
+	"testing"
+)
+
+func TestMemoryPressure(t *testing.T) {
+	old := debug.SetMemoryLimit(math.MaxInt64)
+	defer debug.SetMemoryLimit(old)
+	if memoryPressure(0.5) {
+		t.Error("pressure reported without a limit")
+	}
+	debug.SetMemoryLimit(1 << 40)
+	if memoryPressure(0.5) {
+		t.Error("pressure reported far below the limit")
+	}
+	debug.SetMemoryLimit(1 << 20)
+	if !memoryPressure(0.5) {
+		t.Error("no pressure reported above the limit")
+	}
+}
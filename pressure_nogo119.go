@@ -0,0 +1,11 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.19
+// +build !go1.19
+
+package intern
+
+// memoryPressure reports false: memory limits require Go 1.19.
+func memoryPressure(fraction float64) bool { return false }
@@ -0,0 +1,36 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestEvictCold(t *testing.T) {
+	in := New(WithMemoryPressure(0.9))
+	var vals []*Value
+	for i := 0; i < 8; i++ {
+		v := in.Get(i)
+		for j := 0; j < i; j++ {
+			in.Get(i)
+		}
+		vals = append(vals, v)
+	}
+	in.evictCold()
+	if st := in.Stats(); st.Entries != 6 || st.Evicted != 2 {
+		t.Errorf("after eviction, Stats = %+v; want 6 entries, 2 evicted", st)
+	}
+	for i, v := range vals {
+		_, ok := in.Lookup(i)
+		if want := i >= 2; ok != want {
+			t.Errorf("Lookup(%d) = %v; want %v", i, ok, want)
+		}
+		if v.Get() != i {
+			t.Errorf("evicted Value changed to %v", v.Get())
+		}
+	}
+	runtime.KeepAlive(vals)
+}
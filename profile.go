@@ -0,0 +1,48 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime/pprof"
+	"sync"
+)
+
+// ProfileName is the name of the runtime/pprof profile maintained
+// for Interners created with the Profile option.
+const ProfileName = "go4.org/intern.values"
+
+var (
+	profileOnce sync.Once
+	profile     *pprof.Profile
+)
+
+// valuesProfile returns the profile named ProfileName,
+// creating it if needed.
+func valuesProfile() *pprof.Profile {
+	profileOnce.Do(func() {
+		profile = pprof.NewProfile(ProfileName)
+	})
+	return profile
+}
+
+// Profile returns an Option that records, in the runtime/pprof profile
+// named by ProfileName, the call stack that interned each Value
+// currently in the Interner, so that growth of the table can be
+// attributed to the code responsible, as with a heap profile.
+//
+// The profile is served by net/http/pprof alongside the standard
+// profiles, or can be written with pprof.Lookup(ProfileName). Like
+// other custom profiles, it counts Values; it doesn't track their
+// sizes. A Value is removed from the profile when it's finalized.
+//
+// Recording a stack on every insertion is costly; Profile is best
+// used for investigation rather than left enabled.
+// It has no effect in safe-but-leaky mode.
+func Profile() Option {
+	return func(in *Interner) {
+		in.profile = true
+		valuesProfile()
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bytes"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+func profiledGet(in *Interner, i int) *Value {
+	return in.Get(i)
+}
+
+func TestProfile(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("profile not maintained in safe-but-leaky mode")
+	}
+	in := New(Profile())
+	p := pprof.Lookup(ProfileName)
+	if p == nil {
+		t.Fatal("profile not registered")
+	}
+	base := p.Count()
+
+	vals := make([]*Value, 5)
+	for i := range vals {
+		vals[i] = profiledGet(in, i)
+	}
+	profiledGet(in, 0)
+	if got := p.Count() - base; got != len(vals) {
+		t.Errorf("profile count grew by %d; want %d", got, len(vals))
+	}
+
+	var buf bytes.Buffer
+	p.WriteTo(&buf, 1)
+	if !strings.Contains(buf.String(), "profiledGet") {
+		t.Errorf("profile doesn't mention caller:\n%s", buf.String())
+	}
+
+	runtime.KeepAlive(vals)
+	vals = nil
+	for try := 0; try < 1000 && p.Count() > base; try++ {
+		runtime.GC()
+	}
+	if got := p.Count() - base; got != 0 {
+		t.Errorf("profile count = %d more than base after collection; want 0", got)
+	}
+}
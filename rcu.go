@@ -0,0 +1,112 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "sync/atomic"
+
+// RCU returns an Option that, like Seqlock, lets Gets of values
+// already interned read the table without taking the Interner's lock,
+// but in the manner of read-copy-update, so that readers aren't
+// disturbed by writes elsewhere in the table.
+//
+// Writers publish each new version of the table's slots atomically,
+// and update slots in place atomically. A reader making a Value
+// pointer from a slot's address checks that the slot, and the version
+// of the slots it read, are still current, acting as its own hazard
+// pointer: the Value was then still in the table, so hasn't been
+// freed, and the pointer now keeps it from being freed. Only if that
+// slot was itself written, or the table resized, does the reader fall
+// back to taking the lock. Under Seqlock, by contrast, any write to
+// the table sends concurrent readers back to retry.
+//
+// Hits are also counted on counters spread over cache lines, rather
+// than a single shared one, so that, on machines with many cores,
+// readers share no written memory but that of the Values they find.
+//
+// RCU has no effect on the same Interners as Seqlock; see Seqlock.
+// If both options are given, RCU is used.
+func RCU() Option {
+	return func(in *Interner) { in.rcu = true }
+}
+
+// stripes is the number of counters over which a stripedCounter
+// spreads its count.
+const stripes = 16
+
+// A stripedCounter is a counter spread over cache lines, to be
+// incremented concurrently without contention.
+type stripedCounter [stripes]struct {
+	n uint64
+	_ [56]byte // pad to a cache line
+}
+
+// add increments the counter's stripe for h, a hash.
+func (c *stripedCounter) add(h uint64) {
+	atomic.AddUint64(&c[h%stripes].n, 1)
+}
+
+// load returns the count.
+func (c *stripedCounter) load() uint64 {
+	if c == nil {
+		return 0
+	}
+	var n uint64
+	for i := range c {
+		n += atomic.LoadUint64(&c[i].n)
+	}
+	return n
+}
+
+// rcuGet returns the Value for k, whose hash is kh, if it can be
+// found without the lock, or nil. It counts a hit unless ctx is a
+// composite's part.
+func (in *Interner) rcuGet(k key, kh keyHash, ctx getCtx) *Value {
+	v := in.tab.findRCU(k, kh.tab)
+	if v == nil || !v.resurrect() {
+		return nil
+	}
+	if !ctx.part {
+		in.rcuHits.add(kh.tab)
+	}
+	return v
+}
+
+// findRCU is find for a shared table, without the lock. It returns
+// nil if k isn't found, or if the slots it reads are changed while
+// it reads them. See RCU.
+//
+//go:nocheckptr
+func (t *table) findRCU(k key, h uint64) *Value {
+	pub := atomic.LoadPointer(&t.pub)
+	if pub == nil {
+		return nil
+	}
+	slots := *(*[]slot)(pub)
+	if len(slots) == 0 {
+		return nil
+	}
+	mask := uint64(len(slots) - 1)
+	i := h & mask
+	for n := 0; n < len(slots); n, i = n+1, (i+1)&mask {
+		s := &slots[i]
+		addr := atomic.LoadUintptr(&s.addr)
+		if addr == empty {
+			return nil
+		}
+		if addr == tombstone || atomic.LoadUint64(&s.hash) != h {
+			continue
+		}
+		v := valueAt(addr)
+		if atomic.LoadUintptr(&s.addr) != addr || atomic.LoadPointer(&t.pub) != pub {
+			// v may have been removed, and freed, before it
+			// was converted: don't read it.
+			return nil
+		}
+		if keyFor(v.cmpVal) == k {
+			return v
+		}
+	}
+	return nil
+}
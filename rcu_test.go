@@ -0,0 +1,90 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestRCU(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(RCU(), Seqlock())
+	if safeMap() == nil && (!in.rcu || in.seqlock) {
+		t.Fatalf("rcu, seqlock = %v, %v; want RCU alone in effect", in.rcu, in.seqlock)
+	}
+	v := in.GetByString("a")
+	if in.GetByString("a") != v || in.Get(1) != in.Get(1) {
+		t.Error("Gets with RCU aren't canonical")
+	}
+	if st := in.Stats(); st.Hits != 2 || st.Misses != 2 {
+		t.Errorf("Hits, Misses = %d, %d; want 2, 2", st.Hits, st.Misses)
+	}
+	if in.rcu && in.rcuHits.load() != 2 {
+		t.Errorf("rcuHits = %d; want 2", in.rcuHits.load())
+	}
+	in.Forget("a")
+	if in.GetByString("a") == v {
+		t.Error("Get after Forget returned the forgotten Value")
+	}
+	runtime.KeepAlive(v)
+
+	if New(RCU(), CountHits()).rcu {
+		t.Error("RCU in effect with CountHits")
+	}
+}
+
+func TestRCUConcurrent(t *testing.T) {
+	in := New(RCU())
+	const n = 1000
+	keep := make([]*Value, n)
+	for i := range keep {
+		keep[i] = in.GetByString("k" + strconv.Itoa(i))
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for round := 0; round < 10; round++ {
+				for i, want := range keep {
+					if got := in.GetByString("k" + strconv.Itoa(i)); got != want {
+						t.Errorf("Get of k%d = %p; want %p", i, got, want)
+						return
+					}
+				}
+			}
+		}()
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				s := "g" + strconv.Itoa(g) + "-" + strconv.Itoa(i)
+				in.GetByString(s)
+				if i%3 == 0 {
+					in.Forget(s)
+				}
+			}
+			runtime.GC()
+		}(g)
+	}
+	wg.Wait()
+	runtime.KeepAlive(keep)
+}
+
+func TestStripedCounter(t *testing.T) {
+	c := new(stripedCounter)
+	for h := uint64(0); h < 100; h++ {
+		c.add(h * 7)
+	}
+	if got := c.load(); got != 100 {
+		t.Errorf("load = %d; want 100", got)
+	}
+	var nilc *stripedCounter
+	if nilc.load() != 0 {
+		t.Error("nil counter isn't zero")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// A Ref is a reference to an interned Value, held by value, in the
+// style of unique.Handle. Like *Value, Refs are comparable: two Refs
+// are equal if and only if they refer to the same Value.
+//
+// Unlike a *Value, a Ref can't be built by hand to refer to a Value
+// that wasn't returned by Get, as &Value{} can. The zero Ref refers
+// to nothing; its methods other than IsZero panic, rather than
+// quietly treating it as a real Value. Holding a Ref keeps its Value
+// reachable, as holding a *Value does.
+type Ref struct {
+	v *Value
+}
+
+// GetRef is like Get, but returns a Ref.
+func GetRef(cmpVal interface{}) Ref {
+	return Ref{Get(cmpVal)}
+}
+
+// GetRefByString is like GetByString, but returns a Ref.
+func GetRefByString(s string) Ref {
+	return Ref{GetByString(s)}
+}
+
+// GetRef is like the package-level GetRef, but uses in's table.
+func (in *Interner) GetRef(cmpVal interface{}) Ref {
+	return Ref{in.Get(cmpVal)}
+}
+
+// GetRefByString is like the package-level GetRefByString, but uses
+// in's table.
+func (in *Interner) GetRefByString(s string) Ref {
+	return Ref{in.GetByString(s)}
+}
+
+// Ref returns a Ref to v. A nil v gives a Ref to Get(nil).
+func (v *Value) Ref() Ref {
+	return Ref{v.orNil()}
+}
+
+// IsZero reports whether r is the zero Ref.
+func (r Ref) IsZero() bool {
+	return r.v == nil
+}
+
+// Value returns the Value r refers to. It panics if r is zero.
+func (r Ref) Value() *Value {
+	if r.v == nil {
+		panic("intern: use of zero Ref")
+	}
+	return r.v
+}
+
+// Get returns the comparable value r refers to, as Value.Get does.
+// It panics if r is zero.
+func (r Ref) Get() interface{} {
+	return r.Value().cmpVal
+}
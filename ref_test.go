@@ -0,0 +1,59 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "testing"
+
+func TestRef(t *testing.T) {
+	in := New()
+	a := in.GetRefByString("a")
+	if a != in.GetRef("a") || a != in.GetByString("a").Ref() {
+		t.Error("Refs to equal values differ")
+	}
+	if a == in.GetRef("b") || a == GetRefByString("a") {
+		t.Error("Refs to different Values are equal")
+	}
+	if a.IsZero() || a.Get() != "a" || a.Value() != in.GetByString("a") {
+		t.Errorf("Ref = %v, %v, %p", a.IsZero(), a.Get(), a.Value())
+	}
+	if r := (*Value)(nil).Ref(); r != GetRef(nil) || r.Get() != nil {
+		t.Error("Ref of nil isn't Ref of Get(nil)")
+	}
+	m := map[Ref]int{a: 1}
+	if m[in.GetRefByString("a")] != 1 {
+		t.Error("Ref map lookup failed")
+	}
+
+	var zero Ref
+	if !zero.IsZero() {
+		t.Error("zero Ref not IsZero")
+	}
+	for name, f := range map[string]func(){
+		"Value": func() { zero.Value() },
+		"Get":   func() { zero.Get() },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s on zero Ref didn't panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestRefAllocs(t *testing.T) {
+	in := New()
+	r := in.GetRefByString("x")
+	n := testing.AllocsPerRun(100, func() {
+		if in.GetRefByString("x") != r {
+			t.Fatal("Ref changed")
+		}
+	})
+	if n > 0 {
+		t.Errorf("GetRefByString allocs = %v; want 0", n)
+	}
+}
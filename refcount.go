@@ -0,0 +1,44 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// Refcounted returns an Option that makes the Interner count
+// references explicitly instead of relying on finalizers, for runtimes
+// where finalizers are unavailable or unreliable, such as TinyGo.
+//
+// As with ManualCollect, no finalizers are installed, and each Get of
+// a value, and each Acquire of its Value, counts a reference, which
+// Release drops. Unlike with ManualCollect, a Value is removed as soon
+// as its count drops to zero, without waiting for Collect. A Value
+// that is never Released stays interned for the life of the Interner.
+//
+// Building with the intern_refcount build tag makes every Interner,
+// including the default one, Refcounted.
+func Refcounted() Option {
+	return func(in *Interner) {
+		ManualCollect()(in)
+		in.refcounted = true
+	}
+}
+
+// Acquire takes another reference to v, in an Interner created with
+// ManualCollect or Refcounted, such as when passing v to code that will
+// Release it independently, and returns v. With Refcounted, it panics
+// if v's references have all been Released, as v has then been
+// removed. In other Interners, it does nothing.
+func (v *Value) Acquire() *Value {
+	in := v.orNil().in
+	if in == nil || !in.manual {
+		return v
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	e := v.addExt()
+	if e.refs <= 0 && in.refcounted {
+		panic("intern: Acquire of released Value")
+	}
+	e.refs++
+	return v
+}
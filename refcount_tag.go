@@ -0,0 +1,11 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build intern_refcount
+// +build intern_refcount
+
+package intern
+
+// defaultRefcounted reports whether every Interner is Refcounted.
+const defaultRefcounted = true
@@ -0,0 +1,71 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "testing"
+
+func TestRefcounted(t *testing.T) {
+	in := New(Refcounted())
+	a := in.GetByString("a")
+	if in.GetByString("a") != a {
+		t.Fatal("Values differ")
+	}
+	if a.Acquire() != a {
+		t.Fatal("Acquire didn't return its Value")
+	}
+	a.Release()
+	a.Release()
+	if got := in.Stats().Entries; got != 1 {
+		t.Errorf("Entries = %d with a reference left; want 1", got)
+	}
+	a.Release()
+	if got := in.Stats().Entries; got != 0 {
+		t.Errorf("Entries = %d after the last Release; want 0", got)
+	}
+	if in.GetByString("a") == a {
+		t.Error("Get after the last Release returned the removed Value")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Acquire of released Value didn't panic")
+			}
+		}()
+		a.Acquire()
+	}()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("over-Release didn't panic")
+			}
+		}()
+		a.Release()
+	}()
+}
+
+func TestAcquireManualCollect(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(ManualCollect())
+	v := in.GetByString("x")
+	v.Release()
+	v.Acquire() // revives it before Collect
+	if n := in.Collect(); n != 0 {
+		t.Errorf("Collect = %d; want 0", n)
+	}
+	var nilv *Value
+	if nilv.Acquire() != nil || New().GetByString("y").Acquire() == nil {
+		t.Error("Acquire outside ManualCollect changed its Value")
+	}
+}
+
+// skipIfRefcounted skips t when built with the intern_refcount tag,
+// which makes every Interner Refcounted, for tests of finalization,
+// of the table it replaces, or of ManualCollect without Refcounted.
+func skipIfRefcounted(t *testing.T) {
+	if defaultRefcounted {
+		t.Skip("every Interner is Refcounted with intern_refcount")
+	}
+}
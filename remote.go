@@ -0,0 +1,111 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A RemoteAllocator assigns Symbols to strings on behalf of a group of
+// processes, such as a fleet of workers sharing one coordinator, so
+// that all of them agree on the Symbol for each string.
+//
+// Package go4.org/intern/symrpc contains a reference implementation.
+type RemoteAllocator interface {
+	// Allocate returns the Symbol for s, assigning one if needed.
+	Allocate(s string) (Symbol, error)
+	// Resolve returns the string that was assigned sym.
+	Resolve(sym Symbol) (string, error)
+}
+
+// RemoteSymbols maps between strings and the Symbols assigned to them
+// by a RemoteAllocator, caching each answer so that the allocator is
+// only consulted once per string or Symbol.
+//
+// Symbols from a RemoteSymbols are in the allocator's symbol space,
+// not this process's; they're unrelated to those of SymbolOf and
+// ValueOf. As with those, cached values are never garbage collected.
+type RemoteSymbols struct {
+	ra RemoteAllocator
+
+	mu    sync.RWMutex
+	byStr map[string]Symbol // by underlying string, not Value
+	vals  map[Symbol]*Value
+}
+
+// NewRemoteSymbols returns a RemoteSymbols using ra.
+func NewRemoteSymbols(ra RemoteAllocator) *RemoteSymbols {
+	return &RemoteSymbols{
+		ra:    ra,
+		byStr: map[string]Symbol{},
+		vals:  map[Symbol]*Value{},
+	}
+}
+
+// SymbolOf returns the allocator's Symbol for s.
+func (r *RemoteSymbols) SymbolOf(s string) (Symbol, error) {
+	r.mu.RLock()
+	sym, ok := r.byStr[s]
+	r.mu.RUnlock()
+	if ok {
+		return sym, nil
+	}
+	sym, err := r.ra.Allocate(s)
+	if err != nil {
+		return 0, err
+	}
+	r.add(sym, GetByString(s))
+	return sym, nil
+}
+
+// ValueOf returns the value the allocator assigned sym.
+func (r *RemoteSymbols) ValueOf(sym Symbol) (*Value, error) {
+	r.mu.RLock()
+	v, ok := r.vals[sym]
+	r.mu.RUnlock()
+	if ok {
+		return v, nil
+	}
+	s, err := r.ra.Resolve(sym)
+	if err != nil {
+		return nil, err
+	}
+	v = GetByString(s)
+	r.add(sym, v)
+	return v, nil
+}
+
+func (r *RemoteSymbols) add(sym Symbol, v *Value) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byStr[v.cmpVal.(string)] = sym
+	r.vals[sym] = v
+}
+
+// localAllocator is a RemoteAllocator backed by this
+// process's symbol table.
+type localAllocator struct{}
+
+// LocalAllocator returns a RemoteAllocator that assigns Symbols from
+// this process's symbol table, as used by SymbolOf and ValueOf. It is
+// intended for use by a coordinator serving other processes.
+func LocalAllocator() RemoteAllocator { return localAllocator{} }
+
+func (localAllocator) Allocate(s string) (Symbol, error) {
+	return SymbolOfString(s), nil
+}
+
+func (localAllocator) Resolve(sym Symbol) (string, error) {
+	v := ValueOf(sym)
+	if v == nil {
+		return "", fmt.Errorf("intern: Symbol %d not assigned", sym)
+	}
+	s, ok := v.cmpVal.(string)
+	if !ok {
+		return "", fmt.Errorf("intern: Symbol %d has non-string value of type %T", sym, v.cmpVal)
+	}
+	return s, nil
+}
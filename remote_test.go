@@ -0,0 +1,81 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"fmt"
+	"testing"
+)
+
+// countingAllocator is a RemoteAllocator that counts its calls.
+type countingAllocator struct {
+	calls int
+	syms  map[string]Symbol
+	strs  []string
+}
+
+func (a *countingAllocator) Allocate(s string) (Symbol, error) {
+	a.calls++
+	if sym, ok := a.syms[s]; ok {
+		return sym, nil
+	}
+	a.strs = append(a.strs, s)
+	a.syms[s] = Symbol(len(a.strs)) + 100
+	return a.syms[s], nil
+}
+
+func (a *countingAllocator) Resolve(sym Symbol) (string, error) {
+	a.calls++
+	i := int(sym) - 101
+	if i < 0 || i >= len(a.strs) {
+		return "", fmt.Errorf("unknown Symbol %d", sym)
+	}
+	return a.strs[i], nil
+}
+
+func TestRemoteSymbols(t *testing.T) {
+	ra := &countingAllocator{syms: map[string]Symbol{}}
+	r := NewRemoteSymbols(ra)
+
+	for i := 0; i < 3; i++ {
+		sym, err := r.SymbolOf("remote-a")
+		if err != nil || sym != 101 {
+			t.Fatalf("SymbolOf(remote-a) = %d, %v; want 101, nil", sym, err)
+		}
+	}
+	v, err := r.ValueOf(101)
+	if err != nil || v != GetByString("remote-a") {
+		t.Fatalf("ValueOf(101) = %v, %v; want remote-a", v, err)
+	}
+	if ra.calls != 1 {
+		t.Errorf("allocator called %d times; want 1", ra.calls)
+	}
+
+	// The cache outlives the string's Value.
+	Forget("remote-a")
+	if sym, err := r.SymbolOf("remote-a"); err != nil || sym != 101 {
+		t.Errorf("SymbolOf(remote-a) after Forget = %d, %v; want 101, nil", sym, err)
+	}
+	if ra.calls != 1 {
+		t.Errorf("allocator called %d times after Forget; want 1", ra.calls)
+	}
+	if _, err := r.ValueOf(999); err == nil {
+		t.Error("ValueOf(999) succeeded")
+	}
+}
+
+func TestLocalAllocator(t *testing.T) {
+	ra := LocalAllocator()
+	sym, err := ra.Allocate("local-a")
+	if err != nil || sym != SymbolOfString("local-a") {
+		t.Fatalf("Allocate = %d, %v; want %d", sym, err, SymbolOfString("local-a"))
+	}
+	if s, err := ra.Resolve(sym); err != nil || s != "local-a" {
+		t.Errorf("Resolve = %q, %v; want local-a", s, err)
+	}
+	if _, err := ra.Resolve(SymbolOf(1.5)); err == nil {
+		t.Error("Resolve of non-string Symbol succeeded")
+	}
+}
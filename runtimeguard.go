@@ -0,0 +1,126 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// The default backend converts Values' addresses to uintptrs and back,
+// which is only sound if the garbage collector never moves heap
+// objects. No Go runtime has yet done so, but should one, an Interner
+// acts as its RuntimePolicy says.
+
+// A RuntimePolicy says what an Interner does if the Go runtime may move
+// heap objects, so that its default backend might corrupt memory.
+type RuntimePolicy int
+
+const (
+	// PanicOnUntestedRuntime refuses to run: New panics with
+	// ErrUntestedRuntime. It's the default, as it's better not to
+	// start at all than to corrupt memory.
+	//
+	// So that programs can still choose another policy with
+	// SetRuntimePolicy, the default Interner doesn't panic at init.
+	// Instead, Gets on it panic until another policy is chosen.
+	PanicOnUntestedRuntime RuntimePolicy = iota
+
+	// FallbackToSafe uses the safe-but-leaky backend, as with
+	// GO4_INTERN_SAFE_BUT_LEAKY: no Value is ever collected.
+	FallbackToSafe
+
+	// Proceed uses the default backend regardless.
+	Proceed
+)
+
+func (p RuntimePolicy) String() string {
+	switch p {
+	case PanicOnUntestedRuntime:
+		return "panic"
+	case FallbackToSafe:
+		return "safe"
+	case Proceed:
+		return "proceed"
+	}
+	return fmt.Sprintf("RuntimePolicy(%d)", int(p))
+}
+
+// ErrUntestedRuntime is the value New panics with, and Gets on the
+// default Interner panic with, under PanicOnUntestedRuntime if the Go
+// runtime may move heap objects.
+var ErrUntestedRuntime = errors.New("intern: the Go runtime may move heap objects; see SetRuntimePolicy")
+
+// runtimePolicy is the RuntimePolicy of Interners created without
+// WithRuntimePolicy. It's accessed atomically.
+var runtimePolicy int32
+
+// heapObjectsMayMove reports whether the Go runtime may move heap
+// objects. It's a variable for tests.
+var heapObjectsMayMove = heapObjectsCanMove
+
+// SetRuntimePolicy sets the RuntimePolicy of Interners created later
+// without WithRuntimePolicy, and of the default Interner if Gets on it
+// are panicking under PanicOnUntestedRuntime. It's meant to be called
+// at init, or early in main, to acknowledge the risk in code rather
+// than by setting GODEBUG=internruntime=POLICY (see ParseSettings).
+func SetRuntimePolicy(p RuntimePolicy) {
+	atomic.StoreInt32(&runtimePolicy, int32(p))
+	std.resolveRuntimePolicy(p)
+}
+
+// WithRuntimePolicy returns an Option that sets the Interner's
+// RuntimePolicy, overriding the one set by SetRuntimePolicy.
+func WithRuntimePolicy(p RuntimePolicy) Option {
+	return func(in *Interner) {
+		in.policy = p
+		in.policySet = true
+	}
+}
+
+// deferRuntimeGuard is the Option that makes the default Interner's
+// Gets panic under PanicOnUntestedRuntime, rather than New.
+func deferRuntimeGuard(in *Interner) { in.deferGuard = true }
+
+// guardRuntime applies in's RuntimePolicy, when in is created.
+func (in *Interner) guardRuntime() {
+	if in.valSafe != nil || !heapObjectsMayMove() {
+		return
+	}
+	p := in.policy
+	if !in.policySet {
+		p = RuntimePolicy(atomic.LoadInt32(&runtimePolicy))
+	}
+	switch p {
+	case PanicOnUntestedRuntime:
+		if !in.deferGuard {
+			panic(ErrUntestedRuntime)
+		}
+		safeBackend(in)
+		atomic.StoreUint32(&in.closed, closedUntested)
+	case FallbackToSafe:
+		safeBackend(in)
+	}
+}
+
+// resolveRuntimePolicy applies p to in, if in's Gets are panicking
+// under PanicOnUntestedRuntime. Nothing has been interned, so in's
+// backend can still be changed.
+func (in *Interner) resolveRuntimePolicy(p RuntimePolicy) {
+	if p == PanicOnUntestedRuntime {
+		return
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if atomic.LoadUint32(&in.closed) != closedUntested {
+		return
+	}
+	if p == Proceed {
+		in.valSafe = nil
+		in.leaks = nil
+	}
+	atomic.StoreUint32(&in.closed, 0)
+}
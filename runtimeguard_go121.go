@@ -0,0 +1,16 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.21
+// +build go1.21
+
+package intern
+
+import _ "unsafe" // for go:linkname
+
+// heapObjectsCanMove reports whether the runtime's garbage collector
+// may move heap objects. The runtime provides it for exactly this.
+//
+//go:linkname heapObjectsCanMove runtime.heapObjectsCanMove
+func heapObjectsCanMove() bool
@@ -0,0 +1,12 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.21
+// +build !go1.21
+
+package intern
+
+// heapObjectsCanMove reports whether the runtime's garbage collector
+// may move heap objects. No Go release before 1.21 does.
+func heapObjectsCanMove() bool { return false }
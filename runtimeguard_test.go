@@ -0,0 +1,102 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"errors"
+	"testing"
+)
+
+// pretendMovingRuntime makes the runtime appear to move heap objects,
+// and returns a func that restores the truth and the default policy.
+func pretendMovingRuntime() (restore func()) {
+	old := heapObjectsMayMove
+	heapObjectsMayMove = func() bool { return true }
+	return func() {
+		heapObjectsMayMove = old
+		runtimePolicy = int32(PanicOnUntestedRuntime)
+	}
+}
+
+func TestRuntimeGuard(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("guard not needed in safe mode")
+	}
+	defer pretendMovingRuntime()()
+
+	func() {
+		defer func() {
+			if r := recover(); r != ErrUntestedRuntime {
+				t.Errorf("New panicked with %v; want ErrUntestedRuntime", r)
+			}
+		}()
+		New()
+	}()
+
+	if in := New(WithRuntimePolicy(FallbackToSafe)); in.valSafe == nil {
+		t.Error("FallbackToSafe didn't use the safe backend")
+	}
+	if in := New(WithRuntimePolicy(Proceed)); in.valSafe != nil {
+		t.Error("Proceed used the safe backend")
+	}
+	if in := New(ManualCollect()); in.valSafe == nil {
+		t.Error("ManualCollect lost its map")
+	}
+	SetRuntimePolicy(FallbackToSafe)
+	if in := New(); in.valSafe == nil {
+		t.Error("SetRuntimePolicy(FallbackToSafe) didn't apply to New")
+	}
+	if in := New(WithRuntimePolicy(Proceed)); in.valSafe != nil {
+		t.Error("WithRuntimePolicy didn't override SetRuntimePolicy")
+	}
+}
+
+func TestRuntimeGuardDeferred(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("guard not needed in safe mode")
+	}
+	defer pretendMovingRuntime()()
+
+	for _, p := range []RuntimePolicy{FallbackToSafe, Proceed} {
+		in := New(deferRuntimeGuard)
+		if _, err := in.TryGet("x"); !errors.Is(err, ErrUntestedRuntime) {
+			t.Errorf("TryGet error = %v; want ErrUntestedRuntime", err)
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != ErrUntestedRuntime {
+					t.Errorf("Get panicked with %v; want ErrUntestedRuntime", r)
+				}
+			}()
+			in.GetByString("x")
+		}()
+		in.resolveRuntimePolicy(p)
+		if v := in.GetByString("x"); in.GetByString("x") != v {
+			t.Errorf("%v: Values differ", p)
+		}
+		if safe := in.valSafe != nil; safe != (p == FallbackToSafe) {
+			t.Errorf("%v: safe backend = %v", p, safe)
+		}
+	}
+}
+
+func TestRuntimePolicySetting(t *testing.T) {
+	for _, p := range []RuntimePolicy{PanicOnUntestedRuntime, FallbackToSafe, Proceed} {
+		opts, err := ParseSettings("internruntime=" + p.String())
+		if err != nil || len(opts) != 1 {
+			t.Fatalf("ParseSettings(%v) = %d options, %v", p, len(opts), err)
+		}
+		in := &Interner{}
+		opts[0](in)
+		if in.policy != p || !in.policySet {
+			t.Errorf("setting %v selected policy %v", p, in.policy)
+		}
+	}
+	if _, err := ParseSettings("internruntime=maybe"); err == nil {
+		t.Error("invalid policy accepted")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "sync/atomic"
+
+// seqlockRetries is the number of times a Get reads the table without
+// the lock, each time finding it changed, before taking the lock.
+const seqlockRetries = 4
+
+// Seqlock returns an Option that lets Gets of values already interned
+// read the table without taking the Interner's lock, retrying if it
+// changes while they read rather than waiting for writers. Inserts and
+// removals still take the lock, and so are serialized, but no longer
+// block hits, so bursts of inserts don't convoy the Gets around them
+// behind the lock.
+//
+// Unlike Snapshots, Seqlock needs no janitor, and hits are answered
+// lock-free as soon as a value is inserted. In exchange, every write
+// to the table does some extra atomic operations. Run
+// BenchmarkBackends, which compares "finalizer" to
+// "finalizer-seqlock", with the -cpu flag to see whether it helps a
+// workload.
+//
+// Seqlock has no effect on Interners that must update per-Value or
+// windowed state on each hit, as with CountHits, ManualCollect,
+// WindowedStats, or Shadow, or that don't use the default index, as
+// with HashKeys, WeakPointers, or safe-but-leaky mode.
+func Seqlock() Option {
+	return func(in *Interner) { in.seqlock = true }
+}
+
+// seqlockUsable reports whether in's options let it use Seqlock.
+func (in *Interner) seqlockUsable() bool {
+	return in.valSafe == nil && in.hashMap == nil && in.wk == nil &&
+		!in.countHits && !in.manual && in.window == nil && in.shadow == nil
+}
+
+// seqlockGet returns the Value for k, whose hash is kh, if it can be
+// found without the lock, or nil. It counts a hit unless ctx is a
+// composite's part.
+func (in *Interner) seqlockGet(k key, kh keyHash, ctx getCtx) *Value {
+	for i := 0; i < seqlockRetries; i++ {
+		v, ok := in.tab.findShared(k, kh.tab)
+		if !ok {
+			continue
+		}
+		if v == nil || !v.resurrect() {
+			// Not interned, or being removed by its
+			// finalizer: take the lock to insert it.
+			return nil
+		}
+		if !ctx.part {
+			atomic.AddUint64(&in.seqlockHits, 1)
+		}
+		return v
+	}
+	return nil
+}
+
+// findShared is find for a shared table, without the lock. It reports
+// whether its result is valid: false if the table was written
+// meanwhile, in which case it should be retried.
+//
+// Each Value found is converted to a pointer, keeping it alive, and
+// then the table is checked to be unchanged before the Value is read.
+// If so, the Value was in the table, so hadn't been removed by its
+// finalizer, and can't have been freed: the garbage collector only
+// frees a Value after its finalizer has removed it, changing the
+// table. The caller must still resurrect the Value returned.
+//
+//go:nocheckptr
+func (t *table) findShared(k key, h uint64) (v *Value, ok bool) {
+	seq := atomic.LoadUint32(&t.seq)
+	if seq&1 != 0 {
+		return nil, false // being written
+	}
+	if p := (*[]slot)(atomic.LoadPointer(&t.pub)); p != nil && len(*p) > 0 {
+		slots := *p
+		mask := uint64(len(slots) - 1)
+		i := h & mask
+		for n := 0; n < len(slots); n, i = n+1, (i+1)&mask {
+			s := &slots[i]
+			addr := atomic.LoadUintptr(&s.addr)
+			if addr == empty {
+				break
+			}
+			if addr == tombstone || atomic.LoadUint64(&s.hash) != h {
+				continue
+			}
+			w := valueAt(addr)
+			if atomic.LoadUint32(&t.seq) != seq {
+				// w may have been removed, and freed, before
+				// it was converted: don't read it.
+				return nil, false
+			}
+			if keyFor(w.cmpVal) == k {
+				v = w
+				break
+			}
+		}
+	}
+	if atomic.LoadUint32(&t.seq) != seq {
+		return nil, false
+	}
+	return v, true
+}
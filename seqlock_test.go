@@ -0,0 +1,79 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSeqlock(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(Seqlock())
+	if !in.seqlock && safeMap() == nil {
+		t.Fatal("Seqlock not in effect")
+	}
+	v := in.GetByString("a")
+	if in.GetByString("a") != v || in.Get(1) != in.Get(1) {
+		t.Error("Gets with Seqlock aren't canonical")
+	}
+	if st := in.Stats(); st.Hits != 2 || st.Misses != 2 {
+		t.Errorf("Hits, Misses = %d, %d; want 2, 2", st.Hits, st.Misses)
+	}
+	if in.seqlock && in.seqlockHits != 2 {
+		t.Errorf("seqlockHits = %d; want 2", in.seqlockHits)
+	}
+	in.Forget("a")
+	if in.GetByString("a") == v {
+		t.Error("Get after Forget returned the forgotten Value")
+	}
+	runtime.KeepAlive(v)
+
+	for _, opt := range []Option{CountHits(), ManualCollect(), HashKeys(), WindowedStats()} {
+		if New(Seqlock(), opt).seqlock {
+			t.Errorf("Seqlock in effect with an incompatible option")
+		}
+	}
+}
+
+func TestSeqlockConcurrent(t *testing.T) {
+	in := New(Seqlock())
+	const n = 1000
+	keep := make([]*Value, n)
+	for i := range keep {
+		keep[i] = in.GetByString("k" + strconv.Itoa(i))
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for round := 0; round < 10; round++ {
+				for i, want := range keep {
+					if got := in.GetByString("k" + strconv.Itoa(i)); got != want {
+						t.Errorf("Get of k%d = %p; want %p", i, got, want)
+						return
+					}
+				}
+			}
+		}()
+		go func(g int) {
+			defer wg.Done()
+			// Inserts that grow the table, and removals.
+			for i := 0; i < n; i++ {
+				s := "g" + strconv.Itoa(g) + "-" + strconv.Itoa(i)
+				in.GetByString(s)
+				if i%3 == 0 {
+					in.Forget(s)
+				}
+			}
+			runtime.GC()
+		}(g)
+	}
+	wg.Wait()
+	runtime.KeepAlive(keep)
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The default Interner is configured at init by the settings in the
+// GODEBUG environment variable whose names begin with "intern", as
+// parsed by ParseSettings. For example,
+//
+//	GODEBUG=intern=off,internjanitor=10m
+//
+// starts it disabled, with a janitor. The Go runtime ignores settings
+// it doesn't know, so they can share GODEBUG with the runtime's own.
+// Invalid settings are ignored.
+var stdSettings = godebugSettings(os.Getenv("GODEBUG"))
+
+// Settings returns the settings that configured the default Interner
+// at init, in GODEBUG's comma-separated name=value form.
+func Settings() string { return stdSettings }
+
+// stdOptions returns the Options for the default Interner.
+func stdOptions() []Option {
+	opts, _ := ParseSettings(stdSettings)
+	return append(opts, deferRuntimeGuard)
+}
+
+// godebugSettings returns the settings in godebug whose names
+// begin with "intern".
+func godebugSettings(godebug string) string {
+	var ours []string
+	for _, kv := range strings.Split(godebug, ",") {
+		if strings.HasPrefix(strings.TrimSpace(kv), "intern") {
+			ours = append(ours, strings.TrimSpace(kv))
+		}
+	}
+	return strings.Join(ours, ",")
+}
+
+// ParseSettings parses s, a comma-separated list of name=value
+// settings as in GODEBUG, and returns the Options they select.
+// The settings are:
+//
+//	intern=off           Disabled
+//	internhashkeys=1     HashKeys
+//	interncounthits=1    CountHits
+//	internprofile=1      Profile
+//	internshadow=1       Shadow
+//	internjanitor=DUR    WithJanitor(DUR), for a time.Duration DUR
+//	internsites=N        SampleCallSites(N), for an integer N
+//	internruntime=P      WithRuntimePolicy(P), for P panic, safe, or proceed
+//
+// Boolean settings accept the values of strconv.ParseBool, and
+// intern also accepts "on" and "off". Settings whose names don't
+// begin with "intern" are ignored.
+//
+// ParseSettings returns the Options for all valid settings, and an
+// error describing the first invalid or unknown one, if any.
+func ParseSettings(s string) ([]Option, error) {
+	var opts []Option
+	var firstErr error
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if !strings.HasPrefix(kv, "intern") {
+			continue
+		}
+		opt, err := parseSetting(kv)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if opt != nil {
+			opts = append(opts, opt)
+		}
+	}
+	return opts, firstErr
+}
+
+// parseSetting returns the Option for a single name=value setting,
+// or nil if the setting selects the default.
+func parseSetting(kv string) (Option, error) {
+	eq := strings.IndexByte(kv, '=')
+	if eq < 0 {
+		return nil, fmt.Errorf("intern: setting %q has no value", kv)
+	}
+	name, val := kv[:eq], kv[eq+1:]
+	if name == "internjanitor" {
+		d, err := time.ParseDuration(val)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("intern: invalid %s duration %q", name, val)
+		}
+		return WithJanitor(d), nil
+	}
+	if name == "internsites" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("intern: invalid %s rate %q", name, val)
+		}
+		return SampleCallSites(n), nil
+	}
+	if name == "internruntime" {
+		for p := PanicOnUntestedRuntime; p <= Proceed; p++ {
+			if val == p.String() {
+				return WithRuntimePolicy(p), nil
+			}
+		}
+		return nil, fmt.Errorf("intern: invalid %s policy %q", name, val)
+	}
+
+	var opt Option
+	switch name {
+	case "intern":
+		switch val {
+		case "on":
+			return nil, nil
+		case "off":
+			return Disabled(), nil
+		}
+		if on, err := strconv.ParseBool(val); err == nil {
+			if on {
+				return nil, nil
+			}
+			return Disabled(), nil
+		}
+		return nil, fmt.Errorf("intern: invalid %s value %q", name, val)
+	case "internhashkeys":
+		opt = HashKeys()
+	case "interncounthits":
+		opt = CountHits()
+	case "internprofile":
+		opt = Profile()
+	case "internshadow":
+		opt = Shadow()
+	default:
+		return nil, fmt.Errorf("intern: unknown setting %q", name)
+	}
+	on, err := strconv.ParseBool(val)
+	if err != nil {
+		return nil, fmt.Errorf("intern: invalid %s value %q", name, val)
+	}
+	if !on {
+		return nil, nil
+	}
+	return opt, nil
+}
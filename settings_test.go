@@ -0,0 +1,57 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGodebugSettings(t *testing.T) {
+	got := godebugSettings("gctrace=1, intern=off,madvdontneed=1,internjanitor=1m")
+	if want := "intern=off,internjanitor=1m"; got != want {
+		t.Errorf("godebugSettings = %q; want %q", got, want)
+	}
+}
+
+func TestParseSettings(t *testing.T) {
+	tests := []struct {
+		s       string
+		nopts   int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"gctrace=1", 0, false},
+		{"intern=on", 0, false},
+		{"intern=off", 1, false},
+		{"intern=0", 1, false},
+		{"internhashkeys=1,interncounthits=true,internshadow=0", 2, false},
+		{"internprofile=1,internjanitor=10m", 2, false},
+		{"internjanitor=soon", 0, true},
+		{"internsites=100", 1, false},
+		{"internsites=0", 0, true},
+		{"internshards=64,internhashkeys=1", 1, true},
+		{"intern=maybe", 0, true},
+		{"internhashkeys", 0, true},
+	}
+	for _, tt := range tests {
+		opts, err := ParseSettings(tt.s)
+		if len(opts) != tt.nopts || (err != nil) != tt.wantErr {
+			t.Errorf("ParseSettings(%q) = %d options, %v; want %d options, error %v", tt.s, len(opts), err, tt.nopts, tt.wantErr)
+		}
+	}
+}
+
+func TestParseSettingsApply(t *testing.T) {
+	opts, err := ParseSettings("intern=off,internhashkeys=1,internjanitor=1h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := New(opts...)
+	defer in.Close()
+	if !in.isDisabled() || in.hashMap == nil || in.janitor == nil || in.janitor.interval != time.Hour {
+		t.Errorf("settings not applied: disabled=%v hashMap=%v janitor=%+v", in.isDisabled(), in.hashMap != nil, in.janitor)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// WithMinSize returns an Option that doesn't intern strings shorter
+// than n bytes: Get returns a new Value for each, which isn't
+// canonical, as with WithMaxSize. Interning a string such as "a" or
+// "0" costs more in the index than it saves, since its Value is larger
+// than the string.
+//
+// With TinyStrings, strings short enough for its table are returned
+// from there instead.
+func WithMinSize(n int) Option {
+	return func(in *Interner) { in.minSize = n }
+}
+
+// TinyStrings returns an Option that returns the Values of strings of
+// up to two bytes from a small static table, rather than interning
+// them in the Interner's index.
+//
+// Like Get(nil), Values from the table are canonical but are shared by
+// every Interner using the option, and are never collected, forgotten,
+// or counted in Stats.
+func TinyStrings() Option {
+	return func(in *Interner) { in.tiny = true }
+}
+
+// maxTiny is the length of the longest string in the tiny table.
+const maxTiny = 2
+
+var (
+	tinyEmpty = &Value{cmpVal: ""}
+
+	// tiny1 holds the Values of one-byte strings, and tiny2 those
+	// of two-byte strings, by first byte. Each *[256]Value in tiny2
+	// is made on first use and is accessed atomically.
+	tiny1 [256]Value
+	tiny2 [256]unsafe.Pointer
+)
+
+func init() {
+	for i := range tiny1 {
+		tiny1[i].cmpVal = string([]byte{byte(i)})
+	}
+}
+
+// isShort reports whether k is a string too short to intern.
+func (in *Interner) isShort(k key) bool {
+	return k.isString && len(k.s) < in.minSize
+}
+
+// shortValue returns the Value for the short string s, not interned.
+func (in *Interner) shortValue(s string) *Value {
+	if in.tiny && len(s) <= maxTiny {
+		return tinyValue(s)
+	}
+	return &Value{cmpVal: s, in: in}
+}
+
+// tinyValue returns the Value from the tiny table for s, which must be
+// at most maxTiny bytes long.
+func tinyValue(s string) *Value {
+	switch len(s) {
+	case 0:
+		return tinyEmpty
+	case 1:
+		return &tiny1[s[0]]
+	}
+	p := &tiny2[s[0]]
+	row := (*[256]Value)(atomic.LoadPointer(p))
+	if row == nil {
+		row = new([256]Value)
+		for i := range row {
+			row[i].cmpVal = string([]byte{s[0], byte(i)})
+		}
+		if !atomic.CompareAndSwapPointer(p, nil, unsafe.Pointer(row)) {
+			row = (*[256]Value)(atomic.LoadPointer(p))
+		}
+	}
+	return &row[s[1]]
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestWithMinSize(t *testing.T) {
+	in := New(WithMinSize(4))
+	a, b := in.GetByString("abc"), in.GetByString("abc")
+	if a == b {
+		t.Error("short strings interned")
+	}
+	if a.Get() != "abc" {
+		t.Errorf("Get = %v; want abc", a.Get())
+	}
+	if in.GetByString("abcd") != in.GetByString("abcd") {
+		t.Error("long strings not interned")
+	}
+	if _, ok := in.Lookup("abc"); ok {
+		t.Error("Lookup found short string")
+	}
+	if in.Get(1) != in.Get(1) {
+		t.Error("non-strings not interned")
+	}
+	if got := in.Stats().Entries; got != 2 {
+		t.Errorf("Entries = %d; want 2", got)
+	}
+}
+
+func TestTinyStrings(t *testing.T) {
+	in := New(TinyStrings())
+	in2 := New(TinyStrings(), WithMinSize(1))
+	for _, s := range []string{"", "a", "\xff", "ab", "\x00\xff"} {
+		v := in.GetByString(s)
+		if v.Get() != s {
+			t.Errorf("Get = %q; want %q", v.Get(), s)
+		}
+		if in.GetByString(s) != v || in.Get(s) != v {
+			t.Errorf("%q: Values differ", s)
+		}
+		if in2.GetByString(s) != v {
+			t.Errorf("%q: Values differ between Interners", s)
+		}
+		if got, ok := in.Lookup(s); got != v || !ok {
+			t.Errorf("%q: Lookup = %p, %v; want %p, true", s, got, ok, v)
+		}
+		if got, ok := v.Weak().Strong(); got != v || !ok {
+			t.Errorf("%q: Strong = %p, %v; want %p, true", s, got, ok, v)
+		}
+	}
+	if got := in.Stats().Entries; got != 0 {
+		t.Errorf("Entries = %d; want 0", got)
+	}
+	v := in.GetByString("abc")
+	if in.GetByString("abc") != v {
+		t.Error("longer strings not interned")
+	}
+	runtime.KeepAlive(v)
+
+	if n := testing.AllocsPerRun(100, func() { in.GetByString("xy") }); n != 0 {
+		t.Errorf("tiny Get allocated %v objects; want 0", n)
+	}
+}
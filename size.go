@@ -0,0 +1,41 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+const (
+	// sizeMin is the bound of a size histogram's first bucket, and
+	// sizeBuckets the number of buckets, doubling each time, up to
+	// 64 MiB.
+	sizeMin     = 8
+	sizeBuckets = 24
+)
+
+// SizeStats returns an Option that keeps the distribution of the sizes
+// of the values in the Interner, for reporting by Sizes, so that values
+// unexpectedly large to be interning, such as whole request bodies,
+// can be spotted.
+//
+// A string's size is its length; that of another value is the size
+// of its type, not counting memory it points to. Sizes aren't kept
+// for Interners using WeakPointers.
+func SizeStats() Option {
+	return func(in *Interner) { in.sizes = newSizeHistogram() }
+}
+
+func newSizeHistogram() *histogram {
+	return newHistogram(sizeMin, sizeBuckets)
+}
+
+// Sizes returns the distribution, in bytes, of the sizes of the
+// values currently in in. Its Sum is their total size. Sizes returns
+// a zero Histogram if in wasn't created with SizeStats.
+func (in *Interner) Sizes() Histogram {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.sizes == nil {
+		return Histogram{}
+	}
+	return in.sizes.snapshot()
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"math"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSizes(t *testing.T) {
+	in := New(SizeStats())
+	vs := []*Value{
+		in.GetByString("abc"),
+		in.GetByString("abcdefghij"),
+		in.Get(int64(1)),
+		in.Get([2]int64{}),
+		in.GetByString(strings.Repeat("x", 100<<20)),
+	}
+	in.GetByString("abc") // a hit doesn't count again
+
+	h := in.Sizes()
+	if h.Count != 5 || h.Sum != 3+10+8+16+100<<20 {
+		t.Fatalf("Count, Sum = %v, %v", h.Count, h.Sum)
+	}
+	want := map[int]uint64{0: 2, 1: 2, sizeBuckets: 1} // ≤8, ≤16, huge
+	for i, c := range h.Counts {
+		if c != want[i] {
+			t.Errorf("Counts[%d] = %d; want %d", i, c, want[i])
+		}
+	}
+	if q := h.Quantile(1); !math.IsInf(q, 1) {
+		t.Errorf("max bound = %v; want +Inf for the huge string", q)
+	}
+
+	in.Forget(int64(1))
+	if h := in.Sizes(); h.Count != 4 || h.Counts[0] != 1 {
+		t.Errorf("after Forget, Count = %d, Counts[0] = %d; want 4, 1", h.Count, h.Counts[0])
+	}
+	runtime.KeepAlive(vs)
+
+	if h := New().Sizes(); h.Count != 0 || h.Bounds != nil {
+		t.Error("Sizes without SizeStats isn't zero")
+	}
+}
+
+func TestSizesRemovedTwice(t *testing.T) {
+	in := New(SizeStats())
+	v := in.GetByString("abc")
+	in.GetByString("def")
+	in.Forget("abc")
+	finalize(v) // as when v is collected after Forget
+	if h := in.Sizes(); h.Count != 1 || h.Sum != 3 {
+		t.Errorf("Count, Sum = %d, %v; want 1, 3", h.Count, h.Sum)
+	}
+	if st := in.Stats(); st.Finalized != 0 {
+		t.Errorf("Finalized = %d; want 0", st.Finalized)
+	}
+
+	in = New(WeakPointers(), SizeStats())
+	in.GetByString("abc")
+	in.Forget("abc")
+	if h := in.Sizes(); h.Count != 0 || h.Sum != 0 {
+		t.Errorf("WeakPointers: Count, Sum = %d, %v; want 0, 0", h.Count, h.Sum)
+	}
+}
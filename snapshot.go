@@ -0,0 +1,72 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "sync/atomic"
+
+// Snapshots returns an Option that lets Gets of values already
+// interned be answered without taking the Interner's lock, for
+// read-heavy workloads.
+//
+// A snapshot of the table is published by PublishSnapshot, which the
+// janitor calls on each tick if the Interner has one (see WithJanitor).
+// Gets consult the latest snapshot first, without locking, and only
+// take the lock to look in the table, and insert if needed, for values
+// interned since.
+//
+// A snapshot holds its Values, so they remain interned at least until
+// the next snapshot is published. Removing values explicitly, as with
+// Forget, discards the snapshot until the next is published.
+//
+// Like Seqlock, Snapshots has no effect on Interners that must update
+// per-Value or windowed state on each hit, as with CountHits,
+// ManualCollect, Refcounted, WindowedStats, or Shadow.
+func Snapshots() Option {
+	return func(in *Interner) { in.snapshots = true }
+}
+
+// snapshotsUsable reports whether in's options let it use Snapshots.
+func (in *Interner) snapshotsUsable() bool {
+	return !in.countHits && !in.manual && in.window == nil && in.shadow == nil
+}
+
+// PublishSnapshot publishes a snapshot of the values in in, for Gets
+// to consult without locking. It does nothing unless in was created
+// with the Snapshots option.
+//
+// PublishSnapshot takes time proportional to the size of in.
+func (in *Interner) PublishSnapshot() {
+	if !in.snapshots {
+		return
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	m := make(map[key]*Value, in.tab.count)
+	in.forEachLocked(func(v *Value) {
+		// We're retaining pointers made from uintptrs.
+		v.resurrect()
+		m[keyFor(v.cmpVal)] = v
+	})
+	in.snap.Store(m)
+}
+
+// snapshotGet returns the Value for k in the published snapshot,
+// or nil. It counts a hit unless ctx is a composite's part.
+func (in *Interner) snapshotGet(k key, ctx getCtx) *Value {
+	m, _ := in.snap.Load().(map[key]*Value)
+	v := m[k]
+	if v != nil && !ctx.part {
+		atomic.AddUint64(&in.snapHits, 1)
+	}
+	return v
+}
+
+// dropSnapshot discards the published snapshot, after Values have been
+// removed from in that it may hold.
+func (in *Interner) dropSnapshot() {
+	if in.snapshots {
+		in.snap.Store(map[key]*Value(nil))
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSnapshots(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(Snapshots())
+	foo := in.GetByString("foo")
+	if v := in.snapshotGet(keyFor("foo"), getCtx{}); v != nil {
+		t.Fatal("snapshot published before PublishSnapshot")
+	}
+	in.PublishSnapshot()
+	if v := in.snapshotGet(keyFor("foo"), getCtx{}); v != foo {
+		t.Fatalf("snapshot has %p; want %p", v, foo)
+	}
+	before := in.Stats()
+	if in.GetByString("foo") != foo || in.Get(1) == nil {
+		t.Error("Get after publishing returned the wrong Value")
+	}
+	after := in.Stats()
+	if after.Hits != before.Hits+1 || after.Misses != before.Misses+1 {
+		t.Errorf("stats went from %+v to %+v", before, after)
+	}
+
+	in.Forget("foo")
+	if in.GetByString("foo") == foo {
+		t.Error("Get returned a forgotten Value from the snapshot")
+	}
+	runtime.KeepAlive(foo)
+}
+
+func TestSnapshotAllocs(t *testing.T) {
+	in := New(Snapshots())
+	v := in.GetByString(globalString)
+	in.PublishSnapshot()
+	if n := testing.AllocsPerRun(100, func() { in.GetByString(globalString) }); n != 0 {
+		t.Errorf("GetByString allocated %v objects on a snapshot hit; want 0", n)
+	}
+	runtime.KeepAlive(v)
+}
+
+func TestSnapshotJanitor(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(Snapshots(), WithJanitor(time.Millisecond))
+	defer in.Close()
+	v := in.GetByString("x")
+	deadline := time.Now().Add(10 * time.Second)
+	for in.snapshotGet(keyFor("x"), getCtx{}) == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("janitor didn't publish a snapshot")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	runtime.KeepAlive(v)
+}
+
+func BenchmarkSnapshotGetByString(b *testing.B) {
+	in := New(Snapshots())
+	v := in.GetByString(globalString)
+	in.PublishSnapshot()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			in.GetByString(globalString)
+		}
+	})
+	runtime.KeepAlive(v)
+}
+
+func TestSnapshotsManualCollect(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(Snapshots(), ManualCollect())
+	v := in.GetByString("x")
+	in.PublishSnapshot()
+	if in.GetByString("x") != v {
+		t.Fatal("second Get returned a different Value")
+	}
+	v.Release()
+	v.Release() // mustn't panic: each Get took a reference
+	if n := in.Collect(); n != 1 {
+		t.Errorf("Collect removed %d Values; want 1", n)
+	}
+
+	in = New(Snapshots(), Refcounted())
+	v = in.GetByString("y")
+	in.PublishSnapshot()
+	in.GetByString("y")
+	v.Release()
+	if in.GetByString("y") != v {
+		t.Error("with Refcounted, a Value still referenced was removed")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// Stats are statistics about an Interner's use.
+type Stats struct {
+	// Entries is the number of values currently in the table.
+	Entries int
+
+	// Hits is the number of Gets that found an existing Value.
+	Hits uint64
+	// Misses is the number of Gets that didn't.
+	Misses uint64
+	// BytesSaved estimates the memory deduplicated by interning:
+	// for each hit, the size of the value that the caller would
+	// otherwise have retained its own copy of. For strings, that's
+	// the length of the string; for other values, the size of their
+	// type. Like Hits, it counts over the Interner's lifetime, so it
+	// is an upper bound on the memory saved at any one time.
+	BytesSaved uint64
+
+	// Finalized is the number of Values removed from the table by
+	// their finalizer, after becoming unreachable.
+	Finalized uint64
+	// FinalizerRearms is the number of times a Value's finalizer ran
+	// but had to be re-armed because the Value was returned by a Get
+	// since the previous run. Each re-arm delays the Value's removal
+	// by at least one more GC cycle.
+	FinalizerRearms uint64
+	// FinalizedRearms is the total number of re-arms of the Values
+	// counted in Finalized. FinalizedRearms/Finalized is the average
+	// number of extra GC cycles a Value lingered after its finalizer
+	// first ran.
+	FinalizedRearms uint64
+	// Zombies is the number of Values whose finalizer has been
+	// re-armed and that haven't yet been finalized: Values that
+	// were unreachable at some GC cycle and may be again.
+	Zombies int
+
+	// Evicted is the number of Values removed under memory
+	// pressure. See WithMemoryPressure.
+	Evicted uint64
+
+	// ShadowGets is the number of Gets in shadow mode. See Shadow.
+	ShadowGets uint64
+	// ShadowDuplicates is the number of Gets in shadow mode for
+	// a value that had been seen before: the number of Values
+	// interning would have saved allocating.
+	ShadowDuplicates uint64
+	// ShadowBytesSaved estimates the bytes interning would have
+	// deduplicated: the sum of the sizes of the duplicate values.
+	ShadowBytesSaved uint64
+}
+
+// Stats returns the current statistics for in.
+func (in *Interner) Stats() Stats {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.drainFinalizedLocked()
+	st := Stats{
+		Entries: in.entriesLocked(),
+		Hits:    in.hits + atomic.LoadUint64(&in.snapHits) + atomic.LoadUint64(&in.seqlockHits) + in.rcuHits.load() + in.constants().hitCount(),
+		Misses:  in.misses,
+
+		BytesSaved: in.bytesSaved,
+
+		Finalized:       in.finalized,
+		FinalizerRearms: in.rearms,
+		FinalizedRearms: in.finalizedRearms,
+		Zombies:         in.zombies,
+		Evicted:         in.pressureEvictions,
+	}
+	if sh := in.shadow; sh != nil {
+		st.ShadowGets = sh.gets
+		st.ShadowDuplicates = sh.dups
+		st.ShadowBytesSaved = sh.bytesSaved
+	}
+	return st
+}
+
+// entriesLocked returns the number of values in in's table.
+// in.mu must be held.
+func (in *Interner) entriesLocked() int {
+	switch {
+	case in.valSafe != nil:
+		return len(in.valSafe)
+	case in.hashMap != nil:
+		return len(in.hashMap)
+	case in.wk != nil:
+		return in.wk.len()
+	}
+	return in.tab.count + len(in.flooded)
+}
+
+// Shadow returns an Option that puts an Interner in shadow mode, to
+// estimate what interning would save before adopting it.
+//
+// In shadow mode, nothing is interned: every Get returns a fresh
+// Value, as if the caller had allocated its own. Instead, Gets are
+// recorded and reported in the Shadow fields of Stats.
+//
+// Seen values are tracked by a 64-bit hash, which neither retains
+// the values nor costs as much as interning them, but does grow with
+// the number of distinct values seen.
+func Shadow() Option {
+	return func(in *Interner) {
+		in.shadow = &shadowStats{seen: map[uint64]struct{}{}}
+	}
+}
+
+// shadowStats are the statistics of an Interner in shadow mode.
+type shadowStats struct {
+	seen       map[uint64]struct{} // by hashKey
+	gets       uint64
+	dups       uint64
+	bytesSaved uint64
+}
+
+func (sh *shadowStats) observe(k key) {
+	sh.gets++
+	h := hashKey(k)
+	if _, ok := sh.seen[h]; !ok {
+		sh.seen[h] = struct{}{}
+		return
+	}
+	sh.dups++
+	sh.bytesSaved += uint64(keySize(k))
+}
+
+// keySize estimates the memory, in bytes, used by k's value:
+// the bytes of a string, or the size of another value's type.
+// It doesn't count memory referred to by pointers within the value.
+func keySize(k key) uintptr {
+	if k.isString {
+		return uintptr(len(k.s))
+	}
+	if k.cmpVal == nil {
+		return 0
+	}
+	return reflect.TypeOf(k.cmpVal).Size()
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestStats(t *testing.T) {
+	in := New()
+	a := in.GetByString("a")
+	in.GetByString("a")
+	b := in.Get(1)
+	in.Get(1)
+	st := in.Stats()
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+	if st.Entries != 2 || st.Hits != 2 || st.Misses != 2 {
+		t.Errorf("Stats = %+v; want 2 entries, 2 hits, 2 misses", st)
+	}
+	if want := uint64(len("a")) + uint64(unsafe.Sizeof(0)); st.BytesSaved != want {
+		t.Errorf("BytesSaved = %d; want %d", st.BytesSaved, want)
+	}
+}
+
+func TestShadow(t *testing.T) {
+	in := New(Shadow())
+	a, b := in.GetByString("shadow"), in.Get("shadow")
+	if a == b {
+		t.Error("shadow mode interned a value")
+	}
+	if a.Get() != "shadow" || b.Get() != "shadow" {
+		t.Errorf("values = %v, %v; want shadow", a.Get(), b.Get())
+	}
+	in.Get(int64(1))
+	in.Get(int64(1))
+	in.Get(int64(2))
+
+	st := in.Stats()
+	want := Stats{
+		ShadowGets:       5,
+		ShadowDuplicates: 2,
+		ShadowBytesSaved: uint64(len("shadow")) + 8,
+	}
+	if st != want {
+		t.Errorf("Stats = %+v; want %+v", st, want)
+	}
+}
+
+func TestFinalizerStats(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("Values aren't finalized in safe-but-leaky mode")
+	}
+	in := New()
+	// Get the value twice, resurrecting it, so that its
+	// finalizer must be re-armed once.
+	in.GetByString("zombie")
+	in.GetByString("zombie")
+	for try := 0; try < 1000 && in.Stats().Entries > 0; try++ {
+		runtime.GC()
+	}
+	st := in.Stats()
+	if st.Entries != 0 {
+		t.Fatalf("Entries = %d after GC; want 0", st.Entries)
+	}
+	if st.Finalized != 1 || st.FinalizerRearms != 1 || st.FinalizedRearms != 1 || st.Zombies != 0 {
+		t.Errorf("Stats = %+v; want 1 finalized, 1 rearm, 0 zombies", st)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "math"
+
+const (
+	// strChunkSize is the size of each of a stringArena's chunks.
+	strChunkSize = 64 << 10
+
+	// strArenaMax is the length above which strings are allocated
+	// individually rather than in a chunk, so that a chunk isn't
+	// left mostly unused.
+	strArenaMax = strChunkSize / 16
+)
+
+// A stringArena stores strings packed into large chunks of memory,
+// rather than in an allocation each. That avoids the allocator's
+// per-object overhead and the fragmentation of many small objects.
+// A string is located by a strRef, which holds no pointers.
+//
+// Memory in a chunk is only freed when the arena is unreachable, so a
+// stringArena suits strings that live as long as each other, such as
+// those of a HandleTable, which are never removed.
+//
+// A stringArena is not safe for concurrent use.
+type stringArena struct {
+	chunks [][]byte // len of each is the space used
+	cur    int      // index in chunks of the chunk being filled
+}
+
+// A strRef locates a string in a stringArena: it's the n bytes at
+// offset off in chunk.
+type strRef struct {
+	chunk, off, n uint32
+}
+
+// add stores a copy of s in the arena, returning its location.
+func (a *stringArena) add(s string) strRef {
+	if len(s) == 0 {
+		return strRef{}
+	}
+	if uint64(len(s)) > math.MaxUint32 {
+		panic("intern: string too long for a stringArena")
+	}
+	if len(s) > strArenaMax {
+		a.chunks = append(a.chunks, []byte(s))
+		return strRef{chunk: uint32(len(a.chunks) - 1), n: uint32(len(s))}
+	}
+	if len(a.chunks) == 0 || cap(a.chunks[a.cur])-len(a.chunks[a.cur]) < len(s) {
+		a.chunks = append(a.chunks, make([]byte, 0, strChunkSize))
+		a.cur = len(a.chunks) - 1
+	}
+	c := a.chunks[a.cur]
+	r := strRef{chunk: uint32(a.cur), off: uint32(len(c)), n: uint32(len(s))}
+	a.chunks[a.cur] = append(c, s...)
+	return r
+}
+
+// string returns the string at r, which must have been returned by
+// a.add.
+func (a *stringArena) string(r strRef) string {
+	if r.n == 0 {
+		return ""
+	}
+	return bytesToString(a.chunks[r.chunk][r.off : r.off+r.n : r.off+r.n])
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStringArena(t *testing.T) {
+	var a stringArena
+	var got, want []string
+	for i := 0; i < 20000; i++ {
+		s := "string-" + strconv.Itoa(i)
+		want = append(want, s)
+		got = append(got, a.string(a.add(s)))
+	}
+	big := strings.Repeat("x", strArenaMax+1)
+	want = append(want, big, "")
+	got = append(got, a.string(a.add(big)), a.string(a.add("")))
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("string %d = %q; want %q", i, got[i], want[i])
+		}
+	}
+	if stringData(got[1]) != stringData(got[0])+uintptr(len(got[0])) {
+		t.Error("consecutive strings aren't packed")
+	}
+}
+
+func TestStringArenaAllocs(t *testing.T) {
+	var a stringArena
+	a.add("warm")
+	n := testing.AllocsPerRun(1000, func() { a.add("hello") })
+	if n > 0.01 {
+		t.Errorf("add allocated %v objects per string", n)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// String returns the canonical copy of s in the default Interner.
+// See Interner.String.
+func String(s string) string {
+	return std.String(s)
+}
+
+// Bytes returns the canonical string with the contents of b in the
+// default Interner. See Interner.Bytes.
+func Bytes(b []byte) string {
+	return std.Bytes(b)
+}
+
+// CanonicalString is String. See Interner.CanonicalString.
+func CanonicalString(s string) string {
+	return std.String(s)
+}
+
+// String returns the canonical copy of s: the string held by the Value
+// that GetByString(s) returns. It's for the common case of using
+// interning only to deduplicate strings, without needing Values, and
+// is safe to use in place of holding the Value, as with
+// GetByString(s).Get().(string):
+//
+//   - The result is an ordinary Go string, kept alive by the garbage
+//     collector for as long as it is reachable, whatever becomes of
+//     its Value. It never points into memory that is reused or
+//     unmapped, and never aliases memory the caller can change, other
+//     than that of s itself.
+//   - Until its Value is collected, every call with an equal string
+//     returns the same copy, sharing its memory. The Value is kept
+//     only weakly, so holding the result doesn't keep it interned;
+//     afterwards, a different (equal) copy is returned.
+//   - It takes no reference: in ManualCollect mode, there is nothing
+//     to Release, and Collect may remove the Value at any time. With
+//     Refcounted, the Value stays interned until a Release of a
+//     reference taken otherwise drops its count to zero.
+//
+// Canonical strings share memory, but compare no faster than other
+// strings.
+func (in *Interner) String(s string) string {
+	return in.getByString(s, getCtx{noRef: true}).cmpVal.(string)
+}
+
+// CanonicalString is String, for callers that want the name to say
+// what they rely on.
+func (in *Interner) CanonicalString(s string) string {
+	return in.String(s)
+}
+
+// Bytes is like String for the contents of b. It only allocates if
+// the string isn't already interned.
+func (in *Interner) Bytes(b []byte) string {
+	if in.canonicalize != nil || in.transform != nil || in.foldASCII || in.shadow != nil || in.isDisabled() {
+		return in.String(string(b))
+	}
+	k := key{s: bytesToString(b), isString: true}
+	kh := in.hashString(k.s)
+	in.mu.Lock()
+	v := in.lookupLocked(k, kh, getCtx{noRef: true})
+	in.mu.Unlock()
+	if v == nil {
+		v = in.get(key{s: string(b), isString: true}, getCtx{noRef: true})
+	}
+	return v.cmpVal.(string)
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func stringData(s string) uintptr {
+	return uintptr((*[2]uintptr)(unsafe.Pointer(&s))[0])
+}
+
+func TestString(t *testing.T) {
+	v := GetByString("hello")
+	a := String(string([]byte("hello")))
+	b := Bytes([]byte("hello"))
+	if a != "hello" || b != "hello" {
+		t.Fatalf("got %q, %q", a, b)
+	}
+	if stringData(a) != stringData(v.Get().(string)) || stringData(b) != stringData(a) {
+		t.Error("String and Bytes didn't return the canonical string")
+	}
+
+	buf := []byte("world")
+	w := Bytes(buf)
+	buf[0] = 'W'
+	if w != "world" {
+		t.Errorf("Bytes result aliases its argument: %q", w)
+	}
+	if got := New(CaseInsensitive()).Bytes([]byte("MiXeD")); got != "mixed" {
+		t.Errorf("CaseInsensitive Bytes = %q", got)
+	}
+	runtime.KeepAlive(v)
+}
+
+func TestBytesAllocs(t *testing.T) {
+	v := GetByString("allocs")
+	b := []byte("allocs")
+	if n := testing.AllocsPerRun(100, func() { Bytes(b) }); n != 0 {
+		t.Errorf("Bytes allocated %v objects on a hit; want 0", n)
+	}
+	runtime.KeepAlive(v)
+}
+
+func TestCanonicalString(t *testing.T) {
+	v := GetByString("canon")
+	s := CanonicalString(string([]byte("canon")))
+	if s != "canon" || stringData(s) != stringData(v.Get().(string)) {
+		t.Errorf("CanonicalString didn't return the canonical string")
+	}
+	runtime.KeepAlive(v)
+
+	in := New(ManualCollect())
+	s = in.CanonicalString(string([]byte("manual")))
+	if n := in.Collect(); n != 1 {
+		t.Errorf("Collect removed %d Values; want 1, as CanonicalString takes no reference", n)
+	}
+	runtime.GC()
+	if s != "manual" {
+		t.Errorf("string changed after its Value was collected: %q", s)
+	}
+	if got := in.CanonicalString("manual"); got != s {
+		t.Errorf("CanonicalString after Collect = %q", got)
+	}
+	in = New(Refcounted())
+	s = in.CanonicalString(string([]byte("counted")))
+	if got := in.CanonicalString("counted"); stringData(got) != stringData(s) {
+		t.Error("with Refcounted, CanonicalString removed its Value")
+	}
+	v = in.GetByString("counted")
+	if v.Get().(string) != s {
+		t.Error("with Refcounted, Get after CanonicalString returned a new Value")
+	}
+	v.Release()
+	if in.Stats().Entries != 0 {
+		t.Error("with Refcounted, Release of the only reference didn't remove the Value")
+	}
+}
+
+func TestStringTakesNoReference(t *testing.T) {
+	in := New(ManualCollect())
+	in.String("a")
+	in.String("a")
+	in.Bytes([]byte("b"))
+	in.Bytes([]byte("b"))
+	if n := in.Collect(); n != 2 {
+		t.Errorf("Collect removed %d Values; want 2, as String and Bytes take no reference", n)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"math"
+	"sync"
+)
+
+// A Symbol is a dense, per-process integer ID for an interned value.
+//
+// Symbols are assigned sequentially starting at 1, so they can be used
+// directly as slice indexes. The zero Symbol is never assigned and can
+// be used to mean "no symbol".
+//
+// Unlike *Value, a value that has been assigned a Symbol is never
+// garbage collected: the symbol table holds it for the lifetime of
+// the process, so that ValueOf can always resolve it.
+//
+// Symbols are assigned to underlying values, not Values, so a value
+// keeps its Symbol even if its Value stops being canonical, as after
+// Forget, or isn't, as when interning is disabled. ValueOf returns the
+// Value that was first assigned the Symbol.
+type Symbol uint32
+
+var (
+	// symMu guards symVals and symByVal.
+	symMu    sync.RWMutex
+	symVals  = []*Value{nil}    // indexed by Symbol; index 0 unused
+	symByVal = map[key]Symbol{} // by underlying value
+)
+
+// SymbolOf returns the Symbol for the comparable value cmpVal,
+// assigning the next unused Symbol if cmpVal doesn't have one yet.
+//
+// SymbolOf(v) == SymbolOf(v2) if and only if v == v2.
+// It panics if the symbol space is exhausted.
+func SymbolOf(cmpVal interface{}) Symbol {
+	return symbolOf(Get(cmpVal))
+}
+
+// SymbolOfString is identical to SymbolOf, except that it is
+// specialized for strings, like GetByString.
+func SymbolOfString(s string) Symbol {
+	return symbolOf(GetByString(s))
+}
+
+// Symbol returns the Symbol for v, assigning one if needed.
+// See SymbolOf. A nil v has the Symbol of Get(nil).
+func (v *Value) Symbol() Symbol {
+	return symbolOf(v.orNil())
+}
+
+func symbolOf(v *Value) Symbol {
+	k := keyFor(v.cmpVal)
+	symMu.RLock()
+	sym, ok := symByVal[k]
+	symMu.RUnlock()
+	if ok {
+		return sym
+	}
+
+	symMu.Lock()
+	defer symMu.Unlock()
+	if sym, ok := symByVal[k]; ok {
+		return sym
+	}
+	if uint64(len(symVals)) > math.MaxUint32 {
+		panic("intern: Symbol space exhausted")
+	}
+	sym = Symbol(len(symVals))
+	symVals = append(symVals, v)
+	symByVal[k] = sym
+	return sym
+}
+
+// ValueOf returns the *Value that was first assigned sym,
+// or nil if sym has not been assigned.
+func ValueOf(sym Symbol) *Value {
+	symMu.RLock()
+	defer symMu.RUnlock()
+	if sym == 0 || int(sym) >= len(symVals) {
+		return nil
+	}
+	return symVals[sym]
+}
+
+// NumSymbols returns the number of Symbols assigned so far.
+// The assigned Symbols are 1 through NumSymbols, inclusive.
+func NumSymbols() int {
+	symMu.RLock()
+	defer symMu.RUnlock()
+	return len(symVals) - 1
+}
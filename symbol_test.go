@@ -0,0 +1,61 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "testing"
+
+func TestSymbol(t *testing.T) {
+	foo := SymbolOf("sym-foo")
+	bar := SymbolOfString("sym-bar")
+	if foo == 0 || bar == 0 {
+		t.Fatalf("zero Symbol assigned: foo=%d, bar=%d", foo, bar)
+	}
+	if foo == bar {
+		t.Fatalf("foo and bar share Symbol %d", foo)
+	}
+	if bar != foo+1 {
+		t.Errorf("Symbols not dense: foo=%d, bar=%d", foo, bar)
+	}
+	if got := SymbolOfString("sym-foo"); got != foo {
+		t.Errorf("SymbolOfString(sym-foo) = %d; want %d", got, foo)
+	}
+	if got := Get("sym-bar").Symbol(); got != bar {
+		t.Errorf("Value.Symbol = %d; want %d", got, bar)
+	}
+	if got := ValueOf(foo).Get(); got != "sym-foo" {
+		t.Errorf("ValueOf(foo).Get() = %v; want sym-foo", got)
+	}
+	if ValueOf(foo) != Get("sym-foo") {
+		t.Error("ValueOf(foo) is not the interned Value")
+	}
+	if v := ValueOf(0); v != nil {
+		t.Errorf("ValueOf(0) = %v; want nil", v)
+	}
+	if v := ValueOf(Symbol(NumSymbols() + 1)); v != nil {
+		t.Errorf("ValueOf(unassigned) = %v; want nil", v)
+	}
+}
+
+func TestSymbolUncanonical(t *testing.T) {
+	in := New()
+	v := in.GetByString("sym-forgotten")
+	sym := v.Symbol()
+	in.Forget("sym-forgotten")
+	n := NumSymbols()
+	if got := in.GetByString("sym-forgotten").Symbol(); got != sym {
+		t.Errorf("Symbol after Forget = %d; want %d", got, sym)
+	}
+
+	in.SetDisabled(true)
+	first := in.GetByString("sym-disabled").Symbol()
+	for i := 0; i < 10; i++ {
+		if got := in.GetByString("sym-disabled").Symbol(); got != first {
+			t.Fatalf("Symbol of uninterned Value = %d; want %d", got, first)
+		}
+	}
+	if got := NumSymbols(); got != n+1 {
+		t.Errorf("NumSymbols grew by %d; want 1", got-n)
+	}
+}
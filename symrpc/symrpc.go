@@ -0,0 +1,92 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package symrpc serves an intern.RemoteAllocator over a network
+// connection, such as a Unix socket, using net/rpc.
+//
+// A coordinator process calls Serve, and each worker Dials it and
+// wraps the Client with intern.NewRemoteSymbols.
+package symrpc // import "go4.org/intern/symrpc"
+
+import (
+	"net"
+	"net/rpc"
+
+	"go4.org/intern"
+)
+
+// serviceName is the net/rpc service name.
+const serviceName = "Intern"
+
+// service adapts an intern.RemoteAllocator to net/rpc's method conventions.
+type service struct {
+	ra intern.RemoteAllocator
+}
+
+func (s *service) Allocate(str string, sym *intern.Symbol) (err error) {
+	*sym, err = s.ra.Allocate(str)
+	return err
+}
+
+func (s *service) Resolve(sym intern.Symbol, str *string) (err error) {
+	*str, err = s.ra.Resolve(sym)
+	return err
+}
+
+// Serve accepts connections on l and serves ra on each,
+// until accepting fails.
+//
+// If ra is nil, intern.LocalAllocator() is used, making the serving
+// process's symbol table the shared one.
+func Serve(l net.Listener, ra intern.RemoteAllocator) error {
+	if ra == nil {
+		ra = intern.LocalAllocator()
+	}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(serviceName, &service{ra}); err != nil {
+		return err
+	}
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go srv.ServeConn(c)
+	}
+}
+
+// A Client is an intern.RemoteAllocator served by a remote Serve.
+type Client struct {
+	c *rpc.Client
+}
+
+var _ intern.RemoteAllocator = (*Client)(nil)
+
+// Dial connects to a symrpc server, as with net.Dial.
+func Dial(network, address string) (*Client, error) {
+	c, err := rpc.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{c}, nil
+}
+
+// Allocate implements intern.RemoteAllocator.
+func (c *Client) Allocate(s string) (intern.Symbol, error) {
+	var sym intern.Symbol
+	err := c.c.Call(serviceName+".Allocate", s, &sym)
+	return sym, err
+}
+
+// Resolve implements intern.RemoteAllocator.
+func (c *Client) Resolve(sym intern.Symbol) (string, error) {
+	var s string
+	err := c.c.Call(serviceName+".Resolve", sym, &s)
+	return s, err
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	return c.c.Close()
+}
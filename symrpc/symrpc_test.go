@@ -0,0 +1,64 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symrpc
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go4.org/intern"
+)
+
+func TestUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "symrpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, "sock")
+
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("unix sockets unsupported: %v", err)
+	}
+	defer ln.Close()
+	go Serve(ln, nil)
+
+	c1, err := Dial("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c1.Close()
+	c2, err := Dial("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.Close()
+
+	w1, w2 := intern.NewRemoteSymbols(c1), intern.NewRemoteSymbols(c2)
+	sym, err := w1.SymbolOf("shared-word")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := intern.SymbolOfString("shared-word"); sym != want {
+		t.Errorf("remote Symbol = %d; coordinator has %d", sym, want)
+	}
+	v, err := w2.ValueOf(sym)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Get() != "shared-word" {
+		t.Errorf("ValueOf(%d) = %v; want shared-word", sym, v.Get())
+	}
+	if sym2, err := w2.SymbolOf("shared-word"); err != nil || sym2 != sym {
+		t.Errorf("second worker SymbolOf = %d, %v; want %d, nil", sym2, err, sym)
+	}
+	if _, err := w1.ValueOf(intern.Symbol(1 << 30)); err == nil {
+		t.Error("ValueOf(unassigned) succeeded")
+	}
+}
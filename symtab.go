@@ -0,0 +1,143 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// symtabMagic begins every symbol table written by WriteTable.
+// The final byte is the format version.
+const symtabMagic = "go4isym\x01"
+
+// WriteTable writes the symbol table (every assigned Symbol and its
+// value) to w, in a form that ReadTable can load.
+//
+// Only string values can currently be written. WriteTable returns an
+// error without writing anything if any Symbol has a non-string value.
+func WriteTable(w io.Writer) error {
+	symMu.RLock()
+	vals := symVals[1:len(symVals):len(symVals)]
+	symMu.RUnlock()
+
+	for i, v := range vals {
+		if _, ok := v.cmpVal.(string); !ok {
+			return fmt.Errorf("intern: can't write Symbol %d with non-string value of type %T", i+1, v.cmpVal)
+		}
+	}
+
+	strs := make([]string, len(vals))
+	for i, v := range vals {
+		strs[i] = v.cmpVal.(string)
+	}
+	return writeTable(w, strs)
+}
+
+// writeTable writes strs to w in the format of WriteTable.
+func writeTable(w io.Writer, strs []string) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(symtabMagic)
+	var buf [binary.MaxVarintLen64]byte
+	bw.Write(buf[:binary.PutUvarint(buf[:], uint64(len(strs)))])
+	for _, s := range strs {
+		bw.Write(buf[:binary.PutUvarint(buf[:], uint64(len(s)))])
+		bw.WriteString(s)
+	}
+	return bw.Flush()
+}
+
+// ReadTable loads a symbol table written by WriteTable, assigning each
+// value the same Symbol it had when written.
+//
+// ReadTable is meant to be called at startup, before Symbols are
+// assigned by other means. It returns an error if a Symbol in the
+// table is already assigned to a different value, or if a value in
+// the table already has a different Symbol. Entries that already match
+// are left alone, so loading the same table twice is harmless.
+func ReadTable(r io.Reader) error {
+	return readTable(r, func(i uint64, s string) error {
+		return assignSymbol(Symbol(i), GetByString(s))
+	})
+}
+
+// readTable reads a table in the format of WriteTable from r, calling
+// f with each string and its 1-based index, in order.
+func readTable(r io.Reader, f func(i uint64, s string) error) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(symtabMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return fmt.Errorf("intern: reading symbol table header: %w", noEOF(err))
+	}
+	if string(magic) != symtabMagic {
+		return errors.New("intern: not a symbol table, or unsupported version")
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return fmt.Errorf("intern: reading symbol table length: %w", noEOF(err))
+	}
+	if n > math.MaxUint32-1 {
+		return fmt.Errorf("intern: symbol table has too many entries (%d)", n)
+	}
+	for i := uint64(1); i <= n; i++ {
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return fmt.Errorf("intern: reading entry %d: %w", i, noEOF(err))
+		}
+		var sb []byte
+		// Don't trust size for the allocation; a corrupt
+		// table shouldn't make us allocate gigabytes up front.
+		if size > 1<<20 {
+			sb, err = ioutil.ReadAll(io.LimitReader(br, int64(size)))
+			if err == nil && uint64(len(sb)) != size {
+				err = io.ErrUnexpectedEOF
+			}
+		} else {
+			sb = make([]byte, size)
+			_, err = io.ReadFull(br, sb)
+		}
+		if err != nil {
+			return fmt.Errorf("intern: reading entry %d: %w", i, noEOF(err))
+		}
+		if err := f(i, string(sb)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignSymbol makes sym the Symbol for v. Because tables are loaded
+// in order, sym is either already assigned or the next free Symbol.
+func assignSymbol(sym Symbol, v *Value) error {
+	symMu.Lock()
+	defer symMu.Unlock()
+	k := keyFor(v.cmpVal)
+	if old, ok := symByVal[k]; ok {
+		if old != sym {
+			return fmt.Errorf("intern: loading Symbol %d for %q: value already has Symbol %d", sym, v.cmpVal, old)
+		}
+		return nil
+	}
+	if int(sym) < len(symVals) {
+		return fmt.Errorf("intern: loading Symbol %d for %q: Symbol already assigned to %v", sym, v.cmpVal, symVals[sym].cmpVal)
+	}
+	symVals = append(symVals, v)
+	symByVal[k] = sym
+	return nil
+}
+
+// noEOF converts io.EOF to io.ErrUnexpectedEOF, as any EOF while
+// reading a table means it was truncated.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// swapSymbols replaces the symbol table with an empty one
+// and returns a func that restores the original.
+func swapSymbols() (restore func()) {
+	symMu.Lock()
+	defer symMu.Unlock()
+	oldVals, oldByVal := symVals, symByVal
+	symVals, symByVal = []*Value{nil}, map[key]Symbol{}
+	return func() {
+		symMu.Lock()
+		defer symMu.Unlock()
+		symVals, symByVal = oldVals, oldByVal
+	}
+}
+
+func TestTableRoundTrip(t *testing.T) {
+	defer swapSymbols()()
+
+	words := []string{"alpha", "", "beta", strings.Repeat("x", 300)}
+	for _, w := range words {
+		SymbolOfString(w)
+	}
+	var buf bytes.Buffer
+	if err := WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	table := buf.Bytes()
+
+	// Loading the table into the process that wrote it is a no-op.
+	if err := ReadTable(bytes.NewReader(table)); err != nil {
+		t.Fatalf("reloading own table: %v", err)
+	}
+
+	swapSymbols()
+	if err := ReadTable(bytes.NewReader(table)); err != nil {
+		t.Fatal(err)
+	}
+	if got := NumSymbols(); got != len(words) {
+		t.Errorf("NumSymbols = %d; want %d", got, len(words))
+	}
+	for i, w := range words {
+		if got := SymbolOfString(w); got != Symbol(i+1) {
+			t.Errorf("SymbolOfString(%q) = %d; want %d", w, got, i+1)
+		}
+	}
+}
+
+func TestReadTableConflict(t *testing.T) {
+	defer swapSymbols()()
+
+	SymbolOfString("one")
+	var buf bytes.Buffer
+	if err := WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	swapSymbols()
+	SymbolOfString("two")
+	if err := ReadTable(&buf); err == nil {
+		t.Error("ReadTable succeeded despite conflicting Symbol 1")
+	}
+}
+
+func TestReadTableTruncated(t *testing.T) {
+	defer swapSymbols()()
+
+	SymbolOfString("truncated")
+	var buf bytes.Buffer
+	if err := WriteTable(&buf); err != nil {
+		t.Fatal(err)
+	}
+	table := buf.Bytes()
+
+	swapSymbols()
+	for n := 0; n < len(table); n++ {
+		err := ReadTable(bytes.NewReader(table[:n]))
+		if n < len(symtabMagic) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("ReadTable(table[:%d]) = %v; want ErrUnexpectedEOF", n, err)
+		}
+		if err == nil {
+			t.Errorf("ReadTable(table[:%d]) succeeded", n)
+		}
+	}
+}
+
+func TestWriteTableNonString(t *testing.T) {
+	defer swapSymbols()()
+
+	SymbolOf(42)
+	if err := WriteTable(ioutil.Discard); err == nil {
+		t.Error("WriteTable succeeded with non-string Symbol")
+	}
+}
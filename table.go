@@ -0,0 +1,238 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// table is an open-addressing hash table of weak references to
+// Values, used instead of a map[key]uintptr.
+//
+// Each slot holds only the Value's hash and address, not its key,
+// so the table contains no pointers for the garbage collector to
+// scan and uses a fraction of the memory of a map, whose entries
+// include a full key with its string and interface headers. Keys are
+// compared by reading the underlying value from the Value itself.
+//
+// A table is guarded by its Interner's mu. If shared is set, as by
+// the Seqlock option, it can also be read without the lock by
+// findShared, and its writes are made visible to such readers.
+type table struct {
+	slots []slot // len is zero or a power of two
+	count int    // slots holding a Value
+	tombs int    // slots holding tombstone
+	floor int    // Values to keep room for when shrinking; see WithCapacity
+
+	shared bool
+	seq    uint32         // if shared, odd while being written; accessed atomically
+	pub    unsafe.Pointer // if shared, a *[]slot of slots; accessed atomically
+}
+
+type slot struct {
+	hash uint64
+	addr uintptr // uintptr(*Value), or empty or tombstone
+}
+
+const (
+	empty     = 0
+	tombstone = 1 // never a valid *Value address
+)
+
+const (
+	// minTableSize is the smallest non-zero number of slots.
+	minTableSize = 8
+
+	// shrinkRatio is the ratio of slots to Values below which
+	// a table is shrunk.
+	shrinkRatio = 8
+)
+
+// valueAt returns the *Value at addr, an address from the table.
+// See the comment below Get for why this is okay.
+//
+//go:nocheckptr
+func valueAt(addr uintptr) *Value {
+	return (*Value)(unsafe.Pointer(addr))
+}
+
+// find returns the address of the Value for k, whose hash is h,
+// or zero if there isn't one.
+func (t *table) find(k key, h uint64) uintptr {
+	if len(t.slots) == 0 {
+		return 0
+	}
+	mask := uint64(len(t.slots) - 1)
+	for i := h & mask; ; i = (i + 1) & mask {
+		s := &t.slots[i]
+		switch {
+		case s.addr == empty:
+			return 0
+		case s.addr != tombstone && s.hash == h && keyFor(valueAt(s.addr).cmpVal) == k:
+			return s.addr
+		}
+	}
+}
+
+// findFunc is like find, but compares keys by calling eq with
+// each Value whose hash is h.
+func (t *table) findFunc(h uint64, eq func(*Value) bool) uintptr {
+	if len(t.slots) == 0 {
+		return 0
+	}
+	mask := uint64(len(t.slots) - 1)
+	for i := h & mask; ; i = (i + 1) & mask {
+		s := &t.slots[i]
+		switch {
+		case s.addr == empty:
+			return 0
+		case s.addr != tombstone && s.hash == h && eq(valueAt(s.addr)):
+			return s.addr
+		}
+	}
+}
+
+// insert adds the Value at addr, whose key has hash h.
+// Its key must not already be present.
+func (t *table) insert(h uint64, addr uintptr) {
+	t.beginWrite()
+	defer t.endWrite()
+	if (t.count+t.tombs+1)*4 > len(t.slots)*3 {
+		t.rehash(t.count + 1)
+	}
+	mask := uint64(len(t.slots) - 1)
+	for i := h & mask; ; i = (i + 1) & mask {
+		s := &t.slots[i]
+		if s.addr == empty || s.addr == tombstone {
+			if s.addr == tombstone {
+				t.tombs--
+			}
+			if t.shared {
+				// Store addr last, so readers that see it
+				// see the hash.
+				atomic.StoreUint64(&s.hash, h)
+				atomic.StoreUintptr(&s.addr, addr)
+			} else {
+				*s = slot{hash: h, addr: addr}
+			}
+			t.count++
+			return
+		}
+	}
+}
+
+// remove removes the Value at addr, whose key has hash h.
+func (t *table) remove(h uint64, addr uintptr) {
+	if len(t.slots) == 0 {
+		return
+	}
+	t.beginWrite()
+	defer t.endWrite()
+	mask := uint64(len(t.slots) - 1)
+	for i := h & mask; ; i = (i + 1) & mask {
+		s := &t.slots[i]
+		switch s.addr {
+		case empty:
+			return
+		case addr:
+			if t.shared {
+				atomic.StoreUintptr(&s.addr, tombstone)
+			} else {
+				s.addr = tombstone
+			}
+			t.count--
+			t.tombs++
+			if t.count*shrinkRatio < len(t.slots) && len(t.slots) > tableSize(t.floor) {
+				// Mostly empty, as after a burst of values
+				// is collected. Shrink so the memory can be
+				// returned to the OS.
+				t.rehash(t.count)
+			}
+			return
+		}
+	}
+}
+
+// resize rehashes the table to have room for n Values, or floor if
+// that's more, discarding tombstones.
+func (t *table) resize(n int) {
+	t.beginWrite()
+	defer t.endWrite()
+	t.rehash(n)
+}
+
+// rehash is resize, within a write.
+func (t *table) rehash(n int) {
+	if n < t.floor {
+		n = t.floor
+	}
+	size := tableSize(n)
+	old := t.slots
+	t.slots = make([]slot, size)
+	t.count, t.tombs = 0, 0
+	mask := uint64(size - 1)
+	for _, s := range old {
+		if s.addr == empty || s.addr == tombstone {
+			continue
+		}
+		i := s.hash & mask
+		for t.slots[i].addr != empty {
+			i = (i + 1) & mask
+		}
+		t.slots[i] = s
+		t.count++
+	}
+	t.publish()
+}
+
+// tableSize returns the number of slots for n Values.
+func tableSize(n int) int {
+	size := minTableSize
+	for size*3 < n*4*2 { // target half the maximum load
+		size *= 2
+	}
+	return size
+}
+
+// compact rehashes the table to its ideal size for its current
+// Values, if that is smaller than its current size or would discard
+// tombstones.
+func (t *table) compact() {
+	if t.tombs > 0 {
+		t.resize(t.count)
+	}
+}
+
+// reset removes all Values from the table.
+func (t *table) reset() {
+	t.beginWrite()
+	defer t.endWrite()
+	t.slots, t.count, t.tombs = nil, 0, 0
+	t.publish()
+}
+
+// beginWrite and endWrite bracket changes to a shared table,
+// making seq odd for their duration.
+func (t *table) beginWrite() {
+	if t.shared {
+		atomic.AddUint32(&t.seq, 1)
+	}
+}
+
+func (t *table) endWrite() {
+	if t.shared {
+		atomic.AddUint32(&t.seq, 1)
+	}
+}
+
+// publish makes a shared table's slots visible to readers.
+func (t *table) publish() {
+	if t.shared {
+		p := new([]slot)
+		*p = t.slots
+		atomic.StorePointer(&t.pub, unsafe.Pointer(p))
+	}
+}
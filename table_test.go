@@ -0,0 +1,62 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"strconv"
+	"testing"
+	"unsafe"
+)
+
+func TestTable(t *testing.T) {
+	var tab table
+	const n = 1000
+	vals := make([]*Value, n)
+	hashes := make([]uint64, n)
+	for i := range vals {
+		vals[i] = &Value{cmpVal: strconv.Itoa(i)}
+		// Force plenty of collisions.
+		hashes[i] = uint64(i % 37)
+		tab.insert(hashes[i], uintptr(unsafe.Pointer(vals[i])))
+	}
+	if tab.count != n {
+		t.Fatalf("count = %d; want %d", tab.count, n)
+	}
+	for i, v := range vals {
+		if got := tab.find(keyFor(v.cmpVal), hashes[i]); got != uintptr(unsafe.Pointer(v)) {
+			t.Fatalf("find(%d) = %#x; want %p", i, got, v)
+		}
+	}
+	if got := tab.find(keyFor("missing"), 3); got != 0 {
+		t.Errorf("find(missing) = %#x; want 0", got)
+	}
+
+	for i := 0; i < n; i += 2 {
+		tab.remove(hashes[i], uintptr(unsafe.Pointer(vals[i])))
+	}
+	if tab.count != n/2 {
+		t.Fatalf("count after removal = %d; want %d", tab.count, n/2)
+	}
+	for i, v := range vals {
+		got := tab.find(keyFor(v.cmpVal), hashes[i])
+		if want := uintptr(unsafe.Pointer(v)); i%2 == 0 && got != 0 || i%2 == 1 && got != want {
+			t.Fatalf("find(%d) after removal = %#x", i, got)
+		}
+	}
+
+	// Reinserting reuses tombstones rather than growing forever.
+	size := len(tab.slots)
+	for round := 0; round < 10; round++ {
+		for i := 0; i < n; i += 2 {
+			tab.insert(hashes[i], uintptr(unsafe.Pointer(vals[i])))
+		}
+		for i := 0; i < n; i += 2 {
+			tab.remove(hashes[i], uintptr(unsafe.Pointer(vals[i])))
+		}
+	}
+	if len(tab.slots) > size {
+		t.Errorf("table grew from %d to %d slots under churn", size, len(tab.slots))
+	}
+}
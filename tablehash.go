@@ -0,0 +1,39 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.24
+// +build go1.24
+
+package intern
+
+import "hash/maphash"
+
+// A hashSeed seeds the table hash.
+type hashSeed = maphash.Seed
+
+// tableSeed is the process's table hash seed.
+var tableSeed = newHashSeed()
+
+// newHashSeed returns a random hashSeed.
+func newHashSeed() hashSeed { return maphash.MakeSeed() }
+
+// tableHashString returns the table hash of the string key s.
+func tableHashString(s string) uint64 {
+	return seededHashString(&tableSeed, s)
+}
+
+// tableHashValue returns the table hash of the non-string key cmpVal.
+func tableHashValue(cmpVal interface{}) uint64 {
+	return seededHashValue(&tableSeed, cmpVal)
+}
+
+// seededHashString is tableHashString with the given seed.
+func seededHashString(seed *hashSeed, s string) uint64 {
+	return maphash.String(*seed, s)
+}
+
+// seededHashValue is tableHashValue with the given seed.
+func seededHashValue(seed *hashSeed, cmpVal interface{}) uint64 {
+	return maphash.Comparable(*seed, cmpVal)
+}
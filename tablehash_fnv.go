@@ -0,0 +1,81 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.24
+// +build !go1.24
+
+package intern
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"reflect"
+	"time"
+)
+
+// Before Go 1.24, there's no maphash.Comparable, so the table is
+// hashed with FNV, as for HashKeys, but starting from a random state,
+// so that which values collide can't be known in advance.
+
+// A hashSeed seeds the table hash, by perturbing FNV's initial state.
+type hashSeed struct {
+	hi, lo uint64
+}
+
+// tableSeed is the process's table hash seed.
+var tableSeed = newHashSeed()
+
+// newHashSeed returns a random hashSeed.
+func newHashSeed() hashSeed {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		binary.BigEndian.PutUint64(b[:], uint64(time.Now().UnixNano()))
+	}
+	return hashSeed{binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])}
+}
+
+// fnv returns an FNV hash starting from seed's state.
+func (seed *hashSeed) fnv() fnv128a {
+	h := newFNV128a()
+	h.hi ^= seed.hi
+	h.lo ^= seed.lo
+	return h
+}
+
+// tableHashString returns the table hash of the string key s.
+func tableHashString(s string) uint64 {
+	return seededHashString(&tableSeed, s)
+}
+
+// tableHashValue returns the table hash of the non-string key cmpVal.
+func tableHashValue(cmpVal interface{}) uint64 {
+	return seededHashValue(&tableSeed, cmpVal)
+}
+
+// seededHashString is tableHashString with the given seed.
+func seededHashString(seed *hashSeed, s string) uint64 {
+	h := seed.fnv()
+	h.writeByte('s')
+	h.writeString(s)
+	return h.sum64()
+}
+
+// seededHashValue is tableHashValue with the given seed.
+func seededHashValue(seed *hashSeed, cmpVal interface{}) uint64 {
+	h := seed.fnv()
+	hashValue(&h, reflect.ValueOf(cmpVal))
+	return h.sum64()
+}
+
+// sum64 folds h into 64 bits with MurmurHash3's fmix64 finalizer.
+// FNV's low bits, which index the table, mix poorly on their own.
+func (h *fnv128a) sum64() uint64 {
+	x := h.hi ^ h.lo
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
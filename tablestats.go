@@ -0,0 +1,85 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// longProbe is the probe distance above which a Value is counted in
+// TableStats.LongProbes.
+const longProbe = 8
+
+// TableStats describe the occupancy of an Interner's index, to detect
+// pathological hashing, such as many values whose hashes collide.
+//
+// The index is a single open-addressing hash table, not sharded. A
+// Value's probe distance is how many slots past the one its hash
+// selects it's stored in, and so how many other slots a Get of it
+// examines first. With a good hash, almost all distances are short.
+type TableStats struct {
+	// Slots is the number of slots in the table.
+	Slots int
+	// Entries is the number of slots holding a Value.
+	Entries int
+	// Tombstones is the number of slots marking removed Values,
+	// which are reused by inserts and discarded when the table
+	// is resized.
+	Tombstones int
+	// LoadFactor is the fraction of slots in use, holding a Value or
+	// a tombstone. The table grows at three quarters.
+	LoadFactor float64
+
+	// MeanProbe and MaxProbe are the mean and largest probe
+	// distances of the Values.
+	MeanProbe float64
+	MaxProbe  int
+	// LongProbes is the number of Values with a probe distance
+	// greater than 8.
+	LongProbes int
+
+	// Flooded is the number of Values kept outside the table
+	// because their hashes collided with too many others.
+	Flooded int
+}
+
+// TableStats returns statistics about the occupancy of in's index.
+// It returns zero TableStats if in doesn't use the default index, as
+// with HashKeys or WeakPointers, or in safe-but-leaky mode.
+//
+// TableStats takes time proportional to the size of the index.
+func (in *Interner) TableStats() TableStats {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.valSafe != nil || in.hashMap != nil || in.wk != nil {
+		return TableStats{}
+	}
+	st := in.tab.stats()
+	st.Flooded = len(in.flooded)
+	return st
+}
+
+func (t *table) stats() TableStats {
+	st := TableStats{Slots: len(t.slots), Entries: t.count, Tombstones: t.tombs}
+	if st.Slots == 0 {
+		return st
+	}
+	st.LoadFactor = float64(t.count+t.tombs) / float64(st.Slots)
+	mask := uint64(len(t.slots) - 1)
+	total := 0
+	for i, s := range t.slots {
+		if s.addr == empty || s.addr == tombstone {
+			continue
+		}
+		d := int((uint64(i) - s.hash) & mask)
+		total += d
+		if d > st.MaxProbe {
+			st.MaxProbe = d
+		}
+		if d > longProbe {
+			st.LongProbes++
+		}
+	}
+	if t.count > 0 {
+		st.MeanProbe = float64(total) / float64(t.count)
+	}
+	return st
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestTableStats(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	in := New()
+	if st := in.TableStats(); st != (TableStats{}) {
+		t.Errorf("empty TableStats = %+v", st)
+	}
+	var vs []*Value
+	for i := 0; i < 100; i++ {
+		vs = append(vs, in.Get(i))
+	}
+	in.Forget(0)
+	st := in.TableStats()
+	if st.Entries != 99 || st.Tombstones != 1 || st.Slots != len(in.tab.slots) {
+		t.Errorf("TableStats = %+v", st)
+	}
+	if want := 100 / float64(st.Slots); st.LoadFactor != want {
+		t.Errorf("LoadFactor = %v; want %v", st.LoadFactor, want)
+	}
+	if st.MaxProbe > 20 || st.MeanProbe > 2 {
+		t.Errorf("probes unexpectedly long for a good hash: %+v", st)
+	}
+	runtime.KeepAlive(vs)
+
+	if st := New(HashKeys()).TableStats(); st != (TableStats{}) {
+		t.Errorf("HashKeys TableStats = %+v; want zero", st)
+	}
+}
+
+func TestTableStatsCollisions(t *testing.T) {
+	// Values whose hashes all collide probe ever further.
+	var tab table
+	const n = 20
+	for i := 0; i < n; i++ {
+		tab.insert(42, uintptr(16*(i+1)))
+	}
+	st := tab.stats()
+	if st.MaxProbe != n-1 || st.LongProbes != n-1-longProbe {
+		t.Errorf("colliding TableStats = %+v; want MaxProbe %d, LongProbes %d", st, n-1, n-1-longProbe)
+	}
+	if want := float64(n-1) / 2; st.MeanProbe != want {
+		t.Errorf("MeanProbe = %v; want %v", st.MeanProbe, want)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// A Tenant is a user of a shared Interner, such as one customer of a
+// multi-tenant service, whose use of the Interner is limited by a
+// quota.
+//
+// Values interned through a Tenant's Get methods that weren't already
+// interned are attributed to the Tenant until they're removed from
+// the Interner. Once the Tenant has as many Values as its quota, its
+// Gets of values that aren't already interned return new Values
+// without interning them, as if the Interner were disabled, so that
+// one Tenant with too many distinct values can't grow the Interner
+// without bound. Values that are already interned are returned as
+// usual, whoever interned them.
+//
+// A Tenant may be used by multiple goroutines at once.
+type Tenant struct {
+	in *Interner
+	st *tenantState
+}
+
+// tenantState is the state of a Tenant, guarded by its Interner's mu.
+type tenantState struct {
+	quota    int
+	count    int    // Values attributed to the tenant
+	rejected uint64 // Gets not interned because of the quota
+}
+
+// Tenant returns the Tenant of in identified by key, creating it if
+// needed, and sets its quota of Values.
+func (in *Interner) Tenant(key string, quota int) *Tenant {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	st := in.tenants[key]
+	if st == nil {
+		if in.tenants == nil {
+			in.tenants = map[string]*tenantState{}
+		}
+		st = new(tenantState)
+		in.tenants[key] = st
+	}
+	st.quota = quota
+	return &Tenant{in: in, st: st}
+}
+
+// Get is like Interner.Get, but a Value it inserts is attributed to t.
+func (t *Tenant) Get(cmpVal interface{}) *Value {
+	return t.in.getValue(cmpVal, getCtx{tenant: t.st})
+}
+
+// GetByString is like Interner.GetByString, but a Value it inserts is
+// attributed to t.
+func (t *Tenant) GetByString(s string) *Value {
+	if t.in.canonicalize != nil {
+		return t.in.getValue(s, getCtx{tenant: t.st})
+	}
+	return t.in.getString(s, getCtx{tenant: t.st})
+}
+
+// Len returns the number of Values attributed to t.
+func (t *Tenant) Len() int {
+	t.in.mu.Lock()
+	defer t.in.mu.Unlock()
+	return t.st.count
+}
+
+// Rejected returns the number of t's Gets that weren't interned
+// because t had reached its quota.
+func (t *Tenant) Rejected() uint64 {
+	t.in.mu.Lock()
+	defer t.in.mu.Unlock()
+	return t.st.rejected
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestTenant(t *testing.T) {
+	in := New()
+	a := in.Tenant("a", 2)
+	b := in.Tenant("b", 10)
+	shared := b.GetByString("shared")
+
+	a1, a2 := a.Get(1), a.Get(2)
+	a3 := a.Get(3)
+	if a.Len() != 2 || a.Rejected() != 1 {
+		t.Errorf("a has %d Values, %d rejected; want 2, 1", a.Len(), a.Rejected())
+	}
+	if a.Get(3) == a3 {
+		t.Error("Get over quota interned its value")
+	}
+	if in.Get(1) != a1 || a.Get(2) != a2 {
+		t.Error("Values within quota not interned")
+	}
+	if a.GetByString("shared") != shared {
+		t.Error("Get over quota didn't return an already interned Value")
+	}
+
+	// Freeing up quota lets a intern again.
+	in.Forget(1)
+	if a.Len() != 1 {
+		t.Errorf("a has %d Values after Forget; want 1", a.Len())
+	}
+	if v := a.Get(4); a.Get(4) != v {
+		t.Error("Get after Forget not interned")
+	}
+	if in.Tenant("a", 5).Len() != 2 {
+		t.Error("Tenant with the same key didn't share state")
+	}
+	runtime.KeepAlive(a1)
+	runtime.KeepAlive(a2)
+	runtime.KeepAlive(shared)
+}
+
+func TestTenantCollected(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("Values aren't collected in safe-but-leaky mode")
+	}
+	in := New()
+	ten := in.Tenant("t", 100)
+	func() {
+		for i := 0; i < 50; i++ {
+			ten.Get(i)
+		}
+	}()
+	for i := 0; i < 100 && ten.Len() > 0; i++ {
+		runtime.GC()
+	}
+	if n := ten.Len(); n != 0 {
+		t.Errorf("tenant has %d Values after collection; want 0", n)
+	}
+}
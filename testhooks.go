@@ -0,0 +1,22 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "go4.org/intern/internal/testhooks"
+
+func init() {
+	testhooks.ResetDefault = std.reset
+	testhooks.SafeMode = func() bool { return std.valSafe != nil }
+	testhooks.SafeBackend = Option(safeBackend)
+}
+
+// safeBackend is an Option that puts in in safe-but-leaky mode, as
+// GO4_INTERN_SAFE_BUT_LEAKY would.
+func safeBackend(in *Interner) {
+	if in.valSafe == nil {
+		in.valSafe = map[key]*Value{}
+		in.leaks = newLeaks()
+	}
+}
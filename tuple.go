@@ -0,0 +1,107 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// A Pair is the underlying value of a Value returned by GetPair.
+type Pair struct {
+	A, B interface{}
+}
+
+// A Triple is the underlying value of a Value returned by Get3.
+type Triple struct {
+	A, B, C interface{}
+}
+
+// A StringPair is the underlying value of a Value returned by
+// GetStringPair, such as a label's name and value.
+type StringPair struct {
+	A, B string
+}
+
+// GetPair returns the Value for the composite of the comparable
+// values a and b. It's equivalent to Get(Pair{a, b}).
+//
+// Interning a composite this way, rather than joining its parts into
+// one string with a separator, avoids building the joined string and
+// can't confuse parts that contain the separator.
+func GetPair(a, b interface{}) *Value {
+	return std.GetPair(a, b)
+}
+
+// Get3 returns the Value for the composite of the comparable values
+// a, b, and c. It's equivalent to Get(Triple{a, b, c}).
+func Get3(a, b, c interface{}) *Value {
+	return std.Get3(a, b, c)
+}
+
+// GetStringPair returns the Value for the pair of strings a and b.
+// It's equivalent to Get(StringPair{a, b}), and is cheaper to hash
+// and compare than GetPair of two strings.
+func GetStringPair(a, b string) *Value {
+	return std.GetStringPair(a, b)
+}
+
+// GetPair is like the package-level GetPair, but uses in's table.
+//
+// Options that canonicalize values are applied to the Pair, not to
+// a and b: with CaseInsensitive, for example, pairs of strings
+// differing in case get different Values.
+func (in *Interner) GetPair(a, b interface{}) *Value {
+	return in.Get(Pair{a, b})
+}
+
+// Get3 is like the package-level Get3, but uses in's table.
+// See Interner.GetPair.
+func (in *Interner) Get3(a, b, c interface{}) *Value {
+	return in.Get(Triple{a, b, c})
+}
+
+// GetStringPair is like the package-level GetStringPair, but uses
+// in's table. See Interner.GetPair.
+func (in *Interner) GetStringPair(a, b string) *Value {
+	return in.Get(StringPair{a, b})
+}
+
+// InternComponents returns an Option that makes the Interner also
+// intern the string parts of the composites it interns: Pairs,
+// Triples, and StringPairs, including those from GetPair and the
+// like. A composite's Value holds its parts' Values, and its strings
+// are theirs, so composites that share a string, such as many label
+// pairs with the same name, share one copy of it.
+//
+// Parts are interned as they are, without any of in's canonicalization
+// of strings, such as CaseInsensitive, and aren't counted in Stats. In
+// ManualCollect mode, a composite holds a reference to each of its
+// parts until it is removed.
+func InternComponents() Option {
+	return func(in *Interner) { in.components = true }
+}
+
+// internComponents returns cmpVal with its string parts replaced by
+// those of their Values, and those Values, if cmpVal is a composite.
+// Otherwise it returns cmpVal and nil.
+func (in *Interner) internComponents(cmpVal interface{}, ctx getCtx) (interface{}, []*Value) {
+	var parts []*Value
+	partString := func(s string) string {
+		v := in.get(key{s: s, isString: true}, getCtx{gen: ctx.gen, noRef: true, part: true})
+		parts = append(parts, v)
+		return v.cmpVal.(string)
+	}
+	part := func(x interface{}) interface{} {
+		if s, ok := x.(string); ok {
+			return partString(s)
+		}
+		return x
+	}
+	switch c := cmpVal.(type) {
+	case Pair:
+		cmpVal = Pair{part(c.A), part(c.B)}
+	case Triple:
+		cmpVal = Triple{part(c.A), part(c.B), part(c.C)}
+	case StringPair:
+		cmpVal = StringPair{partString(c.A), partString(c.B)}
+	}
+	return cmpVal, parts
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestTuples(t *testing.T) {
+	in := New()
+	p := in.GetPair("a", 1)
+	if p != in.GetPair("a", 1) || p != in.Get(Pair{"a", 1}) {
+		t.Error("equal Pairs got different Values")
+	}
+	if p == in.GetPair(1, "a") || p == in.GetPair("a", "1") {
+		t.Error("different Pairs got the same Value")
+	}
+	if got, want := p.Get(), (Pair{"a", 1}); got != want {
+		t.Errorf("Get = %v; want %v", got, want)
+	}
+
+	// Parts containing a separator aren't confused, as they are
+	// when joined.
+	if in.GetStringPair("a,b", "c") == in.GetStringPair("a", "b,c") {
+		t.Error("StringPairs confused")
+	}
+	if in.GetStringPair("a", "b") == in.GetPair("a", "b") {
+		t.Error("StringPair and Pair of the same strings got the same Value")
+	}
+
+	tr := in.Get3("a", "b", 3)
+	if tr != in.Get3("a", "b", 3) || tr == in.Get3("a", "b", 4) {
+		t.Error("Triple Values not canonical")
+	}
+	if got, want := tr.Get(), (Triple{"a", "b", 3}); got != want {
+		t.Errorf("Get = %v; want %v", got, want)
+	}
+
+	if _, err := in.TryGet(Pair{[]byte("x"), 1}); err == nil {
+		t.Error("TryGet of uncomparable Pair succeeded")
+	}
+}
+
+func TestInternComponents(t *testing.T) {
+	in := New(InternComponents())
+	name := string([]byte("job"))
+	p := in.GetStringPair(name, "api")
+	q := in.GetPair(string([]byte("job")), 1)
+	r := in.Get3(1, string([]byte("job")), "api")
+	job := in.GetByString("job").Get().(string)
+	for _, s := range []string{
+		p.Get().(StringPair).A,
+		q.Get().(Pair).A.(string),
+		r.Get().(Triple).B.(string),
+	} {
+		if stringData(s) != stringData(job) {
+			t.Error("composite doesn't share its component's string")
+		}
+	}
+	if p != in.GetStringPair("job", "api") {
+		t.Error("composite not canonical")
+	}
+
+	// The composite keeps its components interned.
+	job = ""
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+	}
+	if got := in.GetByString("job").Get().(string); stringData(got) != stringData(p.Get().(StringPair).A) {
+		t.Error("component collected while its composite is reachable")
+	}
+
+	if in := New(); stringData(in.GetStringPair(name, "x").Get().(StringPair).A) != stringData(name) {
+		t.Error("components interned without InternComponents")
+	}
+}
+
+func TestInternComponentsManualCollect(t *testing.T) {
+	skipIfRefcounted(t)
+	in := New(InternComponents(), ManualCollect())
+	for i := 0; i < 3; i++ {
+		in.GetStringPair("job", "api")
+	}
+	if st := in.Stats(); st.Hits != 2 || st.Misses != 1 {
+		t.Errorf("Stats counted parts: hits=%d misses=%d; want 2, 1", st.Hits, st.Misses)
+	}
+	p, _ := in.Lookup(StringPair{"job", "api"})
+	for i := 0; i < 3; i++ {
+		p.Release()
+	}
+	// Removing the composite releases its parts, which the same
+	// Collect may or may not reach.
+	n := in.Collect()
+	n += in.Collect()
+	if n != 3 {
+		t.Errorf("Collect removed %d Values; want 3", n)
+	}
+	if st := in.Stats(); st.Entries != 0 {
+		t.Errorf("Entries = %d after Collect; want 0", st.Entries)
+	}
+
+	in = New(InternComponents(), Refcounted())
+	in.GetStringPair("job", "api").Release()
+	if st := in.Stats(); st.Entries != 0 {
+		t.Errorf("Refcounted: Entries = %d after Release; want 0", st.Entries)
+	}
+}
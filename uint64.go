@@ -0,0 +1,187 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// A Uint64Interner interns uint64 values, such as numeric IDs, more
+// cheaply than an Interner.
+//
+// It indexes Values by a radix tree on the bits of the value, so it
+// needn't hash values, and Get doesn't box its argument in an
+// interface unless it creates a new Value. The Get method of the
+// Values it returns returns a uint64. Signed IDs can be converted to
+// uint64.
+//
+// The radix tree's memory is proportional to the number of distinct
+// ranges of 256 values that are interned, so it suits values that are
+// clustered, as IDs assigned sequentially are.
+type Uint64Interner struct {
+	in *Interner // owns the Values; in.ints is the index
+}
+
+// NewUint64Interner returns a new, empty Uint64Interner.
+func NewUint64Interner() *Uint64Interner {
+	in := New()
+	if in.valSafe == nil {
+		in.ints = new(radix)
+	}
+	return &Uint64Interner{in: in}
+}
+
+// Get returns the Value for x. Get(x) == Get(y) if and only if x == y.
+//
+// Values from a Uint64Interner are distinct from those of any
+// Interner.
+//
+//go:nocheckptr
+func (u *Uint64Interner) Get(x uint64) *Value {
+	in := u.in
+	if in.ints == nil {
+		// Safe-but-leaky mode.
+		return in.Get(x)
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if addr := in.ints.find(x); addr != 0 {
+		v := valueAt(addr)
+		if v.resurrect() {
+			in.hits++
+			return v
+		}
+		// Its finalizer has claimed it. Replace it.
+		in.removeLocked(v)
+	}
+	in.misses++
+	v := &Value{cmpVal: x, in: in}
+	// SetFinalizer before uintptr conversion, as in insertLocked.
+	runtime.SetFinalizer(v, finalize)
+	in.ints.insert(x, uintptr(unsafe.Pointer(v)))
+	return v
+}
+
+// Len returns the number of Values in u.
+func (u *Uint64Interner) Len() int {
+	in := u.in
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.ints == nil {
+		return len(in.valSafe)
+	}
+	return in.ints.count
+}
+
+// radixBits is the number of bits of a value indexed by each level of
+// a radix tree.
+const radixBits = 8
+
+// A radix is a radix tree of weak references to Values, indexed by
+// their uint64 value, most significant bits first. Its height grows
+// to cover the largest value inserted, so small values take few
+// levels. Like table, it's guarded by its Interner's mu.
+type radix struct {
+	root   *radixNode // or nil if empty
+	height int        // levels; the tree holds values < 1<<(radixBits*height)
+	count  int        // values in the tree
+}
+
+// A radixNode is an interior node, with kids, or a leaf, with vals.
+type radixNode struct {
+	n    int // non-nil kids or non-zero vals
+	kids []*radixNode
+	vals []uintptr
+}
+
+func newRadixNode(leaf bool) *radixNode {
+	if leaf {
+		return &radixNode{vals: make([]uintptr, 1<<radixBits)}
+	}
+	return &radixNode{kids: make([]*radixNode, 1<<radixBits)}
+}
+
+// digit returns the index of x in a node at level lvl, where the
+// leaves are at level 0.
+func digit(x uint64, lvl int) int {
+	return int(x>>(radixBits*uint(lvl))) & (1<<radixBits - 1)
+}
+
+// covers reports whether a tree of height h can hold x.
+func covers(h int, x uint64) bool {
+	return h*radixBits >= 64 || x>>(radixBits*uint(h)) == 0
+}
+
+// find returns the address of the Value for x, or zero.
+func (r *radix) find(x uint64) uintptr {
+	if r.root == nil || !covers(r.height, x) {
+		return 0
+	}
+	nd := r.root
+	for lvl := r.height - 1; lvl > 0; lvl-- {
+		if nd = nd.kids[digit(x, lvl)]; nd == nil {
+			return 0
+		}
+	}
+	return nd.vals[digit(x, 0)]
+}
+
+// insert adds the Value at addr for x, which must not be present.
+func (r *radix) insert(x uint64, addr uintptr) {
+	if r.root == nil {
+		r.root, r.height = newRadixNode(true), 1
+	}
+	for !covers(r.height, x) {
+		root := newRadixNode(false)
+		root.kids[0], root.n = r.root, 1
+		r.root = root
+		r.height++
+	}
+	nd := r.root
+	for lvl := r.height - 1; lvl > 0; lvl-- {
+		i := digit(x, lvl)
+		if nd.kids[i] == nil {
+			nd.kids[i] = newRadixNode(lvl == 1)
+			nd.n++
+		}
+		nd = nd.kids[i]
+	}
+	nd.vals[digit(x, 0)] = addr
+	nd.n++
+	r.count++
+}
+
+// remove removes the Value at addr for x, if present, freeing any
+// nodes left empty.
+func (r *radix) remove(x uint64, addr uintptr) {
+	if r.root == nil || !covers(r.height, x) {
+		return
+	}
+	var path [64 / radixBits]*radixNode
+	nd := r.root
+	for lvl := r.height - 1; lvl > 0; lvl-- {
+		path[lvl] = nd
+		if nd = nd.kids[digit(x, lvl)]; nd == nil {
+			return
+		}
+	}
+	i := digit(x, 0)
+	if nd.vals[i] != addr {
+		return
+	}
+	nd.vals[i] = 0
+	nd.n--
+	r.count--
+	for lvl := 1; lvl < r.height && nd.n == 0; lvl++ {
+		parent := path[lvl]
+		parent.kids[digit(x, lvl)] = nil
+		parent.n--
+		nd = parent
+	}
+	if r.root.n == 0 {
+		r.root, r.height = nil, 0
+	}
+}
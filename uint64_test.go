@@ -0,0 +1,124 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"math"
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestUint64Interner(t *testing.T) {
+	u := NewUint64Interner()
+	xs := []uint64{0, 1, 255, 256, 65535, 1 << 40, math.MaxUint64}
+	var vals []*Value
+	for _, x := range xs {
+		v := u.Get(x)
+		if v.Get() != x {
+			t.Errorf("Get(%d).Get() = %v", x, v.Get())
+		}
+		vals = append(vals, v)
+	}
+	for i, x := range xs {
+		if u.Get(x) != vals[i] {
+			t.Errorf("Get(%d) not canonical", x)
+		}
+		for j := range vals {
+			if i != j && vals[i] == vals[j] {
+				t.Errorf("Get(%d) == Get(%d)", x, xs[j])
+			}
+		}
+	}
+	if n := u.Len(); n != len(xs) {
+		t.Errorf("Len = %d; want %d", n, len(xs))
+	}
+	runtime.KeepAlive(vals)
+}
+
+func TestUint64InternerCollect(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("Values aren't collected in safe-but-leaky mode")
+	}
+	u := NewUint64Interner()
+	keep := u.Get(7)
+	func() {
+		for x := uint64(0); x < 10000; x++ {
+			u.Get(x << 20)
+		}
+	}()
+	for i := 0; i < 100 && u.Len() > 1; i++ {
+		runtime.GC()
+	}
+	if n := u.Len(); n != 1 {
+		t.Fatalf("Len after GC = %d; want 1", n)
+	}
+	if u.Get(7) != keep {
+		t.Error("kept Value was collected")
+	}
+	if r := u.in.ints; r.root.n != 1 || r.root.kids[0] == nil {
+		t.Error("empty radix nodes weren't freed")
+	}
+	runtime.KeepAlive(keep)
+}
+
+func TestUint64InternerClaimed(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no finalizers in safe-but-leaky mode")
+	}
+	u := NewUint64Interner()
+	v := u.Get(7)
+	// As if v's finalizer had decided to remove it.
+	atomic.StoreUint32(&v.state, stateDead)
+	w := u.Get(7)
+	if w == v {
+		t.Fatal("Get returned a Value its finalizer had claimed")
+	}
+	if u.Get(7) != w || u.Len() != 1 {
+		t.Errorf("replacement isn't canonical; Len = %d", u.Len())
+	}
+	runtime.KeepAlive(v)
+}
+
+func TestUint64InternerAllocs(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("no table in safe mode")
+	}
+	u := NewUint64Interner()
+	v := u.Get(1 << 33)
+	if n := testing.AllocsPerRun(100, func() { u.Get(1 << 33) }); n != 0 {
+		t.Errorf("Get allocated %v objects on a hit; want 0", n)
+	}
+	runtime.KeepAlive(v)
+}
+
+func TestRadix(t *testing.T) {
+	var r radix
+	xs := []uint64{5, 300, 70000, 1 << 50, math.MaxUint64}
+	for i, x := range xs {
+		r.insert(x, uintptr(i+2))
+	}
+	for i, x := range xs {
+		if got := r.find(x); got != uintptr(i+2) {
+			t.Errorf("find(%d) = %d; want %d", x, got, i+2)
+		}
+	}
+	if r.find(6) != 0 || r.find(1<<49) != 0 {
+		t.Error("found absent value")
+	}
+	r.remove(5, 99) // wrong address
+	if r.find(5) == 0 {
+		t.Error("remove with the wrong address removed the value")
+	}
+	for i, x := range xs {
+		r.remove(x, uintptr(i+2))
+	}
+	if r.root != nil || r.count != 0 {
+		t.Errorf("tree not empty after removing everything: %+v", r)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "unsafe"
+
+// Hash returns a hash of v's underlying value, for use as the hash of
+// v in hash tables and consistent-hashing rings of Values.
+//
+// Values with equal underlying values, even from different Interners,
+// have equal hashes. Hashes are seeded randomly when the process
+// starts, so they differ between processes and shouldn't be stored or
+// sent elsewhere.
+//
+// Hash computes the hash on each call, in time proportional to the
+// size of the underlying value.
+//
+// A nil v hashes like Get(nil).
+func (v *Value) Hash() uint64 {
+	if s, ok := v.Get().(string); ok {
+		return tableHashString(s)
+	}
+	return tableHashValue(v.Get())
+}
+
+// Pointer returns v's address, for use as an integer key identifying v.
+//
+// Because Values are canonical, Pointer is distinct for Values with
+// distinct underlying values, as long as both are reachable. It's
+// stable for v's lifetime, since the garbage collector doesn't move
+// heap objects, but after v has been collected a new Value may have
+// the same address.
+//
+// The result doesn't keep v reachable, and mustn't be converted back
+// into a pointer.
+func (v *Value) Pointer() uintptr {
+	return uintptr(unsafe.Pointer(v))
+}
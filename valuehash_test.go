@@ -0,0 +1,48 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestValueHash(t *testing.T) {
+	type pair struct{ a, b int }
+	in := New()
+	vals := []interface{}{"foo", "bar", "", 1, 2, pair{1, 2}}
+	seen := map[uint64]interface{}{}
+	for _, x := range vals {
+		v1, v2 := Get(x), in.Get(x)
+		if v1.Hash() != v2.Hash() {
+			t.Errorf("Hash of %v differs between Interners", x)
+		}
+		if h := v1.Hash(); seen[h] != nil {
+			t.Errorf("Hash collision between %v and %v", x, seen[h])
+		} else {
+			seen[h] = x
+		}
+		runtime.KeepAlive(v1)
+	}
+	if GetByString("foo").Hash() != Get("foo").Hash() {
+		t.Error("GetByString and Get hashes differ")
+	}
+}
+
+func TestValuePointer(t *testing.T) {
+	a, b := Get("a"), Get("b")
+	if a.Pointer() != uintptr(unsafe.Pointer(a)) {
+		t.Error("Pointer isn't a's address")
+	}
+	if a.Pointer() == b.Pointer() {
+		t.Error("distinct Values have equal Pointers")
+	}
+	if Get("a").Pointer() != a.Pointer() {
+		t.Error("Pointer of equal Values differs")
+	}
+	runtime.KeepAlive(a)
+	runtime.KeepAlive(b)
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "unsafe"
+
+// A Weak is a weak reference to a Value: it doesn't keep the Value
+// from being garbage collected, but can produce the Value again for
+// as long as it hasn't been.
+//
+// Weak references let callers build caches of Values that don't
+// prevent their collection, without reimplementing this package's
+// unsafe tricks.
+//
+// A Weak does keep v's underlying value, such as a string's bytes,
+// reachable; only the Value itself is weakly held.
+// The zero Weak refers to nothing.
+type Weak struct {
+	in   *Interner
+	k    key
+	addr uintptr
+}
+
+// Weak returns a weak reference to v.
+// A weak reference to nil or Get(nil) always refers to Get(nil).
+func (v *Value) Weak() Weak {
+	v = v.orNil()
+	return Weak{in: v.in, k: keyFor(v.cmpVal), addr: uintptr(unsafe.Pointer(v))}
+}
+
+// Strong returns the Value w refers to, and true, if it is still
+// interned. Otherwise it returns nil, false.
+//
+// Strong returns false once the Value has been collected, or is no
+// longer canonical, as after its Generation ends. If an equal value
+// has since been interned with a new Value, Strong may return either
+// false or the new Value, which is canonical for the same underlying
+// value.
+func (w Weak) Strong() (*Value, bool) {
+	in := w.in
+	if in == nil {
+		// Only nilValue and the tiny table's Values, which are
+		// never collected, have no Interner.
+		if w.addr != 0 && w.addr == uintptr(unsafe.Pointer(nilValue)) {
+			return nilValue, true
+		}
+		if w.k.isString && len(w.k.s) <= maxTiny {
+			if v := tinyValue(w.k.s); w.addr == uintptr(unsafe.Pointer(v)) {
+				return v, true
+			}
+		}
+		return nil, false
+	}
+	kh := in.hashKey(w.k)
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.valSafe != nil {
+		v := in.valSafe[w.k]
+		if v == nil || uintptr(unsafe.Pointer(v)) != w.addr {
+			return nil, false
+		}
+		return v, true
+	}
+	if in.wk != nil {
+		v := in.wk.find(w.k)
+		if v == nil || uintptr(unsafe.Pointer(v)) != w.addr {
+			return nil, false
+		}
+		return v, true
+	}
+	// Only convert w.addr back into a pointer if the table still
+	// holds it: while it does, its memory hasn't been freed or reused.
+	if in.findLocked(w.k, kh) != w.addr {
+		return nil, false
+	}
+	v := valueAt(w.addr)
+	if !v.resurrect() {
+		return nil, false
+	}
+	return v, true
+}
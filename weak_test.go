@@ -0,0 +1,55 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestWeak(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("Values aren't collected in safe-but-leaky mode")
+	}
+	in := New()
+	v := in.GetByString("weak")
+	w := v.Weak()
+	if got, ok := w.Strong(); !ok || got != v {
+		t.Fatalf("Strong = %v, %v; want %v, true", got, ok, v)
+	}
+	runtime.KeepAlive(v)
+	v = nil
+
+	for try := 0; try < 1000; try++ {
+		runtime.GC()
+		if in.Stats().Entries == 0 {
+			break
+		}
+	}
+	if got, ok := w.Strong(); ok {
+		t.Errorf("Strong after collection = %v, true; want nil, false", got)
+	}
+	v = in.GetByString("weak")
+	if got, ok := w.Strong(); ok && got != v {
+		t.Errorf("Strong after reinterning = %p; want nil or %p", got, v)
+	}
+
+	if got, ok := (Weak{}).Strong(); ok || got != nil {
+		t.Errorf("zero Weak Strong = %v, %v; want nil, false", got, ok)
+	}
+}
+
+func TestWeakAfterGeneration(t *testing.T) {
+	in := New()
+	g := in.BeginGeneration()
+	v := g.Get(1)
+	w := v.Weak()
+	g.End()
+	if _, ok := w.Strong(); ok {
+		t.Error("Strong succeeded after Generation ended")
+	}
+	runtime.KeepAlive(v)
+}
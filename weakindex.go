@@ -0,0 +1,26 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+// WeakPointers returns an Option that indexes Values by weak pointers
+// (package weak) rather than by uintptrs with finalizers.
+//
+// The default scheme must keep a Value that was returned by a Get
+// since its finalizer was set for an extra GC cycle after it becomes
+// unreachable, as the comment below Get explains, so values that are
+// used repeatedly and then dropped take at least two cycles to be
+// reclaimed. Weak pointers are supported by the garbage collector, so
+// a Value is reclaimed in the first cycle in which it's unreachable,
+// lowering the memory high-water mark of tables with churning keys.
+//
+// WeakPointers requires Go 1.24. With earlier versions, and in
+// safe-but-leaky mode, it has no effect.
+func WeakPointers() Option {
+	return func(in *Interner) {
+		if in.valSafe == nil {
+			in.wk = newWeakIndex(in)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.24
+// +build go1.24
+
+package intern
+
+import (
+	"runtime"
+	"weak"
+)
+
+// A weakIndex is an index of Values by weak pointers.
+// It is guarded by its Interner's mu.
+type weakIndex struct {
+	in *Interner
+	m  map[key]weak.Pointer[Value]
+}
+
+func newWeakIndex(in *Interner) *weakIndex {
+	return &weakIndex{in: in, m: map[key]weak.Pointer[Value]{}}
+}
+
+// find returns the Value for k, or nil.
+func (w *weakIndex) find(k key) *Value {
+	return w.m[k].Value()
+}
+
+// insert adds v, whose key is k.
+func (w *weakIndex) insert(k key, v *Value) {
+	wp := weak.Make(v)
+	w.m[k] = wp
+	var t *tenantState
+	if e := v.ext(); e != nil {
+		t = e.tenant
+	}
+	runtime.AddCleanup(v, w.collected, weakEntry{k, wp, t})
+}
+
+// A weakEntry identifies an entry of a weakIndex, for its cleanup.
+type weakEntry struct {
+	k      key
+	wp     weak.Pointer[Value]
+	tenant *tenantState // v's tenant, or nil
+}
+
+// collected removes e's entry after its Value is reclaimed, unless
+// the entry has since been replaced or removed.
+func (w *weakIndex) collected(e weakEntry) {
+	in := w.in
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if w.m[e.k] == e.wp {
+		delete(w.m, e.k)
+		in.finalized++
+		if e.tenant != nil {
+			e.tenant.count--
+		}
+	}
+}
+
+// remove removes v, whose key is k, if present.
+func (w *weakIndex) remove(k key, v *Value) {
+	if w.m[k].Value() == v {
+		delete(w.m, k)
+	}
+}
+
+// forEach calls f with each Value.
+func (w *weakIndex) forEach(f func(*Value)) {
+	for _, wp := range w.m {
+		if v := wp.Value(); v != nil {
+			f(v)
+		}
+	}
+}
+
+// len returns the number of entries.
+func (w *weakIndex) len() int { return len(w.m) }
@@ -0,0 +1,20 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !go1.24
+// +build !go1.24
+
+package intern
+
+// A weakIndex is unavailable before Go 1.24, which added package weak.
+// Interner.wk is always nil, so its methods are never called.
+type weakIndex struct{}
+
+func newWeakIndex(in *Interner) *weakIndex { return nil }
+
+func (*weakIndex) find(k key) *Value      { panic("unreachable") }
+func (*weakIndex) insert(k key, v *Value) { panic("unreachable") }
+func (*weakIndex) remove(k key, v *Value) { panic("unreachable") }
+func (*weakIndex) forEach(f func(*Value)) { panic("unreachable") }
+func (*weakIndex) len() int               { panic("unreachable") }
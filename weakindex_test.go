@@ -0,0 +1,78 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.24
+// +build go1.24
+
+package intern
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestWeakPointers(t *testing.T) {
+	if safeMap() != nil {
+		t.Skip("WeakPointers has no effect in safe-but-leaky mode")
+	}
+	in := New(WeakPointers())
+	foo := in.GetByString("foo")
+	one := in.Get(1)
+	if in.GetByString("foo") != foo || in.Get(1) != one {
+		t.Fatal("Get not canonical")
+	}
+	if v, ok := in.Lookup("foo"); !ok || v != foo {
+		t.Error("Lookup failed")
+	}
+	if w, ok := foo.Weak().Strong(); !ok || w != foo {
+		t.Error("Weak.Strong failed")
+	}
+	if !in.Forget(1) || in.Get(1) == one {
+		t.Error("Forget failed")
+	}
+	runtime.KeepAlive(foo)
+	runtime.KeepAlive(one)
+}
+
+// TestWeakPointersOneCycle checks that Values that were returned
+// repeatedly are reclaimed in a single GC cycle, unlike with
+// finalizers, which need at least two.
+func TestWeakPointersOneCycle(t *testing.T) {
+	skipIfRefcounted(t)
+	if safeMap() != nil {
+		t.Skip("WeakPointers has no effect in safe-but-leaky mode")
+	}
+	const n = 100
+	in := New(WeakPointers())
+	func() {
+		for i := 0; i < n; i++ {
+			in.GetByString(strconv.Itoa(i))
+			in.GetByString(strconv.Itoa(i))
+		}
+	}()
+	runtime.GC()
+	for i := 0; i < n; i++ {
+		if _, ok := in.Lookup(strconv.Itoa(i)); ok {
+			t.Fatalf("value %d still interned after one GC", i)
+		}
+	}
+	// Cleanups run asynchronously after the cycle.
+	for i := 0; i < 100 && in.Stats().Entries > 0; i++ {
+		runtime.Gosched()
+		runtime.GC()
+	}
+	if st := in.Stats(); st.Entries != 0 || st.Finalized != n {
+		t.Errorf("Stats = %+v; want 0 entries, %d finalized", st, n)
+	}
+}
+
+func TestWeakPointersAllocs(t *testing.T) {
+	in := New(WeakPointers())
+	v := in.GetByString(globalString)
+	if n := testing.AllocsPerRun(100, func() { in.GetByString(globalString) }); n != 0 {
+		t.Errorf("GetByString allocated %v objects on a hit; want 0", n)
+	}
+	runtime.KeepAlive(v)
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import "time"
+
+const (
+	// windowBucket is the time covered by each of a window's buckets,
+	// and so the resolution of windowed statistics.
+	windowBucket = 10 * time.Second
+
+	// windowBuckets is the number of buckets kept, enough for the
+	// longest window.
+	windowBuckets = int(time.Hour / windowBucket)
+)
+
+// HitRateWindows are the windows reported by HitRates.
+var HitRateWindows = []time.Duration{time.Minute, 5 * time.Minute, time.Hour}
+
+// WindowedStats returns an Option that counts hits and misses over
+// sliding windows of the last minute, five minutes, and hour, as
+// reported by HitRates, so that decisions such as autoscaling and
+// tuning can react to changes in workload that lifetime counters hide.
+//
+// Windowed counting reads the clock on every Get, so it costs more
+// than the lifetime counters of Stats. Gets answered from a snapshot,
+// as with the Snapshots option, aren't counted.
+func WindowedStats() Option {
+	return func(in *Interner) { in.window = &window{now: time.Now} }
+}
+
+// A HitRate is the hits and misses of an Interner over a recent window
+// of time, as reported by HitRates.
+type HitRate struct {
+	// Window is the length of the window: the counts cover the
+	// Gets of the last Window, to within ten seconds.
+	Window time.Duration
+	Hits   uint64
+	Misses uint64
+}
+
+// Rate returns the fraction of r's Gets that were hits,
+// or 0 if there were none.
+func (r HitRate) Rate() float64 {
+	if r.Hits+r.Misses == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Hits+r.Misses)
+}
+
+// HitRates returns in's hits and misses over each of HitRateWindows,
+// shortest first. It returns nil if in wasn't created with
+// WindowedStats.
+func (in *Interner) HitRates() []HitRate {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	w := in.window
+	if w == nil {
+		return nil
+	}
+	rates := make([]HitRate, len(HitRateWindows))
+	cur := w.epoch()
+	for i, d := range HitRateWindows {
+		r := HitRate{Window: d}
+		n := int64(d / windowBucket)
+		for e := cur - n + 1; e <= cur; e++ {
+			b := &w.buckets[bucketIndex(e)]
+			if b.epoch == e {
+				r.Hits += b.hits
+				r.Misses += b.misses
+			}
+		}
+		rates[i] = r
+	}
+	return rates
+}
+
+// A window counts hits and misses in buckets of windowBucket,
+// indexed by their epoch modulo windowBuckets. It is guarded by the
+// Interner's mu.
+type window struct {
+	now     func() time.Time
+	buckets [windowBuckets]windowCount
+}
+
+type windowCount struct {
+	epoch  int64 // the bucket's start time, in units of windowBucket
+	hits   uint64
+	misses uint64
+}
+
+// epoch returns the current bucket's epoch.
+func (w *window) epoch() int64 {
+	return w.now().UnixNano() / int64(windowBucket)
+}
+
+func bucketIndex(epoch int64) int {
+	i := int(epoch % int64(windowBuckets))
+	if i < 0 {
+		i += windowBuckets
+	}
+	return i
+}
+
+// record counts a hit or a miss in the current bucket.
+func (w *window) record(hit bool) {
+	e := w.epoch()
+	b := &w.buckets[bucketIndex(e)]
+	if b.epoch != e {
+		*b = windowCount{epoch: e}
+	}
+	if hit {
+		b.hits++
+	} else {
+		b.misses++
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 Brad Fitzpatrick. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package intern
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestHitRates(t *testing.T) {
+	in := New(WindowedStats())
+	now := time.Unix(1e9, 0)
+	in.window.now = func() time.Time { return now }
+
+	v := in.GetByString("a") // miss
+	for i := 0; i < 3; i++ {
+		in.GetByString("a")
+	}
+	now = now.Add(10 * time.Minute)
+	in.GetByString("a")
+	in.GetByString("b") // miss
+	now = now.Add(30 * time.Second)
+	in.GetByString("a")
+
+	want := []HitRate{
+		{time.Minute, 2, 1},
+		{5 * time.Minute, 2, 1},
+		{time.Hour, 5, 2},
+	}
+	got := in.HitRates()
+	if len(got) != len(want) {
+		t.Fatalf("got %d rates; want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("rate %d = %+v; want %+v", i, got[i], want[i])
+		}
+	}
+	if r := got[0].Rate(); r != 2.0/3 {
+		t.Errorf("Rate = %v; want 2/3", r)
+	}
+
+	// Buckets wrap around after an hour.
+	now = now.Add(time.Hour)
+	for _, r := range in.HitRates() {
+		if r.Hits != 0 || r.Misses != 0 {
+			t.Errorf("after an idle hour, %v window = %+v; want none", r.Window, r)
+		}
+	}
+	runtime.KeepAlive(v)
+
+	if New().HitRates() != nil {
+		t.Error("HitRates without WindowedStats isn't nil")
+	}
+	if r := (HitRate{}).Rate(); r != 0 {
+		t.Errorf("empty Rate = %v", r)
+	}
+}